@@ -0,0 +1,434 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"scraper/scraper"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Varsayılan sunucu, tarama ve zamanlayıcı ayarları
+const (
+	defaultPort          = "8080"
+	defaultDBPath        = "data/scraper.db"
+	defaultScanWorkers   = 3
+	defaultScanTimeout   = 15 * time.Second
+	defaultScanRetries   = 3
+	defaultRetryDelay    = 2 * time.Second
+	defaultMaxBackoff    = 30 * time.Second
+	defaultRetryJitter   = 0.2
+	defaultContentLimit  = 2000
+	defaultSchedulerTick = 1 * time.Minute
+	defaultTorHealth     = 30 * time.Second
+)
+
+// CONFIG_FILE tanımlı değilse çalışma dizininde sırayla aranan dosyalar
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// Config: Sunucunun tüm ayarları. Öncelik sırası: varsayılanlar < dosya < ortam değişkenleri.
+// Tarama ve zamanlayıcı ayarları çalışırken Ayarlar sayfasından da değiştirilebilir (bkz. Tunables).
+type Config struct {
+	Server    ServerConfig    `json:"server" yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `json:"database" yaml:"database" toml:"database"`
+	Auth      AuthConfig      `json:"auth" yaml:"auth" toml:"auth"`
+	Tor       TorConfig       `json:"tor" yaml:"tor" toml:"tor"`
+	Routing   RoutingConfig   `json:"routing" yaml:"routing" toml:"routing"`
+	Scan      ScanConfig      `json:"scan" yaml:"scan" toml:"scan"`
+	Scheduler SchedulerConfig `json:"scheduler" yaml:"scheduler" toml:"scheduler"`
+	Snapshots SnapshotConfig  `json:"snapshots" yaml:"snapshots" toml:"snapshots"`
+	Security  SecurityConfig  `json:"security" yaml:"security" toml:"security"`
+
+	File string `json:"file" yaml:"-" toml:"-"` // Yüklenen yapılandırma dosyası (yoksa boş)
+}
+
+type ServerConfig struct {
+	Port string `json:"port" yaml:"port" toml:"port"`
+}
+
+type DatabaseConfig struct {
+	Path string `json:"path" yaml:"path" toml:"path"`
+}
+
+type AuthConfig struct {
+	JWTSecret string `json:"jwt_secret" yaml:"jwt_secret" toml:"jwt_secret"`
+}
+
+// TorConfig: Tor proxy havuzu. Proxies ve Proxy boşsa yerel Tor (9050) ve Tor Browser (9150) portları denenir.
+type TorConfig struct {
+	Proxy           string   `json:"proxy" yaml:"proxy" toml:"proxy"`                                  // Tek proxy (Proxies boşsa kullanılır)
+	Proxies         []string `json:"proxies" yaml:"proxies" toml:"proxies"`                            // SOCKS5 uç noktaları
+	ControlAddrs    []string `json:"control_addrs" yaml:"control_addrs" toml:"control_addrs"`          // Tek adres tümü için, aksi halde proxy sırasıyla eşleşir
+	ControlPassword string   `json:"control_password" yaml:"control_password" toml:"control_password"` // HashedControlPassword karşılığı
+	HealthInterval  Duration `json:"health_interval" yaml:"health_interval" toml:"health_interval"`
+	Isolation       bool     `json:"isolation" yaml:"isolation" toml:"isolation"` // Her taramaya ayrı SOCKS kimliğiyle ayrı devre
+}
+
+type ScanConfig struct {
+	Workers       int      `json:"workers" yaml:"workers" toml:"workers"` // Değişikliği yeniden başlatma gerektirir
+	Timeout       Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+	MaxRetries    int      `json:"max_retries" yaml:"max_retries" toml:"max_retries"`          // İlk deneme dahil toplam deneme
+	RetryDelay    Duration `json:"retry_delay" yaml:"retry_delay" toml:"retry_delay"`          // İlk yeniden denemeden önceki bekleme, her denemede ikiye katlanır
+	MaxBackoff    Duration `json:"max_backoff" yaml:"max_backoff" toml:"max_backoff"`          // Beklemenin üst sınırı
+	RetryJitter   float64  `json:"retry_jitter" yaml:"retry_jitter" toml:"retry_jitter"`       // 0-1: beklemenin rastgele kısaltılabilecek oranı
+	RetryOn       []string `json:"retry_on" yaml:"retry_on" toml:"retry_on"`                   // Yeniden denenecek hata kodları
+	SwitchCircuit bool     `json:"switch_circuit" yaml:"switch_circuit" toml:"switch_circuit"` // Yeniden denemeden önce yeni Tor devresi
+	ContentLimit  int      `json:"content_limit" yaml:"content_limit" toml:"content_limit"`    // Ham içerik kırpma sınırı (karakter)
+}
+
+type SchedulerConfig struct {
+	Tick Duration `json:"tick" yaml:"tick" toml:"tick"` // Watchlist kontrol aralığı
+}
+
+// Duration: Dosyada ve JSON'da "15s", "1m" biçiminde yazılan süre
+type Duration time.Duration
+
+func (d Duration) Std() time.Duration { return time.Duration(d) }
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := parseSeconds(strings.TrimSpace(string(b)))
+	if err != nil {
+		return fmt.Errorf("geçersiz süre: %q (örn. 15s, 1m)", b)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+var (
+	mu      sync.RWMutex
+	current = Defaults()
+)
+
+// Defaults: Hiçbir ayar verilmediğinde kullanılan değerler
+func Defaults() Config {
+	return Config{
+		Server:   ServerConfig{Port: defaultPort},
+		Database: DatabaseConfig{Path: defaultDBPath},
+		Tor:      TorConfig{HealthInterval: Duration(defaultTorHealth), Isolation: true},
+		Routing:  defaultRouting(),
+		Scan: ScanConfig{
+			Workers:       defaultScanWorkers,
+			Timeout:       Duration(defaultScanTimeout),
+			MaxRetries:    defaultScanRetries,
+			RetryDelay:    Duration(defaultRetryDelay),
+			MaxBackoff:    Duration(defaultMaxBackoff),
+			RetryJitter:   defaultRetryJitter,
+			RetryOn:       append([]string(nil), scraper.DefaultRetryOn...),
+			SwitchCircuit: true,
+			ContentLimit:  defaultContentLimit,
+		},
+		Scheduler: SchedulerConfig{Tick: Duration(defaultSchedulerTick)},
+		Snapshots: defaultSnapshots(),
+		Security:  defaultSecurity(),
+	}
+}
+
+// Load: .env dosyasını, isteğe bağlı YAML/TOML dosyasını ve ortam değişkenlerini okuyup doğrular.
+// Başarılı olursa sonuç Get ile erişilen güncel ayar olur.
+func Load() (Config, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Uyarı: .env dosyası bulunamadı")
+	}
+
+	cfg := Defaults()
+	path, err := findConfigFile()
+	if err != nil {
+		return cfg, err
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return cfg, err
+		}
+		cfg.File = path
+	}
+	if err := loadEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	mu.Lock()
+	current, base = cfg, cfg
+	mu.Unlock()
+	return cfg, nil
+}
+
+// Get: Güncel ayarların kopyasını döndürür
+func Get() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// findConfigFile: CONFIG_FILE verilmişse o dosya bulunmalıdır; verilmemişse varsayılan adlar denenir
+func findConfigFile() (string, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("CONFIG_FILE okunamadı: %v", err)
+		}
+		return path, nil
+	}
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Yapılandırma dosyası okunamadı: %v", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, cfg, yaml.DisallowUnknownField())
+	case ".toml":
+		err = toml.NewDecoder(strings.NewReader(string(data))).DisallowUnknownFields().Decode(cfg)
+	default:
+		return fmt.Errorf("Desteklenmeyen yapılandırma dosyası: %s (.yaml, .yml veya .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("Yapılandırma dosyası (%s) çözümlenemedi: %v", path, err)
+	}
+	return nil
+}
+
+// loadEnv: Tanımlı ortam değişkenleri dosyadaki değerleri ezer
+func loadEnv(cfg *Config) error {
+	setString(&cfg.Server.Port, "PORT")
+	setString(&cfg.Database.Path, "DB_PATH")
+	setString(&cfg.Auth.JWTSecret, "JWT_SECRET")
+	setString(&cfg.Tor.Proxy, "TOR_PROXY")
+	setList(&cfg.Tor.Proxies, "TOR_PROXIES")
+	setList(&cfg.Tor.ControlAddrs, "TOR_CONTROL_ADDRS")
+	setString(&cfg.Tor.ControlPassword, "TOR_CONTROL_PASSWORD")
+	setList(&cfg.Scan.RetryOn, "SCAN_RETRY_ON")
+	setString(&cfg.Routing.Default, "ROUTE_DEFAULT")
+	setOptional(&cfg.Routing.I2PProxy, "I2P_PROXY")
+	setString(&cfg.Snapshots.Dir, "SNAPSHOT_DIR")
+
+	s := &cfg.Security
+	setList(&s.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+	setList(&s.AllowedMethods, "CORS_ALLOWED_METHODS")
+	setList(&s.AllowedHeaders, "CORS_ALLOWED_HEADERS")
+	setList(&s.ExposedHeaders, "CORS_EXPOSED_HEADERS")
+	setOptional(&s.ContentSecurityPolicy, "SECURITY_CSP")
+	setOptional(&s.FrameOptions, "SECURITY_FRAME_OPTIONS")
+	setOptional(&s.ReferrerPolicy, "SECURITY_REFERRER_POLICY")
+
+	for _, err := range []error{
+		setInt(&cfg.Scan.Workers, "SCAN_WORKERS"),
+		setDuration(&cfg.Scan.Timeout, "SCAN_TIMEOUT"),
+		setInt(&cfg.Scan.MaxRetries, "SCAN_MAX_RETRIES"),
+		setDuration(&cfg.Scan.RetryDelay, "SCAN_RETRY_DELAY"),
+		setDuration(&cfg.Scan.MaxBackoff, "SCAN_MAX_BACKOFF"),
+		setFloat(&cfg.Scan.RetryJitter, "SCAN_RETRY_JITTER"),
+		setBool(&cfg.Scan.SwitchCircuit, "SCAN_SWITCH_CIRCUIT"),
+		setInt(&cfg.Scan.ContentLimit, "SCAN_CONTENT_LIMIT"),
+		setDuration(&cfg.Scheduler.Tick, "SCHEDULER_TICK"),
+		setDuration(&cfg.Tor.HealthInterval, "TOR_HEALTH_INTERVAL"),
+		setBool(&cfg.Tor.Isolation, "TOR_ISOLATION"),
+		setRouteRules(&cfg.Routing.Rules, "ROUTE_RULES"),
+		setNamedProxies(&cfg.Routing.Proxies, "ROUTE_PROXIES"),
+		setBool(&cfg.Routing.CompleteOnion, "ROUTE_COMPLETE_ONION"),
+		setBool(&cfg.Snapshots.Enabled, "SNAPSHOT_ENABLED"),
+		setDuration(&cfg.Snapshots.MaxAge, "SNAPSHOT_MAX_AGE"),
+		setInt(&cfg.Snapshots.MaxSizeMB, "SNAPSHOT_MAX_SIZE_MB"),
+		setBool(&s.AllowCredentials, "CORS_ALLOW_CREDENTIALS"),
+		setDuration(&s.MaxAge, "CORS_MAX_AGE"),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate: Eksik veya sınır dışı değerleri sunucu açılmadan yakalar
+func (cfg *Config) Validate() error {
+	if strings.TrimSpace(cfg.Server.Port) == "" {
+		return fmt.Errorf("Sunucu portu boş olamaz (PORT)")
+	}
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("Geçersiz port: %q", cfg.Server.Port)
+	}
+	if strings.TrimSpace(cfg.Database.Path) == "" {
+		return fmt.Errorf("Veritabanı yolu boş olamaz (DB_PATH)")
+	}
+	if cfg.Auth.JWTSecret == "" {
+		return fmt.Errorf("JWT_SECRET tanımlı değil")
+	}
+	if err := cfg.Tor.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Routing.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Snapshots.Validate(); err != nil {
+		return err
+	}
+	if cfg.Scan.Workers < 1 || cfg.Scan.Workers > maxScanWorkers {
+		return fmt.Errorf("Tarama işçi sayısı 1-%d arasında olmalı (SCAN_WORKERS)", maxScanWorkers)
+	}
+	tunables := cfg.Tunables()
+	if err := tunables.Validate(); err != nil {
+		return err
+	}
+	return cfg.Security.Validate()
+}
+
+// Redacted: API'de gösterilmek üzere gizli değerleri maskelenmiş kopya
+func (cfg Config) Redacted() Config {
+	if cfg.Auth.JWTSecret != "" {
+		cfg.Auth.JWTSecret = redactedValue
+	}
+	cfg.Tor.Proxy = redactProxy(cfg.Tor.Proxy)
+	proxies := make([]string, len(cfg.Tor.Proxies))
+	for i, p := range cfg.Tor.Proxies {
+		proxies[i] = redactProxy(p)
+	}
+	cfg.Tor.Proxies = proxies
+	if cfg.Tor.ControlPassword != "" {
+		cfg.Tor.ControlPassword = redactedValue
+	}
+	cfg.Routing.I2PProxy = redactProxy(cfg.Routing.I2PProxy)
+	named := make(map[string]string, len(cfg.Routing.Proxies))
+	for name, p := range cfg.Routing.Proxies {
+		named[name] = redactProxy(p)
+	}
+	cfg.Routing.Proxies = named
+	return cfg
+}
+
+func setString(dst *string, key string) {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		*dst = v
+	}
+}
+
+// setOptional: Boş değer de geçerlidir (ilgili başlığı kapatır)
+func setOptional(dst *string, key string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = strings.TrimSpace(v)
+	}
+}
+
+// setList: Virgülle ayrılmış liste. Boş değer bilerek verilmiş sayılır (doğrulamada yakalanır).
+func setList(dst *[]string, key string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = splitList(v)
+	}
+}
+
+func setRouteRules(dst *[]RouteRule, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	rules, err := parseRouteRules(splitList(v))
+	if err != nil {
+		return err
+	}
+	*dst = rules
+	return nil
+}
+
+func setNamedProxies(dst *map[string]string, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return nil
+	}
+	proxies, err := parseNamedProxies(splitList(v))
+	if err != nil {
+		return err
+	}
+	*dst = proxies
+	return nil
+}
+
+func setFloat(dst *float64, key string) error {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("%s sayı olmalı: %q", key, v)
+	}
+	*dst = f
+	return nil
+}
+
+func setInt(dst *int, key string) error {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s geçersiz: %q (tam sayı olmalı)", key, v)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, key string) error {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s geçersiz: %q (true/false)", key, v)
+	}
+	*dst = b
+	return nil
+}
+
+func setDuration(dst *Duration, key string) error {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return nil
+	}
+	d, err := parseSeconds(v)
+	if err != nil {
+		return fmt.Errorf("%s geçersiz: %q (örn. 600 veya 10m)", key, v)
+	}
+	*dst = Duration(d)
+	return nil
+}
+
+// splitList: Virgülle ayrılmış listeyi boşlukları kırparak böler
+func splitList(s string) []string {
+	out := []string{}
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseSeconds: "600" gibi saniye değerini veya "10m" gibi Go süresini çözer
+func parseSeconds(v string) (time.Duration, error) {
+	if n, err := strconv.Atoi(v); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	return time.ParseDuration(v)
+}
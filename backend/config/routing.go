@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Yerleşik bağlantı yolları. Routing.Proxies içindeki adlar da yol olarak kullanılabilir.
+const (
+	RouteDirect = "direct" // Proxy'siz doğrudan bağlantı
+	RouteTor    = "tor"    // Tor havuzu (her denemede ayrı devre)
+	RouteI2P    = "i2p"    // I2P HTTP proxy'si
+)
+
+const defaultI2PProxy = "http://127.0.0.1:4444"
+
+// RoutingConfig: Hedefin hangi yoldan taranacağını belirler.
+// Önce watchlist/tarama isteğindeki seçim, sonra en uzun eşleşen host soneki kuralı, en son Default kullanılır.
+type RoutingConfig struct {
+	Default       string            `json:"default" yaml:"default" toml:"default"`
+	Rules         []RouteRule       `json:"rules" yaml:"rules" toml:"rules"`
+	Proxies       map[string]string `json:"proxies" yaml:"proxies" toml:"proxies"` // Ad -> socks5:// veya http(s):// upstream proxy
+	I2PProxy      string            `json:"i2p_proxy" yaml:"i2p_proxy" toml:"i2p_proxy"`
+	CompleteOnion bool              `json:"complete_onion" yaml:"complete_onion" toml:"complete_onion"` // Uzantısız adreslere .onion eklenir
+}
+
+// RouteRule: Host bu sonekle bitiyorsa (veya ona eşitse) Route kullanılır. Örn. ".i2p" veya "pastebin.com".
+type RouteRule struct {
+	Suffix string `json:"suffix" yaml:"suffix" toml:"suffix"`
+	Route  string `json:"route" yaml:"route" toml:"route"`
+}
+
+func defaultRouting() RoutingConfig {
+	return RoutingConfig{
+		Default: RouteTor,
+		Rules: []RouteRule{
+			{Suffix: ".onion", Route: RouteTor},
+			{Suffix: ".i2p", Route: RouteI2P},
+		},
+		I2PProxy:      defaultI2PProxy,
+		CompleteOnion: true,
+	}
+}
+
+// RouteNames: Seçilebilecek tüm yollar (yerleşikler ve adlandırılmış proxy'ler)
+func (r RoutingConfig) RouteNames() []string {
+	names := []string{RouteDirect, RouteTor}
+	if r.I2PProxy != "" {
+		names = append(names, RouteI2P)
+	}
+	named := make([]string, 0, len(r.Proxies))
+	for name := range r.Proxies {
+		named = append(named, name)
+	}
+	sort.Strings(named)
+	return append(names, named...)
+}
+
+// HasRoute: Yol adı tanımlı mı
+func (r RoutingConfig) HasRoute(name string) bool {
+	switch name {
+	case RouteDirect, RouteTor:
+		return true
+	case RouteI2P:
+		return r.I2PProxy != ""
+	}
+	_, ok := r.Proxies[name]
+	return ok
+}
+
+// Validate: Kuralların ve proxy'lerin tanımlı yollara işaret ettiğini kontrol eder
+func (r RoutingConfig) Validate() error {
+	if r.I2PProxy != "" {
+		if err := validateProxy(r.I2PProxy, "I2P_PROXY"); err != nil {
+			return err
+		}
+	}
+	for name, proxy := range r.Proxies {
+		if name == RouteDirect || name == RouteTor || name == RouteI2P {
+			return fmt.Errorf("Proxy adı yerleşik bir yolla çakışıyor: %s", name)
+		}
+		if !isToken(name) {
+			return fmt.Errorf("Geçersiz proxy adı: %q", name)
+		}
+		if err := validateProxy(proxy, "ROUTE_PROXIES"); err != nil {
+			return err
+		}
+	}
+	if !r.HasRoute(r.Default) {
+		return fmt.Errorf("Varsayılan bağlantı yolu tanımlı değil (ROUTE_DEFAULT): %q", r.Default)
+	}
+	for _, rule := range r.Rules {
+		if strings.Trim(rule.Suffix, ".") == "" {
+			return fmt.Errorf("Yönlendirme kuralında host soneki boş olamaz")
+		}
+		if !r.HasRoute(rule.Route) {
+			return fmt.Errorf("Yönlendirme kuralı tanımsız bir yola işaret ediyor: %s=%s", rule.Suffix, rule.Route)
+		}
+	}
+	return nil
+}
+
+// parseRouteRules: "suffix=yol" çiftlerinden oluşan liste (örn. ".i2p=i2p,pastebin.com=direct")
+func parseRouteRules(items []string) ([]RouteRule, error) {
+	rules := make([]RouteRule, 0, len(items))
+	for _, item := range items {
+		suffix, route, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("Geçersiz yönlendirme kuralı (ROUTE_RULES): %q (örn. .i2p=i2p)", item)
+		}
+		rules = append(rules, RouteRule{Suffix: strings.ToLower(strings.TrimSpace(suffix)), Route: strings.TrimSpace(route)})
+	}
+	return rules, nil
+}
+
+// parseNamedProxies: "ad=url" çiftlerinden oluşan liste (örn. "corp=http://10.0.0.1:3128")
+func parseNamedProxies(items []string) (map[string]string, error) {
+	proxies := make(map[string]string, len(items))
+	for _, item := range items {
+		name, proxy, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("Geçersiz proxy tanımı (ROUTE_PROXIES): %q (örn. corp=http://10.0.0.1:3128)", item)
+		}
+		proxies[strings.TrimSpace(name)] = strings.TrimSpace(proxy)
+	}
+	return proxies, nil
+}
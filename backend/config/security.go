@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Varsayılan CORS ve güvenlik başlığı ayarları
+const (
+	defaultAllowedOrigins = "http://localhost:3000"
+	defaultAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	defaultAllowedHeaders = "Authorization, Content-Type, X-API-Key, Accept, Cache-Control, X-Requested-With"
+	defaultExposedHeaders = "Retry-After"
+	defaultCORSMaxAge     = 10 * time.Minute
+	// API sadece JSON ve SSE döndürür; sayfa içine gömülmesine veya kaynak yüklemesine gerek yoktur
+	defaultCSP            = "default-src 'none'; frame-ancestors 'none'; base-uri 'none'"
+	defaultFrameOptions   = "DENY"
+	defaultReferrerPolicy = "no-referrer"
+)
+
+// API'de gizli değerlerin yerine gösterilen metin
+const redactedValue = "***"
+
+// Tarayıcıların tanıdığı Referrer-Policy değerleri
+var referrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+// SecurityConfig: CORS izinleri ve her yanıta eklenen güvenlik başlıkları
+type SecurityConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins" yaml:"allowed_origins" toml:"allowed_origins"` // Tam origin listesi (şema://host[:port]) veya tek başına "*"
+	AllowedMethods   []string `json:"allowed_methods" yaml:"allowed_methods" toml:"allowed_methods"` // Preflight yanıtında izin verilen HTTP metotları
+	AllowedHeaders   []string `json:"allowed_headers" yaml:"allowed_headers" toml:"allowed_headers"` // Preflight yanıtında izin verilen istek başlıkları
+	ExposedHeaders   []string `json:"exposed_headers" yaml:"exposed_headers" toml:"exposed_headers"` // Tarayıcıdaki betiklerin okuyabileceği yanıt başlıkları
+	AllowCredentials bool     `json:"allow_credentials" yaml:"allow_credentials" toml:"allow_credentials"`
+	MaxAge           Duration `json:"max_age" yaml:"max_age" toml:"max_age"` // Preflight sonucunun tarayıcı önbelleğinde kalma süresi
+
+	ContentSecurityPolicy string `json:"content_security_policy" yaml:"content_security_policy" toml:"content_security_policy"` // Boşsa gönderilmez
+	FrameOptions          string `json:"frame_options" yaml:"frame_options" toml:"frame_options"`                               // DENY veya SAMEORIGIN, boşsa gönderilmez
+	ReferrerPolicy        string `json:"referrer_policy" yaml:"referrer_policy" toml:"referrer_policy"`                         // Boşsa gönderilmez
+}
+
+func defaultSecurity() SecurityConfig {
+	return SecurityConfig{
+		AllowedOrigins:        splitList(defaultAllowedOrigins),
+		AllowedMethods:        splitList(defaultAllowedMethods),
+		AllowedHeaders:        splitList(defaultAllowedHeaders),
+		ExposedHeaders:        splitList(defaultExposedHeaders),
+		MaxAge:                Duration(defaultCORSMaxAge),
+		ContentSecurityPolicy: defaultCSP,
+		FrameOptions:          defaultFrameOptions,
+		ReferrerPolicy:        defaultReferrerPolicy,
+	}
+}
+
+// AllowAllOrigins: Tüm origin'lere izin verilip verilmediği
+func (s SecurityConfig) AllowAllOrigins() bool {
+	return len(s.AllowedOrigins) == 1 && s.AllowedOrigins[0] == "*"
+}
+
+// Validate: Yanlış yazılmış bir ayarın sessizce tüm origin'lere izin vermesini veya
+// tarayıcının başlığı yok saymasını önler. Origin'ler karşılaştırma için normalize edilir.
+func (s *SecurityConfig) Validate() error {
+	if len(s.AllowedOrigins) == 0 {
+		return fmt.Errorf("En az bir izinli origin tanımlanmalı (CORS_ALLOWED_ORIGINS)")
+	}
+	for i, origin := range s.AllowedOrigins {
+		if origin == "*" {
+			if len(s.AllowedOrigins) > 1 {
+				return fmt.Errorf("\"*\" diğer origin'lerle birlikte kullanılamaz")
+			}
+			if s.AllowCredentials {
+				return fmt.Errorf("\"*\" origin'i kimlik bilgisi izniyle (CORS_ALLOW_CREDENTIALS) birlikte kullanılamaz")
+			}
+			continue
+		}
+		normalized, err := normalizeOrigin(origin)
+		if err != nil {
+			return err
+		}
+		s.AllowedOrigins[i] = normalized
+	}
+
+	if len(s.AllowedMethods) == 0 {
+		return fmt.Errorf("En az bir HTTP metodu tanımlanmalı (CORS_ALLOWED_METHODS)")
+	}
+	for i, m := range s.AllowedMethods {
+		if !isToken(m) {
+			return fmt.Errorf("Geçersiz HTTP metodu: %q", m)
+		}
+		s.AllowedMethods[i] = strings.ToUpper(m)
+	}
+	for _, h := range append(append([]string{}, s.AllowedHeaders...), s.ExposedHeaders...) {
+		if !isToken(h) {
+			return fmt.Errorf("Geçersiz başlık adı: %q", h)
+		}
+	}
+	if s.MaxAge < 0 {
+		return fmt.Errorf("CORS_MAX_AGE negatif olamaz")
+	}
+
+	s.FrameOptions = strings.ToUpper(s.FrameOptions)
+	if s.FrameOptions != "" && s.FrameOptions != "DENY" && s.FrameOptions != "SAMEORIGIN" {
+		return fmt.Errorf("Geçersiz X-Frame-Options: %q (DENY veya SAMEORIGIN)", s.FrameOptions)
+	}
+	s.ReferrerPolicy = strings.ToLower(s.ReferrerPolicy)
+	if s.ReferrerPolicy != "" && !referrerPolicies[s.ReferrerPolicy] {
+		return fmt.Errorf("Geçersiz Referrer-Policy: %q", s.ReferrerPolicy)
+	}
+	if strings.ContainsAny(s.ContentSecurityPolicy, "\r\n") {
+		return fmt.Errorf("Content-Security-Policy satır sonu içeremez")
+	}
+	return nil
+}
+
+// normalizeOrigin: Origin başlığıyla birebir karşılaştırılabilmesi için şema://host[:port] biçimine getirir
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("Geçersiz origin: %q (örn. https://panel.example.com)", origin)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("Origin yol, sorgu veya kullanıcı bilgisi içeremez: %q", origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// isToken: RFC 7230 token karakterleri (metot ve başlık adları için)
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > 127 || !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ham sayfa arşivinin varsayılanları
+const (
+	defaultSnapshotDir     = "data/snapshots"
+	defaultSnapshotMaxAge  = 30 * 24 * time.Hour
+	defaultSnapshotMaxSize = 1024 // MB
+)
+
+// SnapshotConfig: Taranan sayfaların ham gövdelerinin arşivi (SHA-256 ile adlandırılmış, gzip'li dosyalar).
+// MaxAge veya MaxSizeMB sıfırsa o sınır uygulanmaz.
+type SnapshotConfig struct {
+	Enabled   bool     `json:"enabled" yaml:"enabled" toml:"enabled"`
+	Dir       string   `json:"dir" yaml:"dir" toml:"dir"`
+	MaxAge    Duration `json:"max_age" yaml:"max_age" toml:"max_age"`             // Bu süreden eski kayıtlar silinir
+	MaxSizeMB int      `json:"max_size_mb" yaml:"max_size_mb" toml:"max_size_mb"` // Aşılırsa en eski kayıtlardan silinir
+}
+
+func defaultSnapshots() SnapshotConfig {
+	return SnapshotConfig{
+		Enabled:   true,
+		Dir:       defaultSnapshotDir,
+		MaxAge:    Duration(defaultSnapshotMaxAge),
+		MaxSizeMB: defaultSnapshotMaxSize,
+	}
+}
+
+// MaxBytes: Arşivin disk üzerindeki (sıkıştırılmış) boyut sınırı
+func (s SnapshotConfig) MaxBytes() int64 {
+	return int64(s.MaxSizeMB) << 20
+}
+
+// Validate: Arşiv açıksa dizin ve saklama sınırlarını kontrol eder
+func (s SnapshotConfig) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(s.Dir) == "" {
+		return fmt.Errorf("Sayfa arşivi dizini boş olamaz (SNAPSHOT_DIR)")
+	}
+	if s.MaxAge < 0 {
+		return fmt.Errorf("Sayfa arşivi saklama süresi negatif olamaz (SNAPSHOT_MAX_AGE)")
+	}
+	if s.MaxSizeMB < 0 {
+		return fmt.Errorf("Sayfa arşivi boyut sınırı negatif olamaz (SNAPSHOT_MAX_SIZE_MB)")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Tor sağlık kontrolü aralığının sınırları
+const (
+	minTorHealthInterval = 5 * time.Second
+	maxTorHealthInterval = 10 * time.Minute
+)
+
+// EndpointProxies: Havuza girecek proxy listesi (Proxies, yoksa Proxy). Boşsa otomatik tespit kullanılır.
+func (t TorConfig) EndpointProxies() []string {
+	if len(t.Proxies) > 0 {
+		return t.Proxies
+	}
+	if t.Proxy != "" {
+		return []string{t.Proxy}
+	}
+	return nil
+}
+
+// ControlFor: i. proxy'nin Tor kontrol portu. Tek adres verilmişse tüm proxy'ler aynı Tor örneğidir.
+func (t TorConfig) ControlFor(i int) string {
+	switch {
+	case len(t.ControlAddrs) == 1:
+		return t.ControlAddrs[0]
+	case i < len(t.ControlAddrs):
+		return t.ControlAddrs[i]
+	}
+	return ""
+}
+
+// Validate: Proxy adreslerini ve kontrol portu eşleşmesini kontrol eder
+func (t TorConfig) Validate() error {
+	proxies := t.EndpointProxies()
+	for _, p := range proxies {
+		if err := validateProxy(p, "TOR_PROXY"); err != nil {
+			return err
+		}
+	}
+	if n := len(t.ControlAddrs); n > 1 && n != len(proxies) {
+		return fmt.Errorf("Tor kontrol adresi sayısı (%d) proxy sayısıyla (%d) eşleşmeli veya tek adres verilmeli", n, len(proxies))
+	}
+	if len(t.ControlAddrs) > 0 && len(proxies) == 0 {
+		return fmt.Errorf("Tor kontrol adresi verildiğinde proxy listesi de verilmeli (TOR_PROXIES)")
+	}
+	for _, addr := range t.ControlAddrs {
+		if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+			return fmt.Errorf("Geçersiz Tor kontrol adresi: %q (örn. 127.0.0.1:9051)", addr)
+		}
+	}
+	if t.HealthInterval.Std() < minTorHealthInterval || t.HealthInterval.Std() > maxTorHealthInterval {
+		return fmt.Errorf("Tor sağlık kontrolü aralığı %s ile %s arasında olmalı", minTorHealthInterval, maxTorHealthInterval)
+	}
+	return nil
+}
+
+// validateProxy: Proxy adresi şema ve host içermelidir (örn. socks5://127.0.0.1:9050)
+func validateProxy(proxy, key string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("Geçersiz proxy adresi (%s): örn. socks5://127.0.0.1:9050", key)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http", "https":
+		return nil
+	}
+	return fmt.Errorf("Desteklenmeyen proxy şeması: %s (socks5, socks5h, http, https)", u.Scheme)
+}
+
+// redactProxy: Proxy adresindeki kullanıcı adı ve şifreyi gizler
+func redactProxy(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return proxy
+	}
+	u.User = nil
+	return strings.Replace(u.String(), "://", "://"+redactedValue+"@", 1)
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"scraper/scraper"
+	"time"
+)
+
+// Çalışırken değiştirilebilen ayarların sınırları
+const (
+	maxScanWorkers   = 32
+	minScanTimeout   = 1 * time.Second
+	maxScanTimeout   = 5 * time.Minute
+	minContentLimit  = 100
+	maxContentLimit  = 100000
+	minSchedulerTick = 10 * time.Second
+	maxSchedulerTick = 1 * time.Hour
+)
+
+// Tunables: Tarama motoru ve zamanlayıcının çalışırken Ayarlar sayfasından değiştirilebilen değerleri.
+// Değişiklikler veritabanında saklanır ve açılışta dosya/ortam değerlerinin üzerine uygulanır.
+type Tunables struct {
+	ScanTimeout       Duration `json:"scan_timeout"`
+	ScanMaxRetries    int      `json:"scan_max_retries"`
+	ScanRetryDelay    Duration `json:"scan_retry_delay"`
+	ScanMaxBackoff    Duration `json:"scan_max_backoff"`
+	ScanRetryJitter   float64  `json:"scan_retry_jitter"`
+	ScanRetryOn       []string `json:"scan_retry_on"`
+	ScanSwitchCircuit bool     `json:"scan_switch_circuit"`
+	ScanContentLimit  int      `json:"scan_content_limit"`
+	SchedulerTick     Duration `json:"scheduler_tick"`
+}
+
+var (
+	base    Config                   // Load ile okunan, veritabanı değişiklikleri uygulanmamış ayarlar
+	changed = make(chan struct{}, 1) // Tunables değiştiğinde zamanlayıcıyı uyandırır
+)
+
+// Tunables: Ayarların çalışırken değiştirilebilen kısmı
+func (cfg Config) Tunables() Tunables {
+	return Tunables{
+		ScanTimeout:       cfg.Scan.Timeout,
+		ScanMaxRetries:    cfg.Scan.MaxRetries,
+		ScanRetryDelay:    cfg.Scan.RetryDelay,
+		ScanMaxBackoff:    cfg.Scan.MaxBackoff,
+		ScanRetryJitter:   cfg.Scan.RetryJitter,
+		ScanRetryOn:       cfg.Scan.RetryOn,
+		ScanSwitchCircuit: cfg.Scan.SwitchCircuit,
+		ScanContentLimit:  cfg.Scan.ContentLimit,
+		SchedulerTick:     cfg.Scheduler.Tick,
+	}
+}
+
+func (cfg *Config) applyTunables(t Tunables) {
+	cfg.Scan.Timeout = t.ScanTimeout
+	cfg.Scan.MaxRetries = t.ScanMaxRetries
+	cfg.Scan.RetryDelay = t.ScanRetryDelay
+	cfg.Scan.MaxBackoff = t.ScanMaxBackoff
+	cfg.Scan.RetryJitter = t.ScanRetryJitter
+	cfg.Scan.RetryOn = t.ScanRetryOn
+	cfg.Scan.SwitchCircuit = t.ScanSwitchCircuit
+	cfg.Scan.ContentLimit = t.ScanContentLimit
+	cfg.Scheduler.Tick = t.SchedulerTick
+}
+
+// Validate: Değerlerin makul sınırlar içinde olduğunu kontrol eder
+func (t Tunables) Validate() error {
+	if t.ScanTimeout.Std() < minScanTimeout || t.ScanTimeout.Std() > maxScanTimeout {
+		return fmt.Errorf("Tarama zaman aşımı %s ile %s arasında olmalı", minScanTimeout, maxScanTimeout)
+	}
+	if len(t.ScanRetryOn) == 0 {
+		return fmt.Errorf("Yeniden denenecek en az bir hata kodu seçilmeli")
+	}
+	policy := scraper.RetryPolicy{
+		MaxAttempts:   t.ScanMaxRetries,
+		BaseBackoff:   t.ScanRetryDelay.Std(),
+		MaxBackoff:    t.ScanMaxBackoff.Std(),
+		Jitter:        t.ScanRetryJitter,
+		RetryOn:       t.ScanRetryOn,
+		SwitchCircuit: t.ScanSwitchCircuit,
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if t.ScanContentLimit < minContentLimit || t.ScanContentLimit > maxContentLimit {
+		return fmt.Errorf("İçerik sınırı %d-%d karakter arasında olmalı", minContentLimit, maxContentLimit)
+	}
+	if t.SchedulerTick.Std() < minSchedulerTick || t.SchedulerTick.Std() > maxSchedulerTick {
+		return fmt.Errorf("Zamanlayıcı aralığı %s ile %s arasında olmalı", minSchedulerTick, maxSchedulerTick)
+	}
+	return nil
+}
+
+// Base: Dosya ve ortam değişkenlerinden gelen, veritabanı değişiklikleri uygulanmamış ayarlar
+func Base() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return base
+}
+
+// SetTunables: Doğrulanmış değerleri güncel ayarlara uygular ve zamanlayıcıya haber verir
+func SetTunables(t Tunables) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	current.applyTunables(t)
+	mu.Unlock()
+
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// TunablesChanged: Çalışırken yapılan değişikliklerde sinyal veren kanal (tek dinleyici içindir)
+func TunablesChanged() <-chan struct{} {
+	return changed
+}
+
+// RetryPolicy: Tarama ayarlarından motorun yeniden deneme politikası
+func (s ScanConfig) RetryPolicy() scraper.RetryPolicy {
+	return scraper.RetryPolicy{
+		MaxAttempts:   s.MaxRetries,
+		BaseBackoff:   s.RetryDelay.Std(),
+		MaxBackoff:    s.MaxBackoff.Std(),
+		Jitter:        s.RetryJitter,
+		RetryOn:       s.RetryOn,
+		SwitchCircuit: s.SwitchCircuit,
+	}
+}
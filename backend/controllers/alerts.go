@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"scraper/models"
+	"scraper/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AlertController struct {
+	DB *gorm.DB
+}
+
+func NewAlertController(db *gorm.DB) *AlertController {
+	return &AlertController{DB: db}
+}
+
+var validSeverities = map[string]bool{
+	models.SeverityLow:      true,
+	models.SeverityMedium:   true,
+	models.SeverityHigh:     true,
+	models.SeverityCritical: true,
+}
+
+// validateRule: Önem derecesini, keyword'ü ve kanalları kontrol eder
+func (ctrl *AlertController) validateRule(rule *models.AlertRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("Kural adı gerekli")
+	}
+	if rule.Severity == "" {
+		rule.Severity = models.SeverityMedium
+	}
+	if !validSeverities[rule.Severity] {
+		return fmt.Errorf("Geçersiz önem derecesi: %s (low, medium, high, critical)", rule.Severity)
+	}
+
+	var keyword models.Keyword
+	if err := ctrl.DB.First(&keyword, rule.KeywordID).Error; err != nil {
+		return fmt.Errorf("Keyword bulunamadı")
+	}
+
+	if len(rule.ChannelIDs) > 0 {
+		var count int64
+		ctrl.DB.Model(&models.AlertChannel{}).Where("id IN ?", rule.ChannelIDs).Count(&count)
+		if int(count) != len(rule.ChannelIDs) {
+			return fmt.Errorf("Bildirim kanallarından biri bulunamadı")
+		}
+	}
+	return nil
+}
+
+// GetRules: Tüm alarm kurallarını listeler
+func (ctrl *AlertController) GetRules(c *gin.Context) {
+	var rules []models.AlertRule
+	if err := ctrl.DB.Preload("Keyword").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Alarm kuralları getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// AddRule: Yeni alarm kuralı ekler
+func (ctrl *AlertController) AddRule(c *gin.Context) {
+	var input models.AlertRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.validateRule(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.Keyword = models.Keyword{}
+	if err := ctrl.DB.Omit("Keyword").Create(&input).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Alarm kuralı eklenemedi: "+input.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Alarm kuralı eklenemedi"})
+		return
+	}
+
+	ctrl.DB.Preload("Keyword").First(&input, input.ID)
+	utils.LogInfo(ctrl.DB, "ALERT", fmt.Sprintf("Alarm kuralı eklendi: %s [%s]", input.Name, input.Severity))
+	utils.Audit(c, ctrl.DB, "alert_rule.create", "alert_rule", input.ID, nil, input)
+	c.JSON(http.StatusOK, input)
+}
+
+// UpdateRule: Alarm kuralını günceller
+func (ctrl *AlertController) UpdateRule(c *gin.Context) {
+	id := c.Param("id")
+	var rule models.AlertRule
+
+	if err := ctrl.DB.First(&rule, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alarm kuralı bulunamadı"})
+		return
+	}
+	before := rule
+
+	var input models.AlertRule
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.validateRule(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule.Name = input.Name
+	rule.KeywordID = input.KeywordID
+	rule.Severity = input.Severity
+	rule.SiteIDs = input.SiteIDs
+	rule.WatchlistIDs = input.WatchlistIDs
+	rule.ChannelIDs = input.ChannelIDs
+	rule.IsActive = input.IsActive
+
+	if err := ctrl.DB.Omit("Keyword").Save(&rule).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Alarm kuralı güncellenemedi: "+rule.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Alarm kuralı güncellenemedi"})
+		return
+	}
+
+	ctrl.DB.Preload("Keyword").First(&rule, rule.ID)
+	utils.LogInfo(ctrl.DB, "ALERT", "Alarm kuralı güncellendi: "+rule.Name)
+	utils.Audit(c, ctrl.DB, "alert_rule.update", "alert_rule", rule.ID, before, rule)
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule: Alarm kuralını siler
+func (ctrl *AlertController) DeleteRule(c *gin.Context) {
+	id := c.Param("id")
+	var before models.AlertRule
+	ctrl.DB.Find(&before, id)
+	if err := ctrl.DB.Delete(&models.AlertRule{}, id).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Alarm kuralı silinemedi ID: "+id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Alarm kuralı silinemedi"})
+		return
+	}
+	utils.LogInfo(ctrl.DB, "ALERT", "Alarm kuralı silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "alert_rule.delete", "alert_rule", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Alarm kuralı silindi"})
+}
+
+// hideSecrets: Parola ve imza anahtarı yanıtlarda gösterilmez
+func hideSecrets(ch *models.AlertChannel) {
+	ch.SMTPPassword = ""
+	ch.Secret = ""
+}
+
+// GetChannels: Bildirim kanallarını listeler
+func (ctrl *AlertController) GetChannels(c *gin.Context) {
+	var channels []models.AlertChannel
+	if err := ctrl.DB.Find(&channels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kanalları getirilemedi"})
+		return
+	}
+	for i := range channels {
+		hideSecrets(&channels[i])
+	}
+	c.JSON(http.StatusOK, channels)
+}
+
+// AddChannel: Yeni bildirim kanalı ekler
+func (ctrl *AlertController) AddChannel(c *gin.Context) {
+	var input models.AlertChannel
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := utils.ValidateChannel(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.DB.Create(&input).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Bildirim kanalı eklenemedi: "+input.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kanalı eklenemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "ALERT", fmt.Sprintf("Bildirim kanalı eklendi: %s (%s)", input.Name, input.Type))
+	hideSecrets(&input)
+	utils.Audit(c, ctrl.DB, "alert_channel.create", "alert_channel", input.ID, nil, input)
+	c.JSON(http.StatusOK, input)
+}
+
+// UpdateChannel: Bildirim kanalını günceller. Boş gönderilen parola/anahtar korunur.
+func (ctrl *AlertController) UpdateChannel(c *gin.Context) {
+	id := c.Param("id")
+	var channel models.AlertChannel
+
+	if err := ctrl.DB.First(&channel, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bildirim kanalı bulunamadı"})
+		return
+	}
+	before := channel
+	hideSecrets(&before)
+
+	var input models.AlertChannel
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.SMTPPassword == "" {
+		input.SMTPPassword = channel.SMTPPassword
+	}
+	if input.Secret == "" {
+		input.Secret = channel.Secret
+	}
+
+	if err := utils.ValidateChannel(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel.Name = input.Name
+	channel.Type = input.Type
+	channel.URL = input.URL
+	channel.Secret = input.Secret
+	channel.ChatID = input.ChatID
+	channel.SMTPHost = input.SMTPHost
+	channel.SMTPPort = input.SMTPPort
+	channel.SMTPUsername = input.SMTPUsername
+	channel.SMTPPassword = input.SMTPPassword
+	channel.SMTPFrom = input.SMTPFrom
+	channel.SMTPTo = input.SMTPTo
+	channel.IsActive = input.IsActive
+
+	if err := ctrl.DB.Save(&channel).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Bildirim kanalı güncellenemedi: "+channel.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kanalı güncellenemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "ALERT", "Bildirim kanalı güncellendi: "+channel.Name)
+	hideSecrets(&channel)
+	utils.Audit(c, ctrl.DB, "alert_channel.update", "alert_channel", channel.ID, before, channel)
+	c.JSON(http.StatusOK, channel)
+}
+
+// DeleteChannel: Bildirim kanalını siler
+func (ctrl *AlertController) DeleteChannel(c *gin.Context) {
+	id := c.Param("id")
+	var before models.AlertChannel
+	ctrl.DB.Find(&before, id)
+	hideSecrets(&before)
+	if err := ctrl.DB.Delete(&models.AlertChannel{}, id).Error; err != nil {
+		utils.LogError(ctrl.DB, "ALERT", "Bildirim kanalı silinemedi ID: "+id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kanalı silinemedi"})
+		return
+	}
+	utils.LogInfo(ctrl.DB, "ALERT", "Bildirim kanalı silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "alert_channel.delete", "alert_channel", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Bildirim kanalı silindi"})
+}
+
+// TestChannel: Kanala örnek bir alarm gönderir ve gönderim kaydını döndürür
+func (ctrl *AlertController) TestChannel(c *gin.Context) {
+	var channel models.AlertChannel
+	if err := ctrl.DB.First(&channel, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bildirim kanalı bulunamadı"})
+		return
+	}
+
+	msg := utils.AlertMessage{
+		Rule:     "Test",
+		Severity: models.SeverityLow,
+		Keyword:  "test",
+		SiteURL:  "http://example.onion",
+		Hits: []utils.AlertHit{{
+			ThreadTitle: "Test bildirimi",
+			Author:      "galileoff",
+			Date:        time.Now().Format("2006-01-02 15:04"),
+			Excerpt:     "Bu mesaj bildirim kanalı ayarlarını doğrulamak için gönderildi.",
+		}},
+		Time: time.Now(),
+	}
+
+	delivery := utils.DeliverAlert(ctrl.DB, channel, msg, nil, nil)
+	if delivery.Status != models.DeliverySent {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Test bildirimi gönderilemedi: " + delivery.Error, "delivery": delivery})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Test bildirimi gönderildi", "delivery": delivery})
+}
+
+// GetDeliveries: Son bildirim gönderimlerini listeler (?status=failed ile süzülebilir)
+func (ctrl *AlertController) GetDeliveries(c *gin.Context) {
+	query := ctrl.DB.Order("created_at desc").Limit(500)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.AlertDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bildirim kayıtları getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"scraper/models"
+	"scraper/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyInput: Anahtar oluşturma isteği. expires_at boşsa anahtar süresizdir.
+type APIKeyInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scope     string     `json:"scope" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// requireSession: Anahtar yönetimi sadece oturumla yapılabilir; sızan bir anahtar kendine yeni anahtar üretemez
+func requireSession(c *gin.Context) bool {
+	if _, ok := c.Get(utils.ContextAPIKeyID); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API anahtarları sadece oturum açılarak yönetilebilir"})
+		return false
+	}
+	return true
+}
+
+// GetAPIKeys: Kullanıcının kendi API anahtarlarını listeler
+func (ac *AuthController) GetAPIKeys(c *gin.Context) {
+	keys := []models.APIKey{}
+	if err := ac.DB.Where("user_id = ?", c.GetUint(utils.ContextUserID)).Order("id DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarları getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey: Yeni API anahtarı oluşturur. Anahtar yanıtta bir kez gösterilir, sonra geri alınamaz.
+func (ac *AuthController) CreateAPIKey(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+
+	var input APIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Anahtar adı ve kapsamı gerekli"})
+		return
+	}
+	if !models.ValidScope(input.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kapsam (read, scan, admin)"})
+		return
+	}
+	// Kullanıcı, rolünün üstünde kapsamlı anahtar oluşturamaz
+	if !models.ScopeAllowed(input.Scope, c.GetString(utils.ContextRole)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Bu kapsam için yetkiniz yok"})
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bitiş tarihi gelecekte olmalı"})
+		return
+	}
+
+	plain, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı oluşturulamadı"})
+		return
+	}
+	key := models.APIKey{
+		UserID:    c.GetUint(utils.ContextUserID),
+		Name:      input.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scope:     input.Scope,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := ac.DB.Create(&key).Error; err != nil {
+		utils.LogError(ac.DB, "AUTH", "API anahtarı oluşturulamadı: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı oluşturulamadı"})
+		return
+	}
+
+	utils.LogInfo(ac.DB, "AUTH", fmt.Sprintf("API anahtarı oluşturuldu: %s (%s, %s)", key.Name, key.Scope, c.GetString(utils.ContextUsername)))
+	utils.Audit(c, ac.DB, "api_key.create", "api_key", key.ID, nil, key)
+	c.JSON(http.StatusOK, gin.H{"key": plain, "api_key": key})
+}
+
+// RevokeAPIKey: Kullanıcının kendi API anahtarını iptal eder
+func (ac *AuthController) RevokeAPIKey(c *gin.Context) {
+	var key models.APIKey
+	if err := ac.DB.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint(utils.ContextUserID)).First(&key).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API anahtarı bulunamadı"})
+		return
+	}
+	if key.RevokedAt != nil {
+		c.JSON(http.StatusOK, key)
+		return
+	}
+
+	before := key
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := ac.DB.Model(&key).Update("revoked_at", &now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı iptal edilemedi"})
+		return
+	}
+
+	utils.LogInfo(ac.DB, "AUTH", fmt.Sprintf("API anahtarı iptal edildi: %s (%s)", key.Name, c.GetString(utils.ContextUsername)))
+	utils.Audit(c, ac.DB, "api_key.revoke", "api_key", key.ID, before, key)
+	c.JSON(http.StatusOK, key)
+}
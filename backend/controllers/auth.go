@@ -1,14 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
-	"os"
 	"scraper/models"
 	"scraper/utils"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -33,10 +34,18 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	// Kaba kuvvet koruması: bekleme süresi dolmadan şifre kontrol edilmez
+	if loginBlocked(c, ac.DB, req.Username) {
+		return
+	}
+
 	var user models.User
 	// Kullanıcıyı bul
 	if err := ac.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
 		utils.LogWarn(ac.DB, "AUTH", "Bilinmeyen kullanıcı giriş denemesi: "+req.Username)
+		utils.RecordLoginFailure(ac.DB, c.ClientIP(), req.Username)
+		c.Set(utils.ContextUsername, req.Username)
+		utils.Audit(c, ac.DB, "auth.login_failed", "user", nil, nil, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Hatalı kullanıcı adı veya şifre."})
 		return
 	}
@@ -44,34 +53,210 @@ func (ac *AuthController) Login(c *gin.Context) {
 	// Şifreyi doğrula
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		utils.LogWarn(ac.DB, "AUTH", "Hatalı şifre denemesi: "+req.Username)
+		utils.RecordLoginFailure(ac.DB, c.ClientIP(), req.Username)
+		setActor(c, user)
+		utils.Audit(c, ac.DB, "auth.login_failed", "user", user.ID, nil, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Hatalı kullanıcı adı veya şifre."})
 		return
 	}
 
-	// Token oluştur
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 2).Unix(), // 2 saat geçerli
-	})
+	// İki adımlı doğrulama açıksa token'lar kod doğrulandıktan sonra verilir
+	if user.TOTPEnabled {
+		preAuth, err := utils.SignPreAuthToken(user)
+		if err != nil {
+			utils.LogError(ac.DB, "AUTH", "Token oluşturma hatası: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Token oluşturulamadı."})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":             "Doğrulama kodu gerekli",
+			"two_factor_required": true,
+			"pre_auth_token":      preAuth,
+			"expires_in":          int(utils.PreAuthTokenTTL.Seconds()),
+		})
+		return
+	}
+
+	ac.completeLogin(c, user)
+}
 
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sunucu yapılandırma hatası."})
+// completeLogin: Başarılı girişten sonra sayaçları sıfırlar, oturum açar ve token'ları döndürür
+func (ac *AuthController) completeLogin(c *gin.Context, user models.User) {
+	utils.RecordLoginSuccess(ac.DB, user.Username)
+	pair, ok := ac.issueTokens(c, user)
+	if !ok {
 		return
 	}
 
-	tokenString, err := token.SignedString([]byte(secret))
+	utils.LogSuccess(ac.DB, "AUTH", "Başarılı giriş: "+user.Username)
+	setActor(c, user)
+	utils.Audit(c, ac.DB, "auth.login", "user", user.ID, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":              "Giriş başarılı",
+		"token":                pair.AccessToken,
+		"refresh_token":        pair.RefreshToken,
+		"expires_in":           pair.ExpiresIn,
+		"user":                 user.Username,
+		"role":                 user.Role,
+		"must_change_password": user.MustChangePassword,
+	})
+}
+
+// loginBlocked: IP veya kullanıcı adı beklemedeyse 429 yazar ve true döner
+func loginBlocked(c *gin.Context, db *gorm.DB, username string) bool {
+	wait, locked := utils.LoginBlocked(db, c.ClientIP(), username)
+	if wait <= 0 {
+		return false
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	msg := fmt.Sprintf("Çok fazla hatalı deneme. %d saniye sonra tekrar deneyin.", seconds)
+	if locked {
+		msg = fmt.Sprintf("Hesap geçici olarak kilitlendi. %d dakika sonra tekrar deneyin veya yöneticinize başvurun.", (seconds+59)/60)
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": msg, "retry_after": seconds, "locked": locked})
+	return true
+}
+
+// setActor: Giriş rotası kimlik doğrulamasız olduğu için denetim kaydının kullanıcısı elle yazılır
+func setActor(c *gin.Context, user models.User) {
+	c.Set(utils.ContextUserID, user.ID)
+	c.Set(utils.ContextUsername, user.Username)
+}
+
+// issueTokens: Yeni oturum açıp erişim ve yenileme token'larını üretir. Hata olursa yanıtı yazar ve false döner.
+func (ac *AuthController) issueTokens(c *gin.Context, user models.User) (utils.TokenPair, bool) {
+	pair, err := utils.CreateSession(ac.DB, user, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		utils.LogError(ac.DB, "AUTH", "Token oluşturma hatası: "+err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token oluşturulamadı."})
+		return utils.TokenPair{}, false
+	}
+	return pair, true
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh: Yenileme token'ını yenisiyle değiştirir ve yeni erişim token'ı verir.
+// Her yenileme token'ı bir kez kullanılabilir.
+func (ac *AuthController) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Yenileme token'ı gerekli."})
 		return
 	}
 
-	utils.LogSuccess(ac.DB, "AUTH", "Başarılı giriş: "+user.Username)
+	user, pair, err := utils.RotateSession(ac.DB, req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidRefreshToken) || errors.Is(err, utils.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		utils.LogError(ac.DB, "AUTH", "Oturum yenilenemedi: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Oturum yenilenemedi."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":                pair.AccessToken,
+		"refresh_token":        pair.RefreshToken,
+		"expires_in":           pair.ExpiresIn,
+		"user":                 user.Username,
+		"role":                 user.Role,
+		"must_change_password": user.MustChangePassword,
+	})
+}
+
+// Logout: Kullanılan erişim token'ını ve ait olduğu oturumu iptal eder
+func (ac *AuthController) Logout(c *gin.Context) {
+	if err := utils.RevokeSessionByJTI(ac.DB, c.GetString(utils.ContextTokenID)); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.LogError(ac.DB, "AUTH", "Çıkış yapılamadı: "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Çıkış yapılamadı."})
+		return
+	}
+
+	utils.LogInfo(ac.DB, "AUTH", "Çıkış yapıldı: "+c.GetString(utils.ContextUsername))
+	utils.Audit(c, ac.DB, "auth.logout", "user", c.GetUint(utils.ContextUserID), nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Çıkış yapıldı"})
+}
+
+// GetAccount: Giriş yapmış kullanıcının bilgilerini döndürür
+func (ac *AuthController) GetAccount(c *gin.Context) {
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword: Kullanıcının kendi şifresini değiştirir ve zorunlu değişiklik işaretini kaldırır.
+// Diğer oturumlar kapatılır ve yeni token çifti döndürülür.
+func (ac *AuthController) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mevcut ve yeni şifre gerekli."})
+		return
+	}
+
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		utils.LogWarn(ac.DB, "AUTH", "Şifre değiştirme denemesinde hatalı şifre: "+user.Username)
+		utils.Audit(c, ac.DB, "auth.password_change_failed", "user", user.ID, nil, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mevcut şifre hatalı."})
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Yeni şifre mevcut şifreyle aynı olamaz."})
+		return
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Şifre kaydedilemedi."})
+		return
+	}
+	user.Password = string(hash)
+	user.MustChangePassword = false
+	if err := ac.DB.Model(&user).Select("password", "must_change_password").Updates(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Şifre kaydedilemedi."})
+		return
+	}
+
+	// Eski şifreyle açılmış tüm oturumlar kapatılır, bu istek için yeni oturum açılır
+	if _, err := utils.RevokeUserSessions(ac.DB, user.ID); err != nil {
+		utils.LogError(ac.DB, "AUTH", "Oturumlar kapatılamadı: "+err.Error())
+	}
+	pair, ok := ac.issueTokens(c, user)
+	if !ok {
+		return
+	}
 
+	utils.LogSuccess(ac.DB, "AUTH", "Şifre değiştirildi: "+user.Username)
+	utils.Audit(c, ac.DB, "auth.password_change", "user", user.ID, nil, nil)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Giriş başarılı",
-		"token":   tokenString,
-		"user":    user.Username,
+		"message":       "Şifre değiştirildi",
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
+		"user":          user.Username,
+		"role":          user.Role,
 	})
 }
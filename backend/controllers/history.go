@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"scraper/models"
+	"scraper/utils"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +30,7 @@ func (ctrl *HistoryController) GetHistory(c *gin.Context) {
 		LastScan     time.Time `json:"last_scan"`
 		TotalThreads int       `json:"total_threads"`
 		TotalPosts   int       `json:"total_posts"`
+		Changed      bool      `json:"changed"`
 		Category     string    `json:"category"`
 		Color        string    `json:"color"`
 	}
@@ -37,7 +39,7 @@ func (ctrl *HistoryController) GetHistory(c *gin.Context) {
 
 	// 1. Temel verileri çek
 	ctrl.DB.Table("stats").
-		Select("stats.id, sites.url, stats.source, stats.scan_date as last_scan, stats.total_threads, stats.total_posts, (SELECT category FROM threads WHERE threads.stats_id = stats.id LIMIT 1) as category").
+		Select("stats.id, sites.url, stats.source, stats.scan_date as last_scan, stats.total_threads, stats.total_posts, stats.changed, (SELECT threads.category FROM thread_sightings JOIN threads ON threads.id = thread_sightings.thread_id WHERE thread_sightings.stats_id = stats.id ORDER BY thread_sightings.id LIMIT 1) as category").
 		Joins("left join sites on stats.site_id = sites.id").
 		Order("stats.scan_date desc").
 		Scan(&history)
@@ -87,12 +89,13 @@ func (ctrl *HistoryController) GetScanDetails(c *gin.Context) {
 	id := c.Param("id") // StatsID
 
 	type ScanDetailsResponse struct {
-		ID           uint            `json:"id"`        // Stats ID
-		SiteUrl      string          `json:"url"`       // Site URL
-		ScanDate     time.Time       `json:"scan_date"` // Tarama Tarihi
-		TotalThreads int             `json:"total_threads"`
-		TotalPosts   int             `json:"total_posts"`
-		Threads      []models.Thread `json:"threads"`
+		ID           uint                  `json:"id"`        // Stats ID
+		SiteUrl      string                `json:"url"`       // Site URL
+		ScanDate     time.Time             `json:"scan_date"` // Tarama Tarihi
+		TotalThreads int                   `json:"total_threads"`
+		TotalPosts   int                   `json:"total_posts"`
+		Threads      []models.Thread       `json:"threads"`
+		Responses    []models.ScanMetadata `json:"responses"` // HTTP üst verisi (durum, başlıklar, yönlendirmeler)
 	}
 
 	// 1. Önce Stats bilgisini ve Site bilgisini çek
@@ -103,10 +106,20 @@ func (ctrl *HistoryController) GetScanDetails(c *gin.Context) {
 	}
 
 	// 2. Bu taramaya ait Thread'leri çek
-	var threads []models.Thread
-	ctrl.DB.Where("stats_id = ?", stats.ID).Preload("Posts").Find(&threads)
+	threads, err := utils.LoadScanThreads(ctrl.DB, stats.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tarama verileri getirilemedi"})
+		return
+	}
+
+	// 3. Sayfaların HTTP üst verisi
+	responses, err := utils.LoadScanMetadata(ctrl.DB, stats.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tarama üst verisi getirilemedi"})
+		return
+	}
 
-	// 3. Yanıtı oluştur
+	// 4. Yanıtı oluştur
 	response := ScanDetailsResponse{
 		ID:           stats.ID,
 		SiteUrl:      stats.Site.URL,
@@ -114,7 +127,45 @@ func (ctrl *HistoryController) GetScanDetails(c *gin.Context) {
 		TotalThreads: stats.TotalThreads,
 		TotalPosts:   stats.TotalPosts,
 		Threads:      threads,
+		Responses:    responses,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetScanDiff: Taramayı aynı sitenin başka bir taramasıyla karşılaştırır.
+// ?against verilmezse bir önceki tarama kullanılır.
+func (ctrl *HistoryController) GetScanDiff(c *gin.Context) {
+	var stats models.Stats
+	if err := ctrl.DB.First(&stats, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarama kaydı bulunamadı"})
+		return
+	}
+
+	var against models.Stats
+	if againstID := c.Query("against"); againstID != "" {
+		if err := ctrl.DB.First(&against, againstID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Karşılaştırılacak tarama bulunamadı"})
+			return
+		}
+	} else {
+		err := ctrl.DB.Where("site_id = ? AND id < ?", stats.SiteID, stats.ID).Order("id desc").First(&against).Error
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bu siteye ait önceki tarama yok"})
+			return
+		}
+	}
+
+	if against.SiteID != stats.SiteID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sadece aynı siteye ait taramalar karşılaştırılabilir"})
+		return
+	}
+
+	diff, err := utils.DiffScans(ctrl.DB, stats.ID, against.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Karşılaştırma yapılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"net/http"
+	"scraper/models"
+	"scraper/scraper"
+	"scraper/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileTestRequest: Profili kaydetmeden bir URL üzerinde denemek için istek
+type ProfileTestRequest struct {
+	URL       string                    `json:"url" binding:"required"`
+	ProfileID uint                      `json:"profile_id"` // Kayıtlı profil
+	Profile   *models.ExtractionProfile `json:"profile"`    // veya henüz kaydedilmemiş taslak
+	Crawl     scraper.CrawlOptions      `json:"crawl"`
+	Route     string                    `json:"route"` // Boşsa host kuralları
+}
+
+// GetProfiles: Tüm seçici profillerini listeler
+func (ctrl *SettingsController) GetProfiles(c *gin.Context) {
+	var profiles []models.ExtractionProfile
+	if err := ctrl.DB.Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Profiller getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// AddProfile: Yeni bir seçici profili ekler
+func (ctrl *SettingsController) AddProfile(c *gin.Context) {
+	var input models.ExtractionProfile
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := scraper.ValidateProfile(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.DB.Create(&input).Error; err != nil {
+		utils.LogError(ctrl.DB, "SETTINGS", "Profil eklenemedi: "+input.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Profil eklenemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "SETTINGS", "Seçici profili eklendi: "+input.Name)
+	utils.Audit(c, ctrl.DB, "profile.create", "profile", input.ID, nil, input)
+	c.JSON(http.StatusOK, input)
+}
+
+// UpdateProfile: Mevcut bir seçici profilini günceller
+func (ctrl *SettingsController) UpdateProfile(c *gin.Context) {
+	id := c.Param("id")
+	var profile models.ExtractionProfile
+
+	if err := ctrl.DB.First(&profile, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil bulunamadı"})
+		return
+	}
+	before := profile
+
+	var input models.ExtractionProfile
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := scraper.ValidateProfile(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile.Name = input.Name
+	profile.SiteID = input.SiteID
+	profile.HostPattern = input.HostPattern
+	profile.ThreadList = input.ThreadList
+	profile.ThreadLink = input.ThreadLink
+	profile.PostContainer = input.PostContainer
+	profile.Author = input.Author
+	profile.Date = input.Date
+	profile.Content = input.Content
+	profile.NextPage = input.NextPage
+	profile.IsActive = input.IsActive
+
+	if err := ctrl.DB.Save(&profile).Error; err != nil {
+		utils.LogError(ctrl.DB, "SETTINGS", "Profil güncellenemedi: "+profile.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Profil güncellenemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "SETTINGS", "Seçici profili güncellendi: "+profile.Name)
+	utils.Audit(c, ctrl.DB, "profile.update", "profile", profile.ID, before, profile)
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile: Bir seçici profilini siler
+func (ctrl *SettingsController) DeleteProfile(c *gin.Context) {
+	id := c.Param("id")
+	var before models.ExtractionProfile
+	ctrl.DB.Find(&before, id)
+	if err := ctrl.DB.Delete(&models.ExtractionProfile{}, id).Error; err != nil {
+		utils.LogError(ctrl.DB, "SETTINGS", "Profil silinemedi ID: "+id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Profil silinemedi"})
+		return
+	}
+	utils.LogInfo(ctrl.DB, "SETTINGS", "Seçici profili silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "profile.delete", "profile", id, before, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Profil silindi"})
+}
+
+// TestProfile: Profili hedef URL üzerinde çalıştırır ve sonucu kaydetmeden döndürür (dry-run)
+func (ctrl *SettingsController) TestProfile(c *gin.Context) {
+	var req ProfileTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile := req.Profile
+	if profile == nil {
+		var stored models.ExtractionProfile
+		if err := ctrl.DB.First(&stored, req.ProfileID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profil bulunamadı"})
+			return
+		}
+		profile = &stored
+	}
+
+	// Taslak profilde site/host bağı henüz olmayabilir, sadece seçicileri kontrol et
+	if err := scraper.ValidateProfileRules(*profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.URL = utils.NormalizeTarget(req.URL, req.Route)
+	route, err := utils.ResolveRoute(req.URL, req.Route)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var keywords []models.Keyword
+	ctrl.DB.Find(&keywords)
+
+	opts := scraper.ScanOptions{
+		Engine:   utils.EngineOptions(),
+		Keywords: keywords,
+		Crawl:    req.Crawl,
+		Profile:  profile,
+	}
+	var result *scraper.ScrapeResult
+	err = utils.ApplyRoute(&opts, route, "profile-test")
+	if err == nil {
+		result, err = scraper.AnalyzeSite(c.Request.Context(), req.URL, opts)
+	}
+	if err != nil {
+		se := scraper.AsScanError(err)
+		c.JSON(se.HTTPStatus(), gin.H{"error": "Site Taranamadı: " + err.Error(), "code": se.Code, "retryable": se.Retryable})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profil denemesi tamamlandı. Veri kaydedilmedi.",
+		"data":    result,
+		"saved":   false,
+	})
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"scraper/models"
+	"scraper/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scheduleRetag: Keyword değişikliğinden sonra kayıtlı verilerin yeniden etiketlenmesini sıraya alır
+func (ctrl *SettingsController) scheduleRetag() {
+	if _, err := ctrl.Retagger.Enqueue(utils.RetagKeyword); err != nil {
+		utils.LogWarn(ctrl.DB, "TAGGER", "Yeniden etiketleme sıraya alınamadı: "+err.Error())
+	}
+}
+
+// StartRetag: Tüm konu ve iletileri güncel keyword'lerle yeniden etiketleyen işi başlatır.
+// Sırada bekleyen bir iş varsa yeni iş açılmaz, o iş döndürülür.
+func (ctrl *SettingsController) StartRetag(c *gin.Context) {
+	job, err := ctrl.Retagger.Enqueue(utils.RetagManual)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Yeniden etiketleme başlatılamadı"})
+		return
+	}
+	utils.Audit(c, ctrl.DB, "retag.start", "retag_job", job.ID, nil, nil)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetRetagJobs: Son yeniden etiketleme işlerini listeler
+func (ctrl *SettingsController) GetRetagJobs(c *gin.Context) {
+	var jobs []models.RetagJob
+	if err := ctrl.DB.Order("id desc").Limit(20).Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Yeniden etiketleme işleri getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetRetagJob: İşin ilerlemesini ve özetini döndürür
+func (ctrl *SettingsController) GetRetagJob(c *gin.Context) {
+	var job models.RetagJob
+	if err := ctrl.DB.First(&job, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Yeniden etiketleme işi bulunamadı"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetagJobEvents: İşin ilerlemesini Server-Sent Events olarak yayınlar; iş bittiğinde akış kapanır
+func (ctrl *SettingsController) RetagJobEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz iş numarası"})
+		return
+	}
+
+	// Durum okunmadan önce abone ol, aradaki olaylar kaçmasın
+	events, unsubscribe := utils.Events.Subscribe(utils.RetagTopic(uint(id)))
+	defer unsubscribe()
+
+	var job models.RetagJob
+	if err := ctrl.DB.First(&job, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Yeniden etiketleme işi bulunamadı"})
+		return
+	}
+
+	startStream(c)
+	c.SSEvent(utils.EventStatus, utils.Event{Type: utils.EventStatus, JobID: job.ID, Data: job, Time: time.Now()})
+	c.Writer.Flush()
+	if job.IsFinished() {
+		return
+	}
+
+	streamEvents(c, events, func(ev utils.Event) bool {
+		status, ok := ev.Data.(models.RetagJob)
+		return ev.Type == utils.EventStatus && ok && status.IsFinished()
+	})
+}
@@ -1,12 +1,11 @@
 package controllers
 
 import (
-	"fmt"
 	"net/http"
-	"os"
 	"scraper/models"
 	"scraper/scraper"
 	"scraper/utils"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,18 +13,22 @@ import (
 )
 
 type ScanRequest struct {
-	URL      string `json:"url" binding:"required"`
-	RandomUA bool   `json:"random_ua"`
+	URL      string               `json:"url" binding:"required"`
+	RandomUA bool                 `json:"random_ua"`
+	Crawl    scraper.CrawlOptions `json:"crawl"` // Çok sayfalı tarama sınırları
+	Route    string               `json:"route"` // Bağlantı yolu (boşsa host kuralları)
 }
 
 type ScanController struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Queue *utils.ScanQueue
 }
 
-func NewScanController(db *gorm.DB) *ScanController {
-	return &ScanController{DB: db}
+func NewScanController(db *gorm.DB, queue *utils.ScanQueue) *ScanController {
+	return &ScanController{DB: db, Queue: queue}
 }
 
+// ScanSite: Taramayı kuyruğa alır ve iş numarasını hemen döndürür
 func (sc *ScanController) ScanSite(c *gin.Context) {
 	var req ScanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,131 +36,112 @@ func (sc *ScanController) ScanSite(c *gin.Context) {
 		return
 	}
 
+	if err := utils.ValidateRouteName(req.Route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// URL Normalizasyonu
-	req.URL = scraper.NormalizeURL(req.URL)
-	startTime := time.Now()
-	utils.LogInfo(sc.DB, "SCANNER", fmt.Sprintf("Tarama başlatıldı: %s", req.URL))
-
-	// Tor Proxy Hazırlığı
-	torProxy := os.Getenv("TOR_PROXY")
-	if torProxy == "" {
-		activeProxy, err := scraper.GetActiveTorProxy()
+	req.URL = utils.NormalizeTarget(req.URL, req.Route)
+
+	job, err := sc.Queue.Enqueue(utils.ScanTask{
+		URL:      req.URL,
+		Source:   "manual",
+		RandomUA: req.RandomUA,
+		Crawl:    req.Crawl,
+		Route:    req.Route,
+	})
+	if err != nil {
+		utils.LogError(sc.DB, "SCANNER", "Tarama kuyruğa alınamadı: "+err.Error())
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	utils.Audit(c, sc.DB, "scan.start", "scan_job", job.ID, nil, req)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Tarama kuyruğa alındı",
+		"job_id":  job.ID,
+		"status":  job.Status,
+	})
+}
+
+// JobResponse: İş kaydı ve alınan sayfaların HTTP üst verisi
+type JobResponse struct {
+	models.ScanJob
+	Responses []models.ScanMetadata `json:"responses"` // Başarısız taramalarda da (403, CAPTCHA) doludur
+}
+
+// GetJob: Tarama işinin durumunu (ve bittiyse sonucunu ve HTTP üst verisini) döndürür
+func (sc *ScanController) GetJob(c *gin.Context) {
+	var job models.ScanJob
+	if err := sc.DB.First(&job, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarama işi bulunamadı"})
+		return
+	}
+
+	response := JobResponse{ScanJob: job, Responses: []models.ScanMetadata{}}
+	if job.IsFinished() {
+		responses, err := utils.LoadJobMetadata(sc.DB, job.ID)
 		if err != nil {
-			utils.LogError(sc.DB, "SCANNER", "Aktif Tor proxy bulunamadı.")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tarama üst verisi getirilemedi"})
 			return
 		}
-		torProxy = activeProxy
-	}
-	utils.LogInfo(sc.DB, "SCANNER", fmt.Sprintf("Proxy bağlantısı kuruldu: %s", torProxy))
-
-	// Keywordleri Getir
-	var keywords []models.Keyword
-	sc.DB.Find(&keywords)
-
-	var userAgents []string
-	if req.RandomUA {
-		var uaList []models.UserAgent
-		sc.DB.Find(&uaList)
-		for _, ua := range uaList {
-			userAgents = append(userAgents, ua.UserAgent)
-		}
+		response.Responses = responses
 	}
+	c.JSON(http.StatusOK, response)
+}
 
-	result, err := scraper.AnalyzeSite(req.URL, torProxy, keywords, userAgents)
+// CancelJob: Kuyruktaki veya çalışan taramayı iptal eder
+func (sc *ScanController) CancelJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		sc.handleScanError(c, err, torProxy)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz iş numarası"})
 		return
 	}
 
-	if result.ErrorMessage != "" {
-		utils.LogError(sc.DB, "SCANNER", fmt.Sprintf("Erişim hatası: %s", result.ErrorMessage))
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": "Siteye Erişilemedi (" + torProxy + "): " + result.ErrorMessage,
-		})
+	job, err := sc.Queue.Cancel(uint(id))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarama işi bulunamadı"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
 
-	sc.processScanResult(c, result, startTime)
+	utils.LogInfo(sc.DB, "SCANNER", "Tarama iptal istendi: "+job.URL)
+	utils.Audit(c, sc.DB, "scan.cancel", "scan_job", job.ID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "İptal isteği alındı", "job_id": job.ID})
 }
 
-// Hata Yanıtı (JSON)
-func (sc *ScanController) handleScanError(c *gin.Context, err error, proxy string) {
-	errStr := err.Error()
-	status := http.StatusInternalServerError
-	var userMsg string
-
-	if scraper.IsProxyConnectionError(err) {
-		userMsg = "Tor Ağına Bağlanılamadı. Lütfen Tor Browser'ın açık olduğundan emin olun."
-		utils.LogError(sc.DB, "SCANNER", "Tor ağına bağlanılamadı.")
-	} else {
-		status = http.StatusBadGateway
-		userMsg = "Site Taranamadı: " + errStr
-		utils.LogError(sc.DB, "SCANNER", fmt.Sprintf("Site tarama hatası: %s", errStr))
+// JobEvents: Tarama işinin ilerleyişini Server-Sent Events olarak yayınlar.
+// İlk mesaj işin güncel durumudur; iş bittiğinde akış kapanır.
+func (sc *ScanController) JobEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz iş numarası"})
+		return
 	}
 
-	c.JSON(status, gin.H{"error": userMsg, "details": errStr})
-}
+	// Durum okunmadan önce abone ol, aradaki olaylar kaçmasın
+	events, unsubscribe := utils.Events.Subscribe(utils.JobTopic(uint(id)))
+	defer unsubscribe()
 
-// Sonuç İşleme (JSON)
-func (sc *ScanController) processScanResult(c *gin.Context, result *scraper.ScrapeResult, startTime time.Time) {
-	if !result.IsForum {
-		duration := time.Since(startTime)
-		utils.LogWarn(sc.DB, "SCANNER", fmt.Sprintf("Hedef forum yapısına uymuyor: %s (Süre: %.2fs)", result.URL, duration.Seconds()))
-		c.JSON(http.StatusOK, gin.H{
-			"message":  "Site forum değil. Veri kaydedilmedi.",
-			"data":     result,
-			"saved":    false,
-			"duration": duration.Seconds(),
-		})
+	var job models.ScanJob
+	if err := sc.DB.First(&job, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarama işi bulunamadı"})
 		return
 	}
 
-	duration := time.Since(startTime)
-	sc.saveToDB(result)
-
-	utils.LogSuccess(sc.DB, "SCANNER", fmt.Sprintf("Tarama tamamlandı: %s (Süre: %.2fs)", result.URL, duration.Seconds()))
+	startStream(c)
+	c.SSEvent(utils.EventStatus, utils.Event{Type: utils.EventStatus, JobID: job.ID, Data: job, Time: time.Now()})
+	c.Writer.Flush()
+	if job.IsFinished() {
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Forum algılandı ve başarıyla kaydedildi",
-		"data":     result,
-		"saved":    true,
-		"duration": duration.Seconds(),
+	streamEvents(c, events, func(ev utils.Event) bool {
+		status, ok := ev.Data.(models.ScanJob)
+		return ev.Type == utils.EventStatus && ok && status.IsFinished()
 	})
 }
-
-// Veritabanına Kayıt
-func (sc *ScanController) saveToDB(result *scraper.ScrapeResult) {
-	site := models.Site{
-		URL:      result.URL,
-		LastScan: time.Now(),
-	}
-	sc.DB.Where(models.Site{URL: result.URL}).Assign(models.Site{
-		LastScan: time.Now(),
-	}).FirstOrCreate(&site)
-
-	stats := models.Stats{
-		SiteID:       site.ID,
-		Source:       "manual", // Manuel tarama
-		TotalThreads: result.ThreadCount,
-		TotalPosts:   result.PostCount,
-		ScanDate:     time.Now(),
-	}
-	sc.DB.Create(&stats)
-
-	go func(siteID, statsID uint, threads []scraper.ThreadData) {
-		for _, t := range threads {
-			thread := models.Thread{
-				SiteID: siteID, StatsID: statsID, Title: t.Title, Link: t.Link,
-				Author: t.Author, Date: t.Date, Category: t.Category,
-			}
-			sc.DB.Create(&thread)
-			for i, p := range t.Posts {
-				sc.DB.Create(&models.Post{
-					ThreadID: thread.ID, Author: p.Author, Content: p.Content,
-					Date: p.Date, Order: i + 1,
-				})
-			}
-		}
-	}(site.ID, stats.ID, result.Threads)
-}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"html"
+	"net/http"
+	"scraper/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Sayfalama sınırları
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Vurgulama için FTS5'e verilen işaretler; çıktı HTML'e kaçırıldıktan sonra <mark> ile değiştirilir
+const (
+	markOpen  = "\x02"
+	markClose = "\x03"
+)
+
+type SearchController struct {
+	DB *gorm.DB
+}
+
+func NewSearchController(db *gorm.DB) *SearchController {
+	return &SearchController{DB: db}
+}
+
+// SearchResult: Arama sonucundaki tek bir konu veya ileti
+type SearchResult struct {
+	Kind      string    `json:"kind"` // thread veya post
+	ID        uint      `json:"id"`   // Konu veya ileti ID
+	ThreadID  uint      `json:"thread_id"`
+	SiteID    uint      `json:"site_id"`
+	SiteURL   string    `json:"site_url"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	Category  string    `json:"category"`
+	Author    string    `json:"author"`
+	Date      string    `json:"date"`
+	FirstSeen time.Time `json:"first_seen"`
+	Highlight string    `json:"highlight"` // Eşleşen kısım, <mark> ile vurgulanmış (HTML güvenli)
+	Rank      float64   `json:"rank"`      // bm25, küçük olan daha alakalı
+
+	// SQLite ifadelerde kolon tipini kaybettiği için ilk görülme tarihleri ayrı okunur
+	PostFirstSeen   *time.Time `json:"-"`
+	ThreadFirstSeen *time.Time `json:"-"`
+}
+
+// Search: Konu başlıkları, ileti içerikleri ve yazar adlarında tam metin arama yapar.
+// q FTS5 sözdizimini destekler: "tam ifade", AND, OR, NOT, önek* ve author:isim gibi kolon süzgeçleri.
+// Süzgeçler: site_id, category, author, from/to (YYYY-MM-DD, ilk görülme tarihi), kind (thread/post).
+func (ctrl *SearchController) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arama sorgusu (q) gerekli"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSearchLimit)))
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	query := ctrl.DB.Table("search_index").
+		Joins("JOIN threads ON threads.id = search_index.thread_id").
+		Joins("LEFT JOIN posts ON search_index.kind = ? AND posts.id = search_index.ref_id", utils.SearchKindPost).
+		Joins("JOIN sites ON sites.id = threads.site_id").
+		Where("search_index MATCH ?", q)
+
+	if siteID := c.Query("site_id"); siteID != "" {
+		query = query.Where("threads.site_id = ?", siteID)
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("threads.category = ?", category)
+	}
+	if author := c.Query("author"); author != "" {
+		query = query.Where("search_index.author = ? COLLATE NOCASE", author)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		if kind != utils.SearchKindThread && kind != utils.SearchKindPost {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tür (thread veya post)"})
+			return
+		}
+		query = query.Where("search_index.kind = ?", kind)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz başlangıç tarihi (YYYY-MM-DD)"})
+			return
+		}
+		query = query.Where("COALESCE(posts.first_seen, threads.first_seen) >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz bitiş tarihi (YYYY-MM-DD)"})
+			return
+		}
+		query = query.Where("COALESCE(posts.first_seen, threads.first_seen) < ?", t.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz arama sorgusu: " + err.Error()})
+		return
+	}
+
+	results := []SearchResult{}
+	err := query.
+		Select(`search_index.kind AS kind, search_index.ref_id AS id, threads.id AS thread_id, threads.site_id AS site_id,
+			sites.url AS site_url, threads.title AS title, threads.link AS link, threads.category AS category,
+			search_index.author AS author, COALESCE(posts.date, threads.date) AS date,
+			posts.first_seen AS post_first_seen, threads.first_seen AS thread_first_seen,
+			CASE WHEN search_index.kind = ? THEN snippet(search_index, 1, ?, ?, '…', 24)
+				ELSE highlight(search_index, 0, ?, ?) END AS highlight,
+			bm25(search_index) AS rank`, utils.SearchKindPost, markOpen, markClose, markOpen, markClose).
+		Order("rank").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Scan(&results).Error
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz arama sorgusu: " + err.Error()})
+		return
+	}
+
+	for i := range results {
+		r := &results[i]
+		r.Highlight = markHighlight(r.Highlight)
+		if r.PostFirstSeen != nil {
+			r.FirstSeen = *r.PostFirstSeen
+		} else if r.ThreadFirstSeen != nil {
+			r.FirstSeen = *r.ThreadFirstSeen
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   q,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+		"results": results,
+	})
+}
+
+// markHighlight: Taranan içerik HTML olarak yorumlanmasın diye kaçırılır, sonra vurgu işaretleri <mark> olur
+func markHighlight(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, markOpen, "<mark>")
+	return strings.ReplaceAll(s, markClose, "</mark>")
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"scraper/config"
 	"scraper/models"
 	"scraper/scraper"
 	"scraper/utils"
@@ -13,11 +14,12 @@ import (
 )
 
 type SettingsController struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Retagger *utils.Retagger
 }
 
-func NewSettingsController(db *gorm.DB) *SettingsController {
-	return &SettingsController{DB: db}
+func NewSettingsController(db *gorm.DB, retagger *utils.Retagger) *SettingsController {
+	return &SettingsController{DB: db, Retagger: retagger}
 }
 
 // ResetOptions: Silme seçenekleri
@@ -60,7 +62,7 @@ func (ctrl *SettingsController) ResetDatabase(c *gin.Context) {
 	var successMsg string
 
 	if options.History {
-		historyTables := []string{"posts", "threads", "stats", "sites"}
+		historyTables := []string{"search_index", "alert_deliveries", "scan_metadata", "snapshots", "content_tags", "post_sightings", "thread_sightings", "posts", "threads", "stats", "sites"}
 		for _, table := range historyTables {
 			if err := tx.Exec("DELETE FROM " + table).Error; err != nil {
 				tx.Rollback()
@@ -69,6 +71,21 @@ func (ctrl *SettingsController) ResetDatabase(c *gin.Context) {
 				return
 			}
 		}
+		// Biten işler silinir; kuyruktaki/çalışan işlerde silinen Stats kaydına bağlantı kalmaz
+		finished := []string{models.JobSucceeded, models.JobFailed, models.JobCancelled}
+		if err := tx.Where("status IN ?", finished).Delete(&models.ScanJob{}).Error; err != nil {
+			tx.Rollback()
+			utils.LogError(ctrl.DB, "SYSTEM", "Veritabanı geçmiş temizleme hatası: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tablo temizlenemedi: scan_jobs"})
+			return
+		}
+		if err := tx.Model(&models.ScanJob{}).Where("stats_id IS NOT NULL").
+			Updates(map[string]interface{}{"stats_id": nil, "saved": false}).Error; err != nil {
+			tx.Rollback()
+			utils.LogError(ctrl.DB, "SYSTEM", "Veritabanı geçmiş temizleme hatası: "+err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tablo temizlenemedi: scan_jobs"})
+			return
+		}
 		successMsg += "Geçmiş, "
 	}
 
@@ -83,7 +100,7 @@ func (ctrl *SettingsController) ResetDatabase(c *gin.Context) {
 	}
 
 	if options.Settings {
-		settingsTables := []string{"keywords", "user_agents", "watchlists"}
+		settingsTables := []string{"keywords", "user_agents", "watchlists", "extraction_profiles", "alert_rules", "alert_channels", "runtime_settings"}
 		for _, table := range settingsTables {
 			if err := tx.Exec("DELETE FROM " + table).Error; err != nil {
 				tx.Rollback()
@@ -101,11 +118,24 @@ func (ctrl *SettingsController) ResetDatabase(c *gin.Context) {
 		return
 	}
 
+	if options.History {
+		// Arşiv kayıtları silindi; dosyaları da diskte saatlik temizliği beklemesin
+		if freed := utils.PruneOrphanSnapshots(ctrl.DB); freed > 0 {
+			utils.LogInfo(ctrl.DB, "SNAPSHOT", fmt.Sprintf("Geçmiş temizliğiyle %d arşiv dosyası silindi", freed))
+		}
+	}
+
+	if options.Settings {
+		// Silinen çalışma zamanı ayarlarının bellekteki karşılığı da dosya/ortam değerine döner
+		config.SetTunables(config.Base().Tunables())
+	}
+
 	if len(successMsg) > 2 {
 		successMsg = successMsg[:len(successMsg)-2] // Son virgülü kaldır
 	}
 
 	utils.LogSuccess(ctrl.DB, "SYSTEM", "Sistem veritabanı temizlendi: "+successMsg)
+	utils.Audit(c, ctrl.DB, "system.reset_db", "system", nil, nil, options)
 	c.JSON(http.StatusOK, gin.H{"message": "Veritabanı temizlendi: " + successMsg})
 }
 
@@ -119,6 +149,17 @@ func (ctrl *SettingsController) GetKeywords(c *gin.Context) {
 	c.JSON(http.StatusOK, keywords)
 }
 
+// normalizeKeyword: Boş mod ve ağırlığa varsayılan atar, regex sözdizimini doğrular
+func normalizeKeyword(kw *models.Keyword) error {
+	if kw.Mode == "" {
+		kw.Mode = models.MatchWord
+	}
+	if kw.Weight == 0 {
+		kw.Weight = 1
+	}
+	return scraper.ValidateKeyword(*kw)
+}
+
 // AddKeyword: Yeni bir anahtar kelime ekler
 func (ctrl *SettingsController) AddKeyword(c *gin.Context) {
 	var input models.Keyword
@@ -127,6 +168,11 @@ func (ctrl *SettingsController) AddKeyword(c *gin.Context) {
 		return
 	}
 
+	if err := normalizeKeyword(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := ctrl.DB.Create(&input).Error; err != nil {
 		utils.LogError(ctrl.DB, "SETTINGS", "Keyword eklenemedi: "+input.Word)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Keyword eklenemedi"})
@@ -134,6 +180,8 @@ func (ctrl *SettingsController) AddKeyword(c *gin.Context) {
 	}
 
 	utils.LogInfo(ctrl.DB, "SETTINGS", fmt.Sprintf("Keyword eklendi: %s (%s)", input.Word, input.Category))
+	utils.Audit(c, ctrl.DB, "keyword.create", "keyword", input.ID, nil, input)
+	ctrl.scheduleRetag()
 	c.JSON(http.StatusOK, input)
 }
 
@@ -146,6 +194,7 @@ func (ctrl *SettingsController) UpdateKeyword(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Keyword bulunamadı"})
 		return
 	}
+	before := keyword
 
 	var input models.Keyword
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -153,9 +202,18 @@ func (ctrl *SettingsController) UpdateKeyword(c *gin.Context) {
 		return
 	}
 
+	if err := normalizeKeyword(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	keyword.Word = input.Word
 	keyword.Category = input.Category
 	keyword.Color = input.Color // Renk güncellemesi
+	keyword.Mode = input.Mode
+	keyword.CaseSensitive = input.CaseSensitive
+	keyword.TurkishCase = input.TurkishCase
+	keyword.Weight = input.Weight
 
 	if err := ctrl.DB.Save(&keyword).Error; err != nil {
 		utils.LogError(ctrl.DB, "SETTINGS", "Keyword güncellenemedi: "+keyword.Word)
@@ -164,18 +222,24 @@ func (ctrl *SettingsController) UpdateKeyword(c *gin.Context) {
 	}
 
 	utils.LogInfo(ctrl.DB, "SETTINGS", "Keyword güncellendi: "+keyword.Word)
+	utils.Audit(c, ctrl.DB, "keyword.update", "keyword", keyword.ID, before, keyword)
+	ctrl.scheduleRetag()
 	c.JSON(http.StatusOK, keyword)
 }
 
 // DeleteKeyword: Bir anahtar kelimeyi siler
 func (ctrl *SettingsController) DeleteKeyword(c *gin.Context) {
 	id := c.Param("id")
+	var before models.Keyword
+	ctrl.DB.Find(&before, id)
 	if err := ctrl.DB.Delete(&models.Keyword{}, id).Error; err != nil {
 		utils.LogError(ctrl.DB, "SETTINGS", "Keyword silinemedi ID: "+id)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Keyword silinemedi"})
 		return
 	}
 	utils.LogInfo(ctrl.DB, "SETTINGS", "Keyword silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "keyword.delete", "keyword", id, before, nil)
+	ctrl.scheduleRetag()
 	c.JSON(http.StatusOK, gin.H{"message": "Keyword silindi"})
 }
 
@@ -204,6 +268,7 @@ func (ctrl *SettingsController) AddUserAgent(c *gin.Context) {
 	}
 
 	utils.LogInfo(ctrl.DB, "SETTINGS", "User Agent eklendi")
+	utils.Audit(c, ctrl.DB, "user_agent.create", "user_agent", input.ID, nil, input)
 	c.JSON(http.StatusOK, input)
 }
 
@@ -216,6 +281,7 @@ func (ctrl *SettingsController) UpdateUserAgent(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User Agent bulunamadı"})
 		return
 	}
+	before := userAgent
 
 	var input models.UserAgent
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -229,18 +295,22 @@ func (ctrl *SettingsController) UpdateUserAgent(c *gin.Context) {
 		return
 	}
 
+	utils.Audit(c, ctrl.DB, "user_agent.update", "user_agent", userAgent.ID, before, userAgent)
 	c.JSON(http.StatusOK, userAgent)
 }
 
 // DeleteUserAgent: Bir User Agent siler
 func (ctrl *SettingsController) DeleteUserAgent(c *gin.Context) {
 	id := c.Param("id")
+	var before models.UserAgent
+	ctrl.DB.Find(&before, id)
 	if err := ctrl.DB.Delete(&models.UserAgent{}, id).Error; err != nil {
 		utils.LogError(ctrl.DB, "SETTINGS", "User Agent silinemedi ID: "+id)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User Agent silinemedi"})
 		return
 	}
 	utils.LogInfo(ctrl.DB, "SETTINGS", "User Agent silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "user_agent.delete", "user_agent", id, before, nil)
 	c.JSON(http.StatusOK, gin.H{"message": "User Agent silindi"})
 }
 
@@ -262,8 +332,17 @@ func (ctrl *SettingsController) AddWatchlistItem(c *gin.Context) {
 		return
 	}
 
+	if err := utils.ValidateRouteName(input.Route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := utils.RetryPolicyFor(input.Retry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// URL'i normalize et
-	input.URL = scraper.NormalizeURL(input.URL)
+	input.URL = utils.NormalizeTarget(input.URL, input.Route)
 
 	// İlk ekleme için next_check zamanını hesapla
 	if input.IntervalMinutes > 0 {
@@ -278,6 +357,7 @@ func (ctrl *SettingsController) AddWatchlistItem(c *gin.Context) {
 	}
 
 	utils.LogInfo(ctrl.DB, "SETTINGS", "Watchlist eklendi: "+input.URL)
+	utils.Audit(c, ctrl.DB, "watchlist.create", "watchlist", input.ID, nil, input)
 	c.JSON(http.StatusOK, input)
 }
 
@@ -290,6 +370,7 @@ func (ctrl *SettingsController) UpdateWatchlistItem(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Watchlist öğesi bulunamadı"})
 		return
 	}
+	before := watchlistItem
 
 	var input models.Watchlist
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -297,10 +378,25 @@ func (ctrl *SettingsController) UpdateWatchlistItem(c *gin.Context) {
 		return
 	}
 
+	if err := utils.ValidateRouteName(input.Route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := utils.RetryPolicyFor(input.Retry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// URL'i normalize et
-	watchlistItem.URL = scraper.NormalizeURL(input.URL)
+	watchlistItem.URL = utils.NormalizeTarget(input.URL, input.Route)
+	watchlistItem.Route = input.Route
 	watchlistItem.IntervalMinutes = input.IntervalMinutes
 	watchlistItem.Description = input.Description
+	watchlistItem.CrawlEnabled = input.CrawlEnabled
+	watchlistItem.CrawlMaxDepth = input.CrawlMaxDepth
+	watchlistItem.CrawlMaxPages = input.CrawlMaxPages
+	watchlistItem.CrawlExternal = input.CrawlExternal
+	watchlistItem.Retry = input.Retry
 
 	// Interval değiştirilmişse next_check'i yeniden hesapla
 	if input.IntervalMinutes > 0 {
@@ -313,18 +409,22 @@ func (ctrl *SettingsController) UpdateWatchlistItem(c *gin.Context) {
 		return
 	}
 
+	utils.Audit(c, ctrl.DB, "watchlist.update", "watchlist", watchlistItem.ID, before, watchlistItem)
 	c.JSON(http.StatusOK, watchlistItem)
 }
 
 // DeleteWatchlistItem: Bir watchlist öğesini siler
 func (ctrl *SettingsController) DeleteWatchlistItem(c *gin.Context) {
 	id := c.Param("id")
+	var before models.Watchlist
+	ctrl.DB.Find(&before, id)
 	if err := ctrl.DB.Delete(&models.Watchlist{}, id).Error; err != nil {
 		utils.LogError(ctrl.DB, "SETTINGS", "Watchlist silinemedi ID: "+id)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Watchlist öğesi silinemedi"})
 		return
 	}
 	utils.LogInfo(ctrl.DB, "SETTINGS", "Watchlist silindi ID: "+id)
+	utils.Audit(c, ctrl.DB, "watchlist.delete", "watchlist", id, before, nil)
 	c.JSON(http.StatusOK, gin.H{"message": "Watchlist öğesi silindi"})
 }
 
@@ -351,5 +451,6 @@ func (ctrl *SettingsController) ToggleAllWatchlist(c *gin.Context) {
 		state = "Pasif"
 	}
 	utils.LogInfo(ctrl.DB, "SETTINGS", fmt.Sprintf("Tüm Watchlist durumu değiştirildi: %s", state))
+	utils.Audit(c, ctrl.DB, "watchlist.toggle_all", "watchlist", nil, nil, input)
 	c.JSON(http.StatusOK, gin.H{"message": "Tüm watchlist öğeleri güncellendi"})
 }
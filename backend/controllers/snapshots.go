@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"net/http"
+	"scraper/models"
+	"scraper/scraper"
+	"scraper/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Arşiv listesinin sayfa boyutu
+const (
+	defaultSnapshotLimit = 100
+	maxSnapshotLimit     = 500
+)
+
+type SnapshotController struct {
+	DB *gorm.DB
+}
+
+func NewSnapshotController(db *gorm.DB) *SnapshotController {
+	return &SnapshotController{DB: db}
+}
+
+// ReparseRequest: Arşivlenmiş bir taramayı ağa çıkmadan yeniden ayrıştırma isteği
+type ReparseRequest struct {
+	JobID     uint                      `json:"job_id"`     // Tarama işi
+	StatsID   uint                      `json:"stats_id"`   // veya kaydedilmiş tarama
+	ProfileID uint                      `json:"profile_id"` // Kayıtlı profil
+	Profile   *models.ExtractionProfile `json:"profile"`    // veya henüz kaydedilmemiş taslak
+}
+
+// GetSnapshots: Arşiv kayıtlarını yeniden eskiye listeler. Süzgeçler: job_id, stats_id, hash, url.
+// usage: arşivin toplam kayıt, dosya ve disk boyutu
+func (ctrl *SnapshotController) GetSnapshots(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSnapshotLimit)))
+	if limit < 1 {
+		limit = defaultSnapshotLimit
+	}
+	if limit > maxSnapshotLimit {
+		limit = maxSnapshotLimit
+	}
+
+	query := ctrl.DB.Model(&models.Snapshot{})
+	for _, field := range []string{"job_id", "stats_id", "hash", "url"} {
+		if value := c.Query(field); value != "" {
+			query = query.Where(field+" = ?", value)
+		}
+	}
+
+	snapshots := []models.Snapshot{}
+	if err := query.Order("id desc").Limit(limit).Find(&snapshots).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Arşiv kayıtları getirilemedi"})
+		return
+	}
+
+	count, blobs, size := utils.SnapshotUsage(ctrl.DB)
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   utils.SnapshotsEnabled(),
+		"snapshots": snapshots,
+		"usage":     gin.H{"snapshots": count, "blobs": blobs, "bytes": size},
+	})
+}
+
+// DownloadSnapshot: Sayfanın ham gövdesini dosya olarak indirir.
+// Tarayıcıda çalıştırılmaması için her zaman ek (attachment) olarak ve octet-stream türüyle gönderilir.
+func (ctrl *SnapshotController) DownloadSnapshot(c *gin.Context) {
+	var snap models.Snapshot
+	if err := ctrl.DB.First(&snap, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Arşiv kaydı bulunamadı"})
+		return
+	}
+
+	body, err := utils.ReadSnapshot(snap)
+	if err != nil {
+		utils.LogError(ctrl.DB, "SNAPSHOT", "Arşiv kaydı okunamadı #"+strconv.Itoa(int(snap.ID))+": "+err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+snap.Hash+`.html"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Snapshot-SHA256", snap.Hash)
+	c.Header("X-Snapshot-URL", snap.FinalURL)
+	c.Data(http.StatusOK, "application/octet-stream", body)
+}
+
+// ReparseSnapshots: İşin arşivlenmiş sayfalarını güncel ayrıştırıcılarla (ve istenirse profil ile) yeniden ayrıştırır.
+// Ağa çıkılmaz, sonuç kaydedilmez. Crawl taramalarında sadece arşivde bulunan sayfalar izlenir.
+func (ctrl *SnapshotController) ReparseSnapshots(c *gin.Context) {
+	var req ReparseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.JobID == 0 && req.StatsID != 0 {
+		var snap models.Snapshot
+		if err := ctrl.DB.Where("stats_id = ?", req.StatsID).First(&snap).Error; err == nil {
+			req.JobID = snap.JobID
+		}
+	}
+	if req.JobID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arşivlenmiş tarama bulunamadı (job_id veya stats_id gerekli)"})
+		return
+	}
+
+	profile := req.Profile
+	if profile == nil && req.ProfileID != 0 {
+		var stored models.ExtractionProfile
+		if err := ctrl.DB.First(&stored, req.ProfileID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profil bulunamadı"})
+			return
+		}
+		profile = &stored
+	}
+	if profile != nil {
+		if err := scraper.ValidateProfileRules(*profile); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	pages, snaps, err := utils.SnapshotPages(ctrl.DB, req.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(pages) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bu işe ait arşivlenmiş sayfa yok"})
+		return
+	}
+
+	// Kayıtlı profil yoksa taramadaki gibi host'a uyan profil kullanılır
+	if profile == nil {
+		profile = utils.FindExtractionProfile(ctrl.DB, snaps[0].URL)
+	}
+
+	var keywords []models.Keyword
+	ctrl.DB.Find(&keywords)
+
+	engine := utils.EngineOptions()
+	engine.Retry = scraper.RetryPolicy{MaxAttempts: 1}
+	opts := scraper.ScanOptions{
+		Engine:    engine,
+		Keywords:  keywords,
+		Profile:   profile,
+		Crawl:     offlineCrawl(snaps),
+		Transport: scraper.NewOfflineTransport(pages),
+	}
+	result, err := scraper.AnalyzeSite(c.Request.Context(), snaps[0].URL, opts)
+	if err != nil {
+		se := scraper.AsScanError(err)
+		c.JSON(se.HTTPStatus(), gin.H{"error": "Arşiv ayrıştırılamadı: " + err.Error(), "code": se.Code})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Arşiv yeniden ayrıştırıldı. Veri kaydedilmedi.",
+		"job_id":  req.JobID,
+		"pages":   len(pages),
+		"data":    result,
+		"saved":   false,
+	})
+}
+
+// PruneSnapshots: Saklama politikasını beklemeden uygular
+func (ctrl *SnapshotController) PruneSnapshots(c *gin.Context) {
+	if !utils.SnapshotsEnabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sayfa arşivi kapalı"})
+		return
+	}
+	before, _, beforeSize := utils.SnapshotUsage(ctrl.DB)
+	utils.PruneSnapshots(ctrl.DB)
+	after, blobs, size := utils.SnapshotUsage(ctrl.DB)
+
+	utils.Audit(c, ctrl.DB, "snapshot.prune", "snapshot", "", gin.H{"snapshots": before, "bytes": beforeSize}, gin.H{"snapshots": after, "bytes": size})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sayfa arşivi temizlendi",
+		"removed": before - after,
+		"usage":   gin.H{"snapshots": after, "blobs": blobs, "bytes": size},
+	})
+}
+
+// offlineCrawl: Arşivdeki sayfa sayısından crawl ayarını çıkarır. Arşivde olmayan bağlantılar 404 döner.
+func offlineCrawl(snaps []models.Snapshot) scraper.CrawlOptions {
+	if len(snaps) < 2 {
+		return scraper.CrawlOptions{}
+	}
+	depth := 1
+	for _, s := range snaps {
+		if s.Depth > depth {
+			depth = s.Depth
+		}
+	}
+	return scraper.CrawlOptions{Enabled: true, MaxDepth: depth - 1, MaxPages: scraper.MaxCrawlPages, AllowExternal: true}
+}
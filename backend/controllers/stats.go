@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"runtime"
 	"scraper/models"
-	"scraper/scraper"
 	"scraper/utils"
 	"time"
 
@@ -43,7 +43,7 @@ func (ctrl *StatsController) GetGeneralStats(c *gin.Context) {
 	}
 	var recentScans []RecentScan
 	ctrl.DB.Table("stats").
-		Select("stats.id, sites.url, stats.source, stats.scan_date, (SELECT category FROM threads WHERE threads.stats_id = stats.id LIMIT 1) as category").
+		Select("stats.id, sites.url, stats.source, stats.scan_date, (SELECT threads.category FROM thread_sightings JOIN threads ON threads.id = thread_sightings.thread_id WHERE thread_sightings.stats_id = stats.id ORDER BY thread_sightings.id LIMIT 1) as category").
 		Joins("left join sites on sites.id = stats.site_id").
 		Order("stats.scan_date desc").
 		Limit(7).
@@ -140,10 +140,20 @@ func (ctrl *StatsController) GetGeneralStats(c *gin.Context) {
 	// Goroutine sayısını göster
 	goroutines := runtime.NumGoroutine()
 
-	// Tor Bağlantısı Kontrolü
+	// Tor Havuzu Durumu (sağlık kontrolleri arka planda yapılır)
 	torStatus := "PASİF"
-	if _, err := scraper.GetActiveTorProxy(); err == nil {
+	torProxies := utils.TorPoolStats()
+	var torLatency float64
+	healthyProxies := 0
+	for _, p := range torProxies {
+		if p.Healthy {
+			healthyProxies++
+			torLatency += p.AvgLatencyMs
+		}
+	}
+	if healthyProxies > 0 {
 		torStatus = "AKTİF"
+		torLatency /= float64(healthyProxies)
 	}
 
 	// --- Genel Toplamlar ---
@@ -157,6 +167,9 @@ func (ctrl *StatsController) GetGeneralStats(c *gin.Context) {
 	var totals TotalStats
 	ctrl.DB.Model(&models.Stats{}).Select("COALESCE(SUM(total_threads), 0) as total_threads, COALESCE(SUM(total_posts), 0) as total_posts").Scan(&totals)
 
+	// --- Giriş Güvenliği ---
+	activeLockouts, recentLockouts := utils.LockoutStats(ctrl.DB)
+
 	c.JSON(http.StatusOK, gin.H{
 		"site_count":   siteCount,
 		"page_count":   pageCount,           // İndeksli İçerik
@@ -170,24 +183,49 @@ func (ctrl *StatsController) GetGeneralStats(c *gin.Context) {
 			"sites":  siteCount - forumCount,
 		},
 		"system_status": gin.H{
-			"cpu":        goroutines,
-			"memory":     memUsage,
-			"network":    "ONLINE",
-			"uptime":     uptimeStr,
-			"tor_status": torStatus,
+			"cpu":         goroutines,
+			"memory":      memUsage,
+			"network":     "ONLINE",
+			"uptime":      uptimeStr,
+			"tor_status":  torStatus,
+			"tor_latency": math.Round(torLatency), // Sağlıklı uç noktaların ortalama gecikmesi (ms)
+		},
+		"tor": gin.H{
+			"healthy": healthyProxies,
+			"total":   len(torProxies),
+			"proxies": torProxies,
+		},
+		"security": gin.H{
+			"active_lockouts": activeLockouts, // Şu an kilitli IP/hesap
+			"lockouts_24h":    recentLockouts, // Son 24 saatte kilitlenen IP/hesap
 		},
 	})
 }
 
 func (ctrl *StatsController) GetSystemLogs(c *gin.Context) {
 	var logs []models.SystemLog
-	if err := ctrl.DB.Order("created_at desc").Limit(1000).Find(&logs).Error; err != nil {
+	query := ctrl.DB.Order("created_at desc").Limit(1000)
+	if code := c.Query("code"); code != "" {
+		// Tarama hata koduna göre filtre (örn. ?code=timeout)
+		query = query.Where("code = ?", code)
+	}
+	if err := query.Find(&logs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch logs"})
 		return
 	}
 	c.JSON(http.StatusOK, logs)
 }
 
+// StreamLogs: Yeni sistem loglarını oluştukları anda Server-Sent Events olarak yayınlar
+func (ctrl *StatsController) StreamLogs(c *gin.Context) {
+	events, unsubscribe := utils.Events.Subscribe(utils.LogTopic)
+	defer unsubscribe()
+
+	startStream(c)
+	c.Writer.Flush()
+	streamEvents(c, events, nil)
+}
+
 func (ctrl *StatsController) GetLogStats(c *gin.Context) {
 	var total, info, warn, errCount, success int64
 
@@ -198,11 +236,23 @@ func (ctrl *StatsController) GetLogStats(c *gin.Context) {
 	ctrl.DB.Model(&models.SystemLog{}).Where("level = ?", "ERROR").Count(&errCount)
 	ctrl.DB.Model(&models.SystemLog{}).Where("level = ?", "SUCCESS").Count(&success)
 
+	// Tarama hatalarının koda göre dağılımı
+	var codeRows []struct {
+		Code  string
+		Count int64
+	}
+	ctrl.DB.Model(&models.SystemLog{}).Select("code, COUNT(*) as count").Where("code <> ''").Group("code").Scan(&codeRows)
+	byCode := make(map[string]int64, len(codeRows))
+	for _, r := range codeRows {
+		byCode[r.Code] = r.Count
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"total":   total,
 		"info":    info,
 		"warning": warn,
 		"error":   errCount,
 		"success": success,
+		"by_code": byCode,
 	})
 }
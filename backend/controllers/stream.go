@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"scraper/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ara sunucuların boşta bağlantıyı kapatmaması için gönderilen yorum satırı aralığı
+const streamKeepAlive = 15 * time.Second
+
+// startStream: Server-Sent Events başlıklarını yazar
+func startStream(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+}
+
+// streamEvents: Kanaldaki olayları istemci bağlantıyı kapatana kadar yazar.
+// done tanımlıysa ve true dönerse son olay yazıldıktan sonra akış kapanır.
+func streamEvents(c *gin.Context, events <-chan utils.Event, done func(utils.Event) bool) {
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case ev := <-events:
+			c.SSEvent(ev.Type, ev)
+			return done == nil || !done(ev)
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+			return true
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/http"
+	"scraper/config"
+	"scraper/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SystemController struct {
+	DB *gorm.DB
+}
+
+func NewSystemController(db *gorm.DB) *SystemController {
+	return &SystemController{DB: db}
+}
+
+// GetConfig: Güncel ayarları gizli değerler maskelenmiş olarak döndürür.
+// runtime: çalışırken değiştirilebilen değerler, base: dosya/ortam değerleri, overrides: veritabanında değiştirilenler.
+func (ctrl *SystemController) GetConfig(c *gin.Context) {
+	cfg := config.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"config":    cfg.Redacted(),
+		"runtime":   cfg.Tunables(),
+		"base":      config.Base().Tunables(),
+		"overrides": utils.RuntimeOverrides(ctrl.DB),
+	})
+}
+
+// UpdateRuntimeConfig: Tarama motoru ve zamanlayıcı ayarlarını değiştirir.
+// Sadece gönderilen alanlar değişir; süreler "15s", "1m" biçimindedir.
+func (ctrl *SystemController) UpdateRuntimeConfig(c *gin.Context) {
+	before := config.Get().Tunables()
+	tunables := before
+	if err := c.ShouldBindJSON(&tunables); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+	if err := tunables.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := utils.SaveTunables(ctrl.DB, tunables, c.GetString(utils.ContextUsername)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ayarlar kaydedilemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "CONFIG", "Çalışma zamanı ayarları güncellendi")
+	utils.Audit(c, ctrl.DB, "system.config_update", "config", nil, before, tunables)
+	c.JSON(http.StatusOK, gin.H{"runtime": tunables, "overrides": utils.RuntimeOverrides(ctrl.DB)})
+}
+
+// ResetRuntimeConfig: Veritabanındaki değişiklikleri silip dosya/ortam değerlerine döner
+func (ctrl *SystemController) ResetRuntimeConfig(c *gin.Context) {
+	before := config.Get().Tunables()
+	if err := utils.ResetTunables(ctrl.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ayarlar sıfırlanamadı"})
+		return
+	}
+
+	after := config.Get().Tunables()
+	utils.LogInfo(ctrl.DB, "CONFIG", "Çalışma zamanı ayarları dosya/ortam değerlerine döndürüldü")
+	utils.Audit(c, ctrl.DB, "system.config_reset", "config", nil, before, after)
+	c.JSON(http.StatusOK, gin.H{"runtime": after, "overrides": utils.RuntimeOverrides(ctrl.DB)})
+}
+
+// GetTorStatus: Tor havuzundaki uç noktaların sağlık, gecikme ve hata oranlarını döndürür
+func (ctrl *SystemController) GetTorStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.TorPoolStats())
+}
+
+// GetRoutes: Watchlist ve tarama formlarında seçilebilecek bağlantı yollarını ve host kurallarını döndürür
+func (ctrl *SystemController) GetRoutes(c *gin.Context) {
+	routing := config.Get().Routing
+	c.JSON(http.StatusOK, gin.H{
+		"default":        routing.Default,
+		"routes":         routing.RouteNames(),
+		"rules":          routing.Rules,
+		"complete_onion": routing.CompleteOnion,
+	})
+}
+
+// NewTorCircuit: Tor'a NEWNYM göndererek yeni devre açtırır. proxy verilmezse tüm uç noktalara gönderilir.
+func (ctrl *SystemController) NewTorCircuit(c *gin.Context) {
+	var req struct {
+		Proxy string `json:"proxy"`
+	}
+	c.ShouldBindJSON(&req)
+
+	if err := utils.NewTorCircuit(req.Proxy); err != nil {
+		utils.LogWarn(ctrl.DB, "TOR", "NEWNYM gönderilemedi: "+err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "TOR", "Yeni Tor devresi istendi")
+	utils.Audit(c, ctrl.DB, "tor.newnym", "tor", nil, nil, req)
+	c.JSON(http.StatusOK, gin.H{"message": "Yeni devre istendi"})
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"net/http"
+	"scraper/models"
+	"scraper/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type TwoFactorLoginRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+	Code         string `json:"code"`          // Doğrulayıcı uygulamadaki 6 haneli kod
+	RecoveryCode string `json:"recovery_code"` // Veya tek kullanımlık kurtarma kodu
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// verifyTOTP: Kodu doğrular ve zaman adımını kaydeder; aynı kod ikinci kez kabul edilmez
+func (ac *AuthController) verifyTOTP(user models.User, code string) bool {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false
+	}
+	res := ac.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// LoginTwoFactor: İki adımlı girişin ikinci adımı. Kod doğrulanınca normal giriş yanıtı döner.
+func (ac *AuthController) LoginTwoFactor(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doğrulama kodu veya kurtarma kodu gerekli."})
+		return
+	}
+
+	preAuth, err := utils.ParsePreAuthToken(ac.DB, req.PreAuthToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "pre_auth_expired": true})
+		return
+	}
+	var user models.User
+	if err := ac.DB.First(&user, preAuth.UserID).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": utils.ErrInvalidPreAuthToken.Error(), "pre_auth_expired": true})
+		return
+	}
+
+	// Kod denemeleri de şifre denemeleriyle aynı sayaçlara işlenir
+	if loginBlocked(c, ac.DB, user.Username) {
+		return
+	}
+
+	setActor(c, user)
+	usedRecovery := req.Code == ""
+	var verified bool
+	if usedRecovery {
+		verified = utils.UseRecoveryCode(ac.DB, user.ID, req.RecoveryCode)
+	} else {
+		verified = ac.verifyTOTP(user, req.Code)
+	}
+	if !verified {
+		utils.LogWarn(ac.DB, "AUTH", "Hatalı doğrulama kodu: "+user.Username)
+		utils.RecordLoginFailure(ac.DB, c.ClientIP(), user.Username)
+		utils.Audit(c, ac.DB, "auth.2fa_failed", "user", user.ID, nil, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Hatalı doğrulama kodu."})
+		return
+	}
+
+	if err := utils.ConsumePreAuth(ac.DB, preAuth); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "pre_auth_expired": true})
+		return
+	}
+	if usedRecovery {
+		utils.LogWarn(ac.DB, "AUTH", "Kurtarma koduyla giriş yapıldı: "+user.Username)
+		utils.Audit(c, ac.DB, "auth.recovery_code_used", "user", user.ID, nil, nil)
+	}
+	ac.completeLogin(c, user)
+}
+
+// GetTwoFactorStatus: İki adımlı doğrulamanın durumu ve kalan kurtarma kodu sayısı
+func (ac *AuthController) GetTwoFactorStatus(c *gin.Context) {
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	var remaining int64
+	ac.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+	c.JSON(http.StatusOK, gin.H{"enabled": user.TOTPEnabled, "recovery_codes_remaining": remaining})
+}
+
+// EnrollTwoFactor: Yeni TOTP anahtarı üretir. Anahtar, ilk kod doğrulanana kadar etkin olmaz.
+func (ac *AuthController) EnrollTwoFactor(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "İki adımlı doğrulama zaten açık"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Anahtar üretilemedi"})
+		return
+	}
+	if err := ac.DB.Model(&user).Update("totp_pending_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Anahtar kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPURI(user.Username, secret),
+	})
+}
+
+// ConfirmTwoFactor: Kurulumdaki anahtarı ilk kodla doğrulayıp etkinleştirir ve kurtarma kodlarını döndürür
+func (ac *AuthController) ConfirmTwoFactor(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doğrulama kodu gerekli"})
+		return
+	}
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	if user.TOTPPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Önce kurulumu başlatın"})
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hatalı doğrulama kodu"})
+		return
+	}
+
+	err := ac.DB.Model(&user).Updates(map[string]interface{}{
+		"totp_enabled":        true,
+		"totp_secret":         user.TOTPPendingSecret,
+		"totp_pending_secret": "",
+		"totp_last_step":      step,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İki adımlı doğrulama açılamadı"})
+		return
+	}
+	codes, err := utils.GenerateRecoveryCodes(ac.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kurtarma kodları üretilemedi"})
+		return
+	}
+
+	utils.LogSuccess(ac.DB, "AUTH", "İki adımlı doğrulama açıldı: "+user.Username)
+	utils.Audit(c, ac.DB, "auth.2fa_enable", "user", user.ID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "İki adımlı doğrulama açıldı", "recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes: Eski kurtarma kodlarını geçersiz kılıp yenilerini üretir
+func (ac *AuthController) RegenerateRecoveryCodes(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doğrulama kodu gerekli"})
+		return
+	}
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "İki adımlı doğrulama kapalı"})
+		return
+	}
+	if !ac.verifyTOTP(user, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Hatalı doğrulama kodu"})
+		return
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(ac.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kurtarma kodları üretilemedi"})
+		return
+	}
+	utils.LogInfo(ac.DB, "AUTH", "Kurtarma kodları yenilendi: "+user.Username)
+	utils.Audit(c, ac.DB, "auth.recovery_codes_regenerate", "user", user.ID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableTwoFactor: Şifre ve geçerli kod ile iki adımlı doğrulamayı kapatır
+func (ac *AuthController) DisableTwoFactor(c *gin.Context) {
+	if !requireSession(c) {
+		return
+	}
+	var req TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Şifre ve doğrulama kodu gerekli"})
+		return
+	}
+	var user models.User
+	if err := ac.DB.First(&user, c.GetUint(utils.ContextUserID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "İki adımlı doğrulama kapalı"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Şifre hatalı"})
+		return
+	}
+	if !ac.verifyTOTP(user, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Hatalı doğrulama kodu"})
+		return
+	}
+
+	if err := utils.DisableTwoFactor(ac.DB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İki adımlı doğrulama kapatılamadı"})
+		return
+	}
+	utils.LogWarn(ac.DB, "AUTH", "İki adımlı doğrulama kapatıldı: "+user.Username)
+	utils.Audit(c, ac.DB, "auth.2fa_disable", "user", user.ID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "İki adımlı doğrulama kapatıldı"})
+}
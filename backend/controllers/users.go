@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"scraper/models"
+	"scraper/utils"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Şifre için en az karakter sayısı
+const minPasswordLength = 8
+
+type UserController struct {
+	DB *gorm.DB
+}
+
+func NewUserController(db *gorm.DB) *UserController {
+	return &UserController{DB: db}
+}
+
+// UserInput: Kullanıcı ekleme/güncelleme isteği. Güncellemede boş şifre mevcut şifreyi korur.
+type UserInput struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// validatePassword: Şifre uzunluğunu kontrol eder
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return fmt.Errorf("Şifre en az %d karakter olmalı", minPasswordLength)
+	}
+	return nil
+}
+
+// lastAdmin: Kullanıcı sistemdeki tek admin mi
+func (ctrl *UserController) lastAdmin(user models.User) bool {
+	if user.Role != models.RoleAdmin {
+		return false
+	}
+	var count int64
+	ctrl.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count)
+	return count <= 1
+}
+
+// GetUsers: Tüm kullanıcıları listeler
+func (ctrl *UserController) GetUsers(c *gin.Context) {
+	var users []models.User
+	if err := ctrl.DB.Order("id").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcılar getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// AddUser: Yeni kullanıcı ekler. Yönetici tarafından belirlenen şifre ilk girişte değiştirilmelidir.
+func (ctrl *UserController) AddUser(c *gin.Context) {
+	var input UserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kullanıcı adı gerekli"})
+		return
+	}
+	if input.Role == "" {
+		input.Role = models.RoleViewer
+	}
+	if !models.ValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rol (admin, analyst, viewer)"})
+		return
+	}
+	if err := validatePassword(input.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing int64
+	ctrl.DB.Model(&models.User{}).Where("username = ?", input.Username).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bu kullanıcı adı zaten kullanılıyor"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı eklenemedi"})
+		return
+	}
+
+	user := models.User{
+		Username:           input.Username,
+		Password:           string(hash),
+		Role:               input.Role,
+		MustChangePassword: true,
+	}
+	if err := ctrl.DB.Create(&user).Error; err != nil {
+		utils.LogError(ctrl.DB, "USERS", "Kullanıcı eklenemedi: "+input.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı eklenemedi"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "USERS", fmt.Sprintf("Kullanıcı eklendi: %s (%s)", user.Username, user.Role))
+	utils.Audit(c, ctrl.DB, "user.create", "user", user.ID, nil, user)
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUser: Kullanıcının adını, rolünü veya şifresini günceller.
+// Şifre sıfırlanırsa kullanıcı bir sonraki girişte değiştirmek zorundadır.
+func (ctrl *UserController) UpdateUser(c *gin.Context) {
+	var user models.User
+	if err := ctrl.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+	before := user
+
+	var input UserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Username != "" && input.Username != user.Username {
+		var existing int64
+		ctrl.DB.Model(&models.User{}).Where("username = ? AND id <> ?", input.Username, user.ID).Count(&existing)
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu kullanıcı adı zaten kullanılıyor"})
+			return
+		}
+		user.Username = input.Username
+	}
+
+	if input.Role != "" && input.Role != user.Role {
+		if !models.ValidRole(input.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rol (admin, analyst, viewer)"})
+			return
+		}
+		if ctrl.lastAdmin(user) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Son admin kullanıcısının rolü değiştirilemez"})
+			return
+		}
+		user.Role = input.Role
+	}
+
+	if input.Password != "" {
+		if err := validatePassword(input.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı güncellenemedi"})
+			return
+		}
+		user.Password = string(hash)
+		user.MustChangePassword = true
+	}
+
+	if err := ctrl.DB.Save(&user).Error; err != nil {
+		utils.LogError(ctrl.DB, "USERS", "Kullanıcı güncellenemedi: "+user.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı güncellenemedi"})
+		return
+	}
+
+	// Token'lar ad ve rol bilgisini taşıdığı için açık oturumlar kapatılır
+	if user.Username != before.Username || user.Role != before.Role || input.Password != "" {
+		ctrl.revokeSessions(user)
+	}
+
+	utils.LogInfo(ctrl.DB, "USERS", fmt.Sprintf("Kullanıcı güncellendi: %s (%s)", user.Username, user.Role))
+	utils.Audit(c, ctrl.DB, "user.update", "user", user.ID, before, user)
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser: Kullanıcıyı siler. Kişi kendini veya son admini silemez.
+func (ctrl *UserController) DeleteUser(c *gin.Context) {
+	var user models.User
+	if err := ctrl.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+
+	if user.ID == c.GetUint(utils.ContextUserID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kendi hesabınızı silemezsiniz"})
+		return
+	}
+	if ctrl.lastAdmin(user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Son admin kullanıcısı silinemez"})
+		return
+	}
+
+	// Kullanıcı adı tekrar kullanılabilsin diye kalıcı silinir
+	if err := ctrl.DB.Unscoped().Delete(&user).Error; err != nil {
+		utils.LogError(ctrl.DB, "USERS", "Kullanıcı silinemedi: "+user.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı silinemedi"})
+		return
+	}
+
+	ctrl.revokeSessions(user)
+	utils.LogInfo(ctrl.DB, "USERS", "Kullanıcı silindi: "+user.Username)
+	utils.Audit(c, ctrl.DB, "user.delete", "user", user.ID, user, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Kullanıcı silindi"})
+}
+
+// revokeSessions: Kullanıcının tüm oturumlarını kapatır
+func (ctrl *UserController) revokeSessions(user models.User) {
+	if _, err := utils.RevokeUserSessions(ctrl.DB, user.ID); err != nil {
+		utils.LogError(ctrl.DB, "USERS", "Oturumlar kapatılamadı: "+user.Username)
+	}
+}
+
+// RevokeUserSessions: Kullanıcının açık tüm oturumlarını sonlandırır
+func (ctrl *UserController) RevokeUserSessions(c *gin.Context) {
+	var user models.User
+	if err := ctrl.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+
+	count, err := utils.RevokeUserSessions(ctrl.DB, user.ID)
+	if err != nil {
+		utils.LogError(ctrl.DB, "USERS", "Oturumlar kapatılamadı: "+user.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Oturumlar kapatılamadı"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "USERS", fmt.Sprintf("Kullanıcının oturumları kapatıldı: %s (%d oturum)", user.Username, count))
+	utils.Audit(c, ctrl.DB, "user.revoke_sessions", "user", user.ID, nil, gin.H{"revoked": count})
+	c.JSON(http.StatusOK, gin.H{"message": "Oturumlar kapatıldı", "revoked": count})
+}
+
+// UnlockUser: Hatalı denemeler nedeniyle kilitlenen hesabın kilidini kaldırır
+func (ctrl *UserController) UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := ctrl.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+
+	removed, err := utils.UnlockLogin(ctrl.DB, models.ThrottleUser, user.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hesap kilidi kaldırılamadı"})
+		return
+	}
+	if removed {
+		utils.LogInfo(ctrl.DB, "AUTH", "Hesap kilidi kaldırıldı: "+user.Username)
+		utils.Audit(c, ctrl.DB, "user.unlock", "user", user.ID, nil, nil)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Hesap kilidi kaldırıldı"})
+}
+
+// GetLockouts: Bekleme veya kilit altındaki IP adreslerini ve kullanıcı adlarını listeler
+func (ctrl *UserController) GetLockouts(c *gin.Context) {
+	throttles := []models.LoginThrottle{}
+	if err := ctrl.DB.Where("blocked_until > ?", time.Now()).Order("blocked_until DESC").Find(&throttles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kilitler getirilemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, throttles)
+}
+
+// DeleteLockout: IP veya kullanıcı adı sayacını silerek kilidi kaldırır
+func (ctrl *UserController) DeleteLockout(c *gin.Context) {
+	var throttle models.LoginThrottle
+	if err := ctrl.DB.First(&throttle, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kilit bulunamadı"})
+		return
+	}
+
+	if _, err := utils.UnlockLogin(ctrl.DB, throttle.Kind, throttle.Key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kilit kaldırılamadı"})
+		return
+	}
+
+	utils.LogInfo(ctrl.DB, "AUTH", fmt.Sprintf("Giriş kilidi kaldırıldı: %s (%s)", throttle.Key, throttle.Kind))
+	utils.Audit(c, ctrl.DB, "lockout.delete", "login_throttle", throttle.ID, throttle, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Kilit kaldırıldı"})
+}
+
+// ResetTwoFactor: Doğrulayıcı cihazını ve kurtarma kodlarını kaybeden kullanıcının iki adımlı doğrulamasını kapatır
+func (ctrl *UserController) ResetTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := ctrl.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı bulunamadı"})
+		return
+	}
+
+	if err := utils.DisableTwoFactor(ctrl.DB, user.ID); err != nil {
+		utils.LogError(ctrl.DB, "USERS", "İki adımlı doğrulama sıfırlanamadı: "+user.Username)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İki adımlı doğrulama sıfırlanamadı"})
+		return
+	}
+
+	utils.LogWarn(ctrl.DB, "USERS", "İki adımlı doğrulama yönetici tarafından sıfırlandı: "+user.Username)
+	utils.Audit(c, ctrl.DB, "user.2fa_reset", "user", user.ID, nil, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "İki adımlı doğrulama sıfırlandı"})
+}
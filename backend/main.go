@@ -4,13 +4,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"scraper/config"
 	"scraper/controllers"
 	"scraper/models"
 	"scraper/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/glebarez/sqlite"
-	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -19,36 +20,55 @@ import (
 var DB *gorm.DB
 
 func main() {
-	// .env dosyasını yükle
-	if err := godotenv.Load(); err != nil {
-		log.Println("Uyarı: .env dosyası bulunamadı")
+	// Ayarları yükle (.env, isteğe bağlı config dosyası ve ortam değişkenleri; hatalı ayarla sunucu açılmaz)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Yapılandırma geçersiz: %v", err)
+	}
+	if cfg.File != "" {
+		log.Printf("Yapılandırma dosyası yüklendi: %s", cfg.File)
 	}
 
 	// Veritabanını Başlat
-	initDB()
+	initDB(cfg.Database.Path)
+
+	// Ayarlar sayfasından kaydedilen çalışma zamanı değerlerini uygula
+	utils.LoadRuntimeSettings(DB)
 
 	// Uptime Başlat
 	utils.InitStartTime()
 
-	// Watchlist Scheduler Başlat
-	go utils.StartWatchlistScheduler(DB)
+	// Tor Proxy Havuzu Başlat (sağlık kontrolü, devre izolasyonu ve NEWNYM)
+	if _, err := utils.StartTorPool(DB); err != nil {
+		log.Fatalf("Tor havuzu başlatılamadı: %v", err)
+	}
 
-	r := gin.Default()
+	// Hedef Yönlendirmesi (direct, Tor, I2P ve adlandırılmış proxy'ler)
+	utils.StartRouting()
 
-	// CORS Ara Katmanı
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+	// Tarama Kuyruğu Başlat (manuel ve watchlist taramaları ortak işçileri kullanır)
+	scanQueue := utils.StartScanQueue(DB, cfg.Scan.Workers)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// Yeniden Etiketleme İşçisi Başlat (keyword değişikliklerini kayıtlı verilere uygular)
+	retagger := utils.StartRetagger(DB)
+
+	// Ham Sayfa Arşivi (SHA-256 adlı gzip dosyaları, yaş ve boyut sınırıyla temizlenir)
+	utils.StartSnapshots(DB)
+
+	// Alarm Bildirim Göndericisi Başlat (taramaları bekletmez, başarısız gönderimleri yeniden dener)
+	utils.StartAlertDelivery(DB)
+
+	// Watchlist Scheduler Başlat
+	go utils.StartWatchlistScheduler(DB, scanQueue)
 
-		c.Next()
-	})
+	// Süresi dolmuş oturum ve iptal kayıtlarını temizle
+	go utils.StartTokenCleanup(DB)
+
+	r := gin.Default()
+
+	// CORS ve Güvenlik Başlıkları
+	r.Use(utils.SecurityHeadersMiddleware(cfg.Security))
+	r.Use(utils.CORSMiddleware(cfg.Security))
 
 	api := r.Group("/api")
 	{
@@ -60,73 +80,151 @@ func main() {
 		// Auth Controller
 		authCtrl := controllers.NewAuthController(DB)
 		api.POST("/login", authCtrl.Login)
+		api.POST("/login/2fa", authCtrl.LoginTwoFactor)
+		api.POST("/refresh", authCtrl.Refresh)
 
 		// --- Protected Rotalar ---
+		// Okuma rotaları tüm rollere (viewer ve üstü), değiştiren rotalar analyst'e,
+		// kullanıcı yönetimi ve veritabanı sıfırlama sadece admin'e açıktır.
 		protected := api.Group("/")
-		protected.Use(utils.AuthMiddleware())
+		protected.Use(utils.AuthMiddleware(DB))
+		analyst := protected.Group("/", utils.RequireRole(models.RoleAnalyst))
+		admin := protected.Group("/", utils.RequireRole(models.RoleAdmin))
 		{
 			// Controller Örnekleri
-			scanCtrl := controllers.NewScanController(DB)
+			scanCtrl := controllers.NewScanController(DB, scanQueue)
 			statsCtrl := controllers.NewStatsController(DB)
 			historyCtrl := controllers.NewHistoryController(DB)
-			settingsCtrl := controllers.NewSettingsController(DB)
+			settingsCtrl := controllers.NewSettingsController(DB, retagger)
+			alertCtrl := controllers.NewAlertController(DB)
+			searchCtrl := controllers.NewSearchController(DB)
+			userCtrl := controllers.NewUserController(DB)
+			auditCtrl := controllers.NewAuditController(DB)
+			systemCtrl := controllers.NewSystemController(DB)
+			snapshotCtrl := controllers.NewSnapshotController(DB)
+
+			// Hesap (şifre değiştirme zorunluyken de erişilebilir)
+			protected.GET("/account", authCtrl.GetAccount)
+			protected.PUT("/account/password", authCtrl.ChangePassword)
+			protected.POST("/logout", authCtrl.Logout)
+
+			// Kişisel API anahtarları
+			protected.GET("/account/api-keys", authCtrl.GetAPIKeys)
+			protected.POST("/account/api-keys", authCtrl.CreateAPIKey)
+			protected.DELETE("/account/api-keys/:id", authCtrl.RevokeAPIKey)
+
+			// İki adımlı doğrulama (TOTP)
+			protected.GET("/account/2fa", authCtrl.GetTwoFactorStatus)
+			protected.POST("/account/2fa/enroll", authCtrl.EnrollTwoFactor)
+			protected.POST("/account/2fa/verify", authCtrl.ConfirmTwoFactor)
+			protected.POST("/account/2fa/recovery-codes", authCtrl.RegenerateRecoveryCodes)
+			protected.POST("/account/2fa/disable", authCtrl.DisableTwoFactor)
 
 			// Tarama
-			protected.POST("/scan", scanCtrl.ScanSite)
+			analyst.POST("/scan", scanCtrl.ScanSite)
+			protected.GET("/scan/jobs/:id", scanCtrl.GetJob)
+			protected.GET("/scan/jobs/:id/events", scanCtrl.JobEvents)
+			analyst.DELETE("/scan/jobs/:id", scanCtrl.CancelJob)
 
 			// İstatistikler ve Loglar
 			protected.GET("/stats/general", statsCtrl.GetGeneralStats)
 			protected.GET("/logs", statsCtrl.GetSystemLogs)
+			protected.GET("/logs/stream", statsCtrl.StreamLogs)
 			protected.GET("/logs/stats", statsCtrl.GetLogStats)
 
 			// Geçmiş
 			protected.GET("/history", historyCtrl.GetHistory)
 			protected.GET("/history/:id", historyCtrl.GetScanDetails)
+			protected.GET("/history/:id/diff", historyCtrl.GetScanDiff)
+
+			// Ham Sayfa Arşivi
+			protected.GET("/snapshots", snapshotCtrl.GetSnapshots)
+			protected.GET("/snapshots/:id/download", snapshotCtrl.DownloadSnapshot)
+			analyst.POST("/snapshots/reparse", snapshotCtrl.ReparseSnapshots)
+			admin.POST("/snapshots/prune", snapshotCtrl.PruneSnapshots)
+
+			// Arama
+			protected.GET("/search", searchCtrl.Search)
 
 			// Sistem İşlemleri
-			protected.POST("/system/reset-db", settingsCtrl.ResetDatabase)
+			admin.POST("/system/reset-db", settingsCtrl.ResetDatabase)
+			admin.GET("/system/config", systemCtrl.GetConfig)
+			admin.PUT("/system/config/runtime", systemCtrl.UpdateRuntimeConfig)
+			admin.DELETE("/system/config/runtime", systemCtrl.ResetRuntimeConfig)
+			protected.GET("/system/tor", systemCtrl.GetTorStatus)
+			protected.GET("/system/routes", systemCtrl.GetRoutes)
+			admin.POST("/system/tor/newnym", systemCtrl.NewTorCircuit)
+
+			// Kullanıcılar
+			admin.GET("/users", userCtrl.GetUsers)
+			admin.POST("/users", userCtrl.AddUser)
+			admin.PUT("/users/:id", userCtrl.UpdateUser)
+			admin.DELETE("/users/:id", userCtrl.DeleteUser)
+			admin.POST("/users/:id/revoke-sessions", userCtrl.RevokeUserSessions)
+			admin.POST("/users/:id/unlock", userCtrl.UnlockUser)
+			admin.DELETE("/users/:id/2fa", userCtrl.ResetTwoFactor)
+			admin.GET("/security/lockouts", userCtrl.GetLockouts)
+			admin.DELETE("/security/lockouts/:id", userCtrl.DeleteLockout)
+
+			// Denetim Kayıtları
+			admin.GET("/audit", auditCtrl.GetAuditLogs)
 
 			// Ayarlar (Keywords)
 			protected.GET("/settings/keywords", settingsCtrl.GetKeywords)
-			protected.POST("/settings/keywords", settingsCtrl.AddKeyword)
-			protected.PUT("/settings/keywords/:id", settingsCtrl.UpdateKeyword)
-			protected.DELETE("/settings/keywords/:id", settingsCtrl.DeleteKeyword)
+			analyst.POST("/settings/keywords", settingsCtrl.AddKeyword)
+			analyst.PUT("/settings/keywords/:id", settingsCtrl.UpdateKeyword)
+			analyst.DELETE("/settings/keywords/:id", settingsCtrl.DeleteKeyword)
+			analyst.POST("/settings/keywords/retag", settingsCtrl.StartRetag)
+			protected.GET("/settings/retag-jobs", settingsCtrl.GetRetagJobs)
+			protected.GET("/settings/retag-jobs/:id", settingsCtrl.GetRetagJob)
+			protected.GET("/settings/retag-jobs/:id/events", settingsCtrl.RetagJobEvents)
 
 			// Ayarlar (User Agents)
 			protected.GET("/settings/user-agents", settingsCtrl.GetUserAgents)
-			protected.POST("/settings/user-agents", settingsCtrl.AddUserAgent)
-			protected.PUT("/settings/user-agents/:id", settingsCtrl.UpdateUserAgent)
-			protected.DELETE("/settings/user-agents/:id", settingsCtrl.DeleteUserAgent)
+			analyst.POST("/settings/user-agents", settingsCtrl.AddUserAgent)
+			analyst.PUT("/settings/user-agents/:id", settingsCtrl.UpdateUserAgent)
+			analyst.DELETE("/settings/user-agents/:id", settingsCtrl.DeleteUserAgent)
 
 			// Ayarlar (Watchlist)
 			protected.GET("/settings/watchlist", settingsCtrl.GetWatchlist)
-			protected.POST("/settings/watchlist", settingsCtrl.AddWatchlistItem)
-			protected.PUT("/settings/watchlist/toggle-all", settingsCtrl.ToggleAllWatchlist)
-			protected.PUT("/settings/watchlist/:id", settingsCtrl.UpdateWatchlistItem)
-			protected.DELETE("/settings/watchlist/:id", settingsCtrl.DeleteWatchlistItem)
-		}
-	}
+			analyst.POST("/settings/watchlist", settingsCtrl.AddWatchlistItem)
+			analyst.PUT("/settings/watchlist/toggle-all", settingsCtrl.ToggleAllWatchlist)
+			analyst.PUT("/settings/watchlist/:id", settingsCtrl.UpdateWatchlistItem)
+			analyst.DELETE("/settings/watchlist/:id", settingsCtrl.DeleteWatchlistItem)
+
+			// Ayarlar (Seçici Profilleri)
+			protected.GET("/settings/profiles", settingsCtrl.GetProfiles)
+			analyst.POST("/settings/profiles", settingsCtrl.AddProfile)
+			analyst.POST("/settings/profiles/test", settingsCtrl.TestProfile)
+			analyst.PUT("/settings/profiles/:id", settingsCtrl.UpdateProfile)
+			analyst.DELETE("/settings/profiles/:id", settingsCtrl.DeleteProfile)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+			// Alarmlar
+			protected.GET("/alerts/rules", alertCtrl.GetRules)
+			analyst.POST("/alerts/rules", alertCtrl.AddRule)
+			analyst.PUT("/alerts/rules/:id", alertCtrl.UpdateRule)
+			analyst.DELETE("/alerts/rules/:id", alertCtrl.DeleteRule)
+			protected.GET("/alerts/channels", alertCtrl.GetChannels)
+			analyst.POST("/alerts/channels", alertCtrl.AddChannel)
+			analyst.PUT("/alerts/channels/:id", alertCtrl.UpdateChannel)
+			analyst.DELETE("/alerts/channels/:id", alertCtrl.DeleteChannel)
+			analyst.POST("/alerts/channels/:id/test", alertCtrl.TestChannel)
+			protected.GET("/alerts/deliveries", alertCtrl.GetDeliveries)
+		}
 	}
 
-	log.Printf("Sunucu %s portunda başlatılıyor", port)
-	if err := r.Run(":" + port); err != nil {
+	log.Printf("Sunucu %s portunda başlatılıyor", cfg.Server.Port)
+	if err := r.Run(":" + cfg.Server.Port); err != nil {
 		log.Fatal("Sunucu başlatılamadı: ", err)
 	}
 }
 
-func initDB() {
+func initDB(dbPath string) {
 	// Veri dizini yoksa oluştur
-	if _, err := os.Stat("data"); os.IsNotExist(err) {
-		os.Mkdir("data", 0755)
-	}
-
-	dbPath := "data/scraper.db"
-	if envPath := os.Getenv("DB_PATH"); envPath != "" {
-		dbPath = envPath
+	if dir := filepath.Dir(dbPath); dir != "." {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			os.MkdirAll(dir, 0755)
+		}
 	}
 
 	var err error
@@ -135,27 +233,76 @@ func initDB() {
 		log.Fatalf("Veritabanı bağlantısı başarısız: %v", err)
 	}
 
+	// Eski tarama başına kopyalanan konu/iletileri tekil kayıtlara dönüştür
+	if err := utils.MigrateCanonicalStore(DB); err != nil {
+		log.Fatalf("Tekil kayıt dönüşümü başarısız: %v", err)
+	}
+	if err := utils.MigratePostSightingEdits(DB); err != nil {
+		log.Fatalf("İleti düzenleme bilgisi taşınamadı: %v", err)
+	}
+
 	// Otomatik Taşıma
-	err = DB.AutoMigrate(&models.Site{}, &models.Stats{}, &models.User{}, &models.SystemLog{}, &models.Thread{}, &models.Post{}, &models.Keyword{}, &models.UserAgent{}, &models.Watchlist{})
+	err = DB.AutoMigrate(&models.Site{}, &models.Stats{}, &models.ScanMetadata{}, &models.Snapshot{}, &models.SnapshotBlob{}, &models.User{}, &models.RecoveryCode{}, &models.Session{}, &models.RevokedToken{}, &models.LoginThrottle{}, &models.APIKey{}, &models.SystemLog{}, &models.AuditLog{}, &models.Thread{}, &models.Post{}, &models.ThreadSighting{}, &models.PostSighting{}, &models.ContentTag{}, &models.Keyword{}, &models.UserAgent{}, &models.Watchlist{}, &models.ExtractionProfile{}, &models.ScanJob{}, &models.RetagJob{}, &models.AlertRule{}, &models.AlertChannel{}, &models.AlertDelivery{}, &models.RuntimeSetting{})
 	if err != nil {
 		log.Printf("Taşıma başarısız: %v", err)
 	} else {
 		log.Println("Veritabanı bağlandı ve taşındı.")
 	}
 
+	// Tam metin arama dizini
+	if err := utils.EnsureSearchIndex(DB); err != nil {
+		log.Printf("Arama dizini oluşturulamadı: %v", err)
+	}
+
+	// Forum numarası olmayan iletilerin eski (tarihli) kimliklerini güncelle
+	if err := utils.RekeyPositionalPosts(DB); err != nil {
+		log.Printf("İleti kimlikleri güncellenemedi: %v", err)
+	}
+
 	seedUsers()
 }
 
+// Kurulumda oluşturulan admin hesabının varsayılan bilgileri
+const (
+	defaultAdminUsername = "admin"
+	defaultAdminPassword = "galileoff"
+)
+
+// seedUsers: İlk kurulumda admin hesabını oluşturur (ilk girişte şifre değiştirilmeli).
+// Rol alanı eklenmeden önce oluşturulmuş veritabanlarında admin rolü ve varsayılan şifre kontrolü yapılır.
 func seedUsers() {
 	var count int64
 	DB.Model(&models.User{}).Count(&count)
 	if count == 0 {
-		passwordHash, _ := bcrypt.GenerateFromPassword([]byte("galileoff"), bcrypt.DefaultCost)
+		passwordHash, _ := bcrypt.GenerateFromPassword([]byte(defaultAdminPassword), bcrypt.DefaultCost)
 		user := models.User{
-			Username: "admin",
-			Password: string(passwordHash),
+			Username:           defaultAdminUsername,
+			Password:           string(passwordHash),
+			Role:               models.RoleAdmin,
+			MustChangePassword: true,
 		}
 		DB.Create(&user)
 		log.Println("Admin kullanıcısı oluşturuldu.")
+		return
+	}
+
+	// Hiç admin yoksa (rol alanı yeni eklendiyse) ilk kullanıcı admin yapılır
+	var admins int64
+	DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&admins)
+	if admins == 0 {
+		var first models.User
+		if err := DB.Order("id").First(&first).Error; err == nil {
+			DB.Model(&first).Update("role", models.RoleAdmin)
+			log.Printf("%s kullanıcısına admin rolü verildi.", first.Username)
+		}
+	}
+
+	// Varsayılan şifre hâlâ kullanılıyorsa değiştirilmesi zorunlu tutulur
+	var seeded models.User
+	if err := DB.Where("username = ?", defaultAdminUsername).Limit(1).Find(&seeded).Error; err == nil && seeded.ID != 0 && !seeded.MustChangePassword {
+		if bcrypt.CompareHashAndPassword([]byte(seeded.Password), []byte(defaultAdminPassword)) == nil {
+			DB.Model(&seeded).Update("must_change_password", true)
+			log.Println("Uyarı: admin kullanıcısı varsayılan şifreyi kullanıyor, ilk girişte değiştirilmesi istenecek.")
+		}
 	}
 }
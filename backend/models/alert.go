@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Alarm önem dereceleri
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Bildirim kanalı türleri
+const (
+	ChannelWebhook = "webhook" // JSON POST
+	ChannelSMTP    = "smtp"    // E-posta
+	ChannelBot     = "bot"     // Telegram benzeri bot API (chat_id + text)
+)
+
+// Bildirim gönderim durumları
+const (
+	DeliveryPending = "pending" // Sırada veya yeniden denenecek
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// AlertRule: Bir keyword yeni içerikte geçtiğinde hangi kanallara bildirim gideceğini belirler.
+// SiteIDs ve WatchlistIDs boşsa kural tüm taramalara uygulanır.
+type AlertRule struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	KeywordID    uint           `gorm:"index;not null" json:"keyword_id"`
+	Keyword      Keyword        `gorm:"foreignKey:KeywordID" json:"keyword"`
+	Severity     string         `gorm:"default:'medium'" json:"severity"`
+	SiteIDs      []uint         `gorm:"serializer:json" json:"site_ids"`
+	WatchlistIDs []uint         `gorm:"serializer:json" json:"watchlist_ids"`
+	ChannelIDs   []uint         `gorm:"serializer:json" json:"channel_ids"`
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// AlertChannel: Bildirim hedefi. Türüne göre ilgili alanlar kullanılır.
+type AlertChannel struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	Type         string         `gorm:"not null" json:"type"` // webhook, smtp, bot
+	URL          string         `json:"url"`                  // webhook adresi veya bot sendMessage adresi
+	Secret       string         `json:"secret,omitempty"`     // webhook için X-Alert-Signature (HMAC-SHA256) anahtarı
+	ChatID       string         `json:"chat_id"`              // bot mesajının gideceği sohbet
+	SMTPHost     string         `json:"smtp_host"`
+	SMTPPort     int            `json:"smtp_port"`
+	SMTPUsername string         `json:"smtp_username"`
+	SMTPPassword string         `json:"smtp_password,omitempty"`
+	SMTPFrom     string         `json:"smtp_from"`
+	SMTPTo       string         `json:"smtp_to"` // Virgülle ayrılmış alıcılar
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// AlertDelivery: Her bildirim gönderiminin kaydı. Taramalardan gelen bildirimler bekleme durumunda
+// oluşturulur ve arka planda gönderilir; deneme sayısı ve sıradaki deneme zamanı burada tutulur.
+type AlertDelivery struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	RuleID        *uint      `gorm:"index" json:"rule_id"` // Test gönderimlerinde boş
+	ChannelID     uint       `gorm:"index;not null" json:"channel_id"`
+	StatsID       *uint      `gorm:"index" json:"stats_id"`
+	Severity      string     `json:"severity"`
+	Status        string     `gorm:"index" json:"status"` // pending, sent, failed
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at"` // Bekleyen gönderimin sıradaki denemesi (boşsa hemen)
+	Error         string     `json:"error"`                        // Son denemenin hatası
+	Payload       string     `gorm:"type:text" json:"payload"`     // Gönderilen mesaj (JSON)
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	SentAt        *time.Time `json:"sent_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// API anahtarı kapsamları
+const (
+	ScopeRead  = "read"  // Sadece okuma (viewer yetkileri)
+	ScopeScan  = "scan"  // Tarama ve ayar değişiklikleri (analyst yetkileri)
+	ScopeAdmin = "admin" // Tüm yetkiler
+)
+
+// Kapsamın en fazla karşılık geldiği rol
+var scopeRoles = map[string]string{ScopeRead: RoleViewer, ScopeScan: RoleAnalyst, ScopeAdmin: RoleAdmin}
+
+// APIKey: Otomasyon istemcileri için kişisel API anahtarı. Anahtarın kendisi değil SHA-256 özeti saklanır.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `json:"prefix"` // Listede anahtarı tanımak için ilk karakterler
+	KeyHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scope      string     `gorm:"not null" json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"` // Boşsa süresiz
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ValidScope: Kapsam tanımlı mı
+func ValidScope(scope string) bool {
+	_, ok := scopeRoles[scope]
+	return ok
+}
+
+// ScopeAllowed: Kullanıcının rolü bu kapsamda anahtar oluşturmaya yetiyor mu
+func ScopeAllowed(scope, userRole string) bool {
+	return RoleAllows(userRole, scopeRoles[scope])
+}
+
+// ScopeRole: Anahtarla yapılan isteğin rolü; kapsam ve kullanıcının rolünden düşük olanı
+func ScopeRole(scope, userRole string) string {
+	role := scopeRoles[scope]
+	if RoleAllows(role, userRole) {
+		return userRole
+	}
+	return role
+}
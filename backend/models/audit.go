@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog: Kimliği doğrulanmış kullanıcının yaptığı değişikliğin kaydı.
+// Sistem loglarından ayrı tutulur; "Loglar" sıfırlaması bu tabloyu silmez.
+type AuditLog struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	UserID     *uint           `gorm:"index" json:"user_id"` // Bilinmeyen kullanıcı girişlerinde boş
+	Username   string          `gorm:"index" json:"username"`
+	Action     string          `gorm:"index;not null" json:"action"` // Örn: keyword.update, scan.start, auth.login
+	EntityType string          `gorm:"index" json:"entity_type"`     // Örn: keyword, watchlist, user
+	EntityID   string          `gorm:"index" json:"entity_id"`
+	Before     json.RawMessage `gorm:"type:text" json:"before,omitempty"` // Değişiklik öncesi değer (JSON)
+	After      json.RawMessage `gorm:"type:text" json:"after,omitempty"`  // Değişiklik sonrası değer (JSON)
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
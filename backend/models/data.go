@@ -5,26 +5,47 @@ import (
 )
 
 type Thread struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	SiteID    uint      `gorm:"index;not null" json:"site_id"`
-	StatsID   uint      `gorm:"index;not null" json:"stats_id"` // Hangi taramaya ait olduğu
-	Title     string    `json:"title"`
-	Link      string    `json:"link"`
-	Author    string    `json:"author"`
-	Date      string    `json:"date"`
-	Category  string    `json:"category"` // Otomatik belirlenen kategori
-	Posts     []Post    `json:"posts" gorm:"foreignKey:ThreadID;constraint:OnDelete:CASCADE;"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	SiteID    uint         `gorm:"index;uniqueIndex:idx_thread_site_key;not null" json:"site_id"`
+	StatsID   uint         `gorm:"index;not null" json:"stats_id"`             // İlk görüldüğü tarama (tarama detayında o tarama)
+	RemoteID  string       `json:"remote_id"`                                  // Forumun kendi konu numarası
+	Key       string       `gorm:"uniqueIndex:idx_thread_site_key" json:"key"` // Site + bağlantıdan türetilen kimlik (taramalar arası eşleştirme)
+	Hash      string       `json:"hash"`                                       // Başlık/yazar içerik özeti
+	Title     string       `json:"title"`
+	Link      string       `json:"link"`
+	Author    string       `json:"author"`
+	Date      string       `json:"date"`
+	Category  string       `json:"category"` // Otomatik belirlenen kategori
+	Replies   int          `json:"replies"`
+	Views     int          `json:"views"`
+	Posts     []Post       `json:"posts" gorm:"foreignKey:ThreadID;constraint:OnDelete:CASCADE;"`
+	Tags      []ContentTag `gorm:"-" json:"tags"` // Başlıkta eşleşen keyword'ler
+	FirstSeen time.Time    `json:"first_seen"`
+	LastSeen  time.Time    `json:"last_seen"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
 }
 
 type Post struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	ThreadID   uint      `gorm:"index;not null" json:"thread_id"`
-	Author     string    `json:"author"`
-	Content    string    `json:"content"`
-	Date       string    `json:"date"`
-	Order      int       `json:"order"`       // İleti sırası
-	LastEdited string    `json:"last_edited"` // Düzenleme tarihi/bilgisi
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	ThreadID   uint         `gorm:"index;uniqueIndex:idx_post_fingerprint;not null" json:"thread_id"`
+	RemoteID   string       `json:"remote_id"`                                    // Forumun kendi ileti numarası
+	Key        string       `gorm:"uniqueIndex:idx_post_fingerprint" json:"key"`  // Konu kimliği + ileti kimliğinden türetilir
+	Hash       string       `gorm:"uniqueIndex:idx_post_fingerprint" json:"hash"` // İçerik özeti; düzenlenen ileti yeni kayıt olur
+	Author     string       `json:"author"`
+	Content    string       `json:"content"`
+	Date       string       `json:"date"`
+	Order      int          `json:"order"`                         // İleti sırası
+	LastEdited string       `json:"last_edited"`                   // Düzenleme tarihi/bilgisi
+	Quotes     []PostQuote  `gorm:"serializer:json" json:"quotes"` // İletideki alıntı blokları
+	Tags       []ContentTag `gorm:"-" json:"tags"`                 // İçerikte eşleşen keyword'ler
+	FirstSeen  time.Time    `json:"first_seen"`
+	LastSeen   time.Time    `json:"last_seen"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// PostQuote, ileti içinden ayrıştırılan alıntı bloğudur.
+type PostQuote struct {
+	Author  string `json:"author"`
+	Content string `json:"content"`
 }
@@ -6,12 +6,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// Keyword eşleştirme modları
+const (
+	MatchContains = "contains" // Metnin herhangi bir yerinde geçmesi yeterli
+	MatchWord     = "word"     // Sadece tam kelime olarak ("ssh", "sshd_config" içinde eşleşmez)
+	MatchRegex    = "regex"    // Word bir düzenli ifadedir
+)
+
 type Keyword struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Word      string         `gorm:"index;not null" json:"word"`
-	Category  string         `json:"category"`
-	Color     string         `json:"color"` // Etiket rengi
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Word          string         `gorm:"index;not null" json:"word"`
+	Category      string         `json:"category"`
+	Color         string         `json:"color"` // Etiket rengi
+	Mode          string         `gorm:"default:'word'" json:"mode"`
+	CaseSensitive bool           `json:"case_sensitive"`
+	TurkishCase   bool           `json:"turkish_case"`            // Küçük harfe çevirirken Türkçe kuralları (İ→i, I→ı)
+	Weight        float64        `gorm:"default:1" json:"weight"` // Etiket puanı = ağırlık x eşleşme sayısı
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
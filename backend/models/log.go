@@ -8,6 +8,7 @@ type SystemLog struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Level     string    `json:"level"` // INFO, WARN, ERROR, SUCCESS
 	Source    string    `json:"source"`
+	Code      string    `gorm:"index" json:"code,omitempty"` // Tarama hatalarında hata kodu
 	Message   string    `json:"message"`
 	CreatedAt time.Time `json:"created_at"`
 }
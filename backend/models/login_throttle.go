@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Giriş sayacı türleri
+const (
+	ThrottleIP   = "ip"
+	ThrottleUser = "user"
+)
+
+// LoginThrottle: IP adresi veya kullanıcı adı başına başarısız giriş sayacı.
+// Kullanıcı adı, var olmayan hesaplar için de tutulur; yanıtlardan hesabın varlığı anlaşılmaz.
+type LoginThrottle struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Kind         string     `gorm:"uniqueIndex:idx_login_throttle;not null" json:"kind"` // ip veya user
+	Key          string     `gorm:"uniqueIndex:idx_login_throttle;not null" json:"key"`  // IP adresi veya küçük harfli kullanıcı adı
+	Failures     int        `json:"failures"`
+	LastFailure  time.Time  `json:"last_failure"`
+	BlockedUntil *time.Time `gorm:"index" json:"blocked_until"` // Bu zamana kadar giriş denemesi kabul edilmez
+	Locked       bool       `json:"locked"`                     // Kilit eşiği aşıldı (geri çekilmeden ayrı)
+	LockedAt     *time.Time `gorm:"index" json:"locked_at"`
+	Lockouts     int        `json:"lockouts"` // Toplam kilitlenme sayısı
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExtractionProfile: Hazır seçicilerin tutmadığı forumlar için kullanıcı tanımlı seçici şablonu.
+// Seçiciler "css@öznitelik" veya "xpath:ifade" biçimindedir.
+type ExtractionProfile struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Name          string         `gorm:"not null" json:"name"`
+	SiteID        *uint          `gorm:"index" json:"site_id"` // Belirli bir siteye bağlıysa
+	HostPattern   string         `json:"host_pattern"`         // örn: "*.onion", "forumxyz.onion"
+	ThreadList    string         `json:"thread_list"`          // Konu listesindeki satır
+	ThreadLink    string         `json:"thread_link"`          // Satır içindeki konu bağlantısı
+	PostContainer string         `json:"post_container"`       // İleti kapsayıcısı
+	Author        string         `json:"author"`               // İleti yazarı
+	Date          string         `json:"date"`                 // İleti tarihi
+	Content       string         `json:"content"`              // İleti içeriği
+	NextPage      string         `json:"next_page"`            // Sonraki sayfa bağlantısı
+	IsActive      bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
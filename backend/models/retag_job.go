@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// RetagJob: Kayıtlı konu ve iletileri güncel keyword listesiyle yeniden etiketleyen arka plan işi.
+// Durumlar tarama işleriyle aynıdır (queued, running, succeeded, failed).
+type RetagJob struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	Trigger          string         `gorm:"default:'manual'" json:"trigger"` // manual veya keyword (keyword değişikliği sonrası)
+	Status           string         `gorm:"index;default:'queued'" json:"status"`
+	Error            string         `json:"error"`
+	TotalThreads     int            `json:"total_threads"`
+	ProcessedThreads int            `json:"processed_threads"`
+	ProcessedPosts   int            `json:"processed_posts"`
+	CategoryChanged  int            `json:"category_changed"`                   // Kategorisi değişen konu sayısı
+	TagsChanged      int            `json:"tags_changed"`                       // Etiketleri değişen konu başlığı + ileti sayısı
+	Transitions      map[string]int `gorm:"serializer:json" json:"transitions"` // "Eski -> Yeni" kategori geçişleri
+	Duration         float64        `json:"duration"`                           // Saniye
+	CreatedAt        time.Time      `json:"created_at"`
+	StartedAt        *time.Time     `json:"started_at"`
+	FinishedAt       *time.Time     `json:"finished_at"`
+}
+
+// IsFinished: İş son durumlardan birine ulaştı mı
+func (j *RetagJob) IsFinished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
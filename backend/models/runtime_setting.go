@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RuntimeSetting: Ayarlar sayfasından çalışırken değiştirilen bir yapılandırma değeri.
+// Key, config.Tunables alanının JSON adıdır; Value JSON olarak saklanır.
+// Satırı olmayan ayarlar dosya/ortam değişkeni değerini kullanır.
+type RuntimeSetting struct {
+	Key       string    `gorm:"primaryKey" json:"key"`
+	Value     string    `gorm:"not null" json:"value"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
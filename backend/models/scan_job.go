@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tarama işi durumları
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// ScanJob: Kuyruğa alınan manuel veya watchlist taraması
+type ScanJob struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	URL         string          `gorm:"not null" json:"url"`
+	Source      string          `gorm:"default:'manual'" json:"source"` // manual veya watchlist
+	WatchlistID *uint           `gorm:"index" json:"watchlist_id"`
+	Route       string          `json:"route"` // Taramanın kullandığı bağlantı yolu (direct, tor, i2p...)
+	Status      string          `gorm:"index;default:'queued'" json:"status"`
+	Error       string          `json:"error"`
+	ErrorCode   string          `gorm:"index" json:"error_code"`             // scraper.Code* (timeout, http_status, not_forum...)
+	Saved       bool            `json:"saved"`                               // Sonuç veritabanına yazıldı mı
+	StatsID     *uint           `json:"stats_id"`                            // Kaydedilen taramanın Stats kaydı
+	Result      json.RawMessage `gorm:"type:text" json:"result,omitempty"`   // ScrapeResult (JSON)
+	Attempts    json.RawMessage `gorm:"type:text" json:"attempts,omitempty"` // []scraper.AttemptRecord (JSON)
+	Duration    float64         `json:"duration"`                            // Saniye
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+}
+
+// IsFinished: İş son durumlardan birine ulaştı mı
+func (j *ScanJob) IsFinished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScanMetadata: Taramada alınan bir sayfanın HTTP üst verisi (durum kodu, başlıklar, yönlendirmeler, süre).
+// Başarısız ve forum olmayan taramalar kaydedilmediğinden (Stats yok) üst veri tarama işine de bağlanır.
+type ScanMetadata struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	JobID       uint            `gorm:"index" json:"job_id"`
+	StatsID     uint            `gorm:"index;not null" json:"stats_id"` // 0: kaydedilmemiş tarama
+	URL         string          `json:"url"`                            // İstenen adres
+	FinalURL    string          `json:"final_url"`                      // Yönlendirmelerden sonraki adres
+	Depth       int             `json:"depth"`                          // 1: hedef sayfa
+	StatusCode  int             `gorm:"index" json:"status_code"`
+	ContentType string          `json:"content_type"`
+	Server      string          `json:"server"`     // Server başlığı (banner değişikliklerini izlemek için ayrı tutulur)
+	BodySize    int             `json:"body_size"`  // Bayt
+	LatencyMs   float64         `json:"latency_ms"` // İstekten gövdenin okunmasına kadar
+	Headers     json.RawMessage `gorm:"type:text" json:"headers"`
+	Redirects   json.RawMessage `gorm:"type:text" json:"redirects,omitempty"` // [{from, to, status_code}]
+	CreatedAt   time.Time       `json:"created_at"`
+}
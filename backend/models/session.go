@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Session: Yenileme (refresh) token'ı ile sürdürülen oturum. Token'ın kendisi değil SHA-256 özeti saklanır.
+// Her yenilemede yeni kayıt açılır ve eskisi iptal edilir (rotation).
+type Session struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash       string     `gorm:"uniqueIndex;not null" json:"-"`
+	AccessJTI       string     `gorm:"index" json:"-"` // Bu oturumdan verilen son erişim token'ının JWT ID'si
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+	ReplacedByID    *uint      `json:"replaced_by_id"` // Yenileme sonucu açılan oturum
+	IP              string     `json:"ip"`
+	UserAgent       string     `json:"user_agent"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// RevokedToken: Süresi dolmadan geçersiz kılınan erişim token'ı.
+// ExpiresAt geçtikten sonra token zaten kabul edilmediği için kayıt silinebilir.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
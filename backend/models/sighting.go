@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// ThreadSighting: Tekil konunun hangi taramada görüldüğü.
+// Yanıt/görüntülenme sayıları taramaya göre değiştiği için burada saklanır.
+type ThreadSighting struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ThreadID  uint      `gorm:"uniqueIndex:idx_thread_sighting;not null" json:"thread_id"`
+	StatsID   uint      `gorm:"uniqueIndex:idx_thread_sighting;index;not null" json:"stats_id"`
+	Replies   int       `json:"replies"`
+	Views     int       `json:"views"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostSighting: Tekil iletinin hangi taramada, hangi sırada görüldüğü.
+// Düzenleme bilgisi içerik değişmeden de güncellenebildiğinden taramaya göre burada saklanır.
+type PostSighting struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PostID     uint      `gorm:"uniqueIndex:idx_post_sighting;not null" json:"post_id"`
+	StatsID    uint      `gorm:"uniqueIndex:idx_post_sighting;index;not null" json:"stats_id"`
+	Order      int       `json:"order"`
+	LastEdited string    `json:"last_edited"`
+	CreatedAt  time.Time `json:"created_at"`
+}
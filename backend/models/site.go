@@ -18,7 +18,10 @@ type Stats struct {
 	SiteID       uint      `gorm:"index" json:"site_id"`
 	Site         Site      `json:"site" gorm:"foreignKey:SiteID"`
 	Source       string    `gorm:"default:'manual'" json:"source"` // manual veya watchlist
+	Engine       string    `json:"engine"`                         // Tespit edilen forum yazılımı
 	TotalThreads int       `json:"total_threads"`
 	TotalPosts   int       `json:"total_posts"`
 	ScanDate     time.Time `json:"scan_date"`
+	PreviousID   *uint     `json:"previous_id"` // Karşılaştırılan önceki tarama
+	Changed      bool      `json:"changed"`     // Önceki taramaya göre değişiklik var mı
 }
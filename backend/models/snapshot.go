@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Snapshot: Tarama işinde alınan bir sayfanın arşivdeki ham gövdesi (iş → URL → blob)
+type Snapshot struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	JobID       uint      `gorm:"index" json:"job_id"`
+	StatsID     *uint     `gorm:"index" json:"stats_id"` // Sonuç kaydedildiyse (forum olmayan sayfalarda boş)
+	URL         string    `json:"url"`                   // İstenen adres
+	FinalURL    string    `json:"final_url"`             // Yönlendirmelerden sonraki adres
+	Depth       int       `json:"depth"`                 // 1: hedef sayfa
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type"`
+	Hash        string    `gorm:"index;size:64" json:"hash"` // Gövdenin SHA-256 özeti (hex)
+	Size        int64     `json:"size"`                      // Ham gövde (bayt)
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// SnapshotBlob: Arşivdeki tekil gövde dosyası. Aynı içerik birden fazla taramada görülse de bir kez saklanır.
+type SnapshotBlob struct {
+	Hash       string    `gorm:"primaryKey;size:64" json:"hash"`
+	Size       int64     `json:"size"`        // Ham boyut
+	StoredSize int64     `json:"stored_size"` // Diskteki gzip'li boyut
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// ContentTag: Bir konu başlığı veya iletide eşleşen keyword.
+// PostID 0 ise etiket konu başlığına aittir. Bir içerik birden fazla etiket taşıyabilir.
+type ContentTag struct {
+	ID        uint        `gorm:"primaryKey" json:"id"`
+	ThreadID  uint        `gorm:"index;uniqueIndex:idx_content_tag;not null" json:"thread_id"`
+	PostID    uint        `gorm:"uniqueIndex:idx_content_tag;index" json:"post_id"`
+	KeywordID uint        `gorm:"uniqueIndex:idx_content_tag;index;not null" json:"keyword_id"`
+	Word      string      `json:"word"`
+	Category  string      `json:"category"`
+	Score     float64     `json:"score"`
+	Matches   []MatchSpan `gorm:"serializer:json" json:"matches"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// MatchSpan: Eşleşmenin metindeki konumu (karakter/rune cinsinden, End hariç)
+type MatchSpan struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
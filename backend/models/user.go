@@ -1,10 +1,51 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kullanıcı rolleri (yetki sırasıyla)
+const (
+	RoleViewer  = "viewer"  // Geçmiş, arama, istatistik ve logları okuyabilir
+	RoleAnalyst = "analyst" // Tarama başlatabilir, keyword/watchlist/alarm ayarlarını düzenleyebilir
+	RoleAdmin   = "admin"   // Kullanıcıları yönetebilir ve veritabanını sıfırlayabilir
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleAnalyst: 2, RoleAdmin: 3}
 
 // Kullanıcı Modeli
 type User struct {
 	gorm.Model
-	Username string `json:"username" gorm:"unique"`
-	Password string `json:"-"` // Şifre JSON çıktısında görünmemeli
+	Username           string `json:"username" gorm:"unique"`
+	Password           string `json:"-"` // Şifre JSON çıktısında görünmemeli
+	Role               string `json:"role" gorm:"default:'viewer'"`
+	MustChangePassword bool   `json:"must_change_password"` // Varsayılan/yönetici tarafından atanan şifre ilk girişte değiştirilmeli
+
+	// İki adımlı doğrulama (RFC 6238 TOTP)
+	TOTPEnabled       bool   `json:"totp_enabled"`
+	TOTPSecret        string `json:"-"`
+	TOTPPendingSecret string `json:"-"` // Kurulumda doğrulanmayı bekleyen anahtar
+	TOTPLastStep      int64  `json:"-"` // Son kabul edilen kodun zaman adımı; aynı kod tekrar kullanılamaz
+}
+
+// RecoveryCode: Doğrulayıcı cihaz kaybedildiğinde kullanılan tek kullanımlık kurtarma kodu (SHA-256 özeti)
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"index;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ValidRole: Rol tanımlı mı
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows: role, required rolünün yetkilerini kapsıyor mu (admin > analyst > viewer)
+func RoleAllows(role, required string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[required]
 }
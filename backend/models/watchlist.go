@@ -14,7 +14,27 @@ type Watchlist struct {
 	LastChecked     *time.Time     `json:"last_checked"`                                // Son kontrol zamanı
 	NextCheck       *time.Time     `json:"next_check"`                                  // Bir sonraki kontrol zamanı
 	IsActive        bool           `gorm:"default:true" json:"is_active"`               // Aktif/pasif durumu
+	CrawlEnabled    bool           `json:"crawl_enabled"`                               // Çok sayfalı tarama
+	CrawlMaxDepth   int            `json:"crawl_max_depth"`                             // Crawl derinlik sınırı
+	CrawlMaxPages   int            `json:"crawl_max_pages"`                             // Crawl sayfa sınırı
+	CrawlExternal   bool           `json:"crawl_external"`                              // Farklı sunuculara çıkılabilir mi
+	Route           string         `json:"route"`                                       // Bağlantı yolu (boşsa host kuralları)
+	Failures        int            `json:"failures"`                                    // Art arda başarısız tarama sayısı
+	LastErrorCode   string         `json:"last_error_code"`                             // Son başarısız taramanın hata kodu
+	LastError       string         `json:"last_error"`
+	Retry           RetryOverride  `gorm:"embedded;embeddedPrefix:retry_" json:"retry"` // Öğeye özel yeniden deneme politikası
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
+
+// RetryOverride: Watchlist öğesinin genel yeniden deneme ayarlarından farklı değerleri.
+// Sıfır/boş alanlarda Ayarlar sayfasındaki değer kullanılır.
+type RetryOverride struct {
+	MaxAttempts   int      `json:"max_attempts"`   // İlk deneme dahil toplam deneme
+	BaseBackoff   int      `json:"base_backoff"`   // Saniye
+	MaxBackoff    int      `json:"max_backoff"`    // Saniye
+	Jitter        *float64 `json:"jitter"`         // 0-1
+	RetryOn       string   `json:"retry_on"`       // Virgülle ayrılmış hata kodları
+	SwitchCircuit *bool    `json:"switch_circuit"` // Yeniden denemeden önce yeni Tor devresi
+}
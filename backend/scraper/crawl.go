@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"net/url"
+)
+
+// Crawl modu varsayılanları ve üst sınırları
+const (
+	DefaultCrawlDepth = 2
+	DefaultCrawlPages = 20
+	MaxCrawlDepth     = 5
+	MaxCrawlPages     = 200
+)
+
+// CrawlOptions, çok sayfalı taramanın (konu bağlantıları ve sayfalama) sınırlarını belirler.
+type CrawlOptions struct {
+	Enabled       bool `json:"enabled"`
+	MaxDepth      int  `json:"max_depth"`      // Kök sayfadan itibaren izlenecek bağlantı derinliği
+	MaxPages      int  `json:"max_pages"`      // Ziyaret edilecek toplam sayfa sayısı
+	AllowExternal bool `json:"allow_external"` // false ise sadece hedef sunucudaki bağlantılar izlenir
+}
+
+// Normalize, boş değerlere varsayılanları atar ve sınırları aşan değerleri kırpar.
+func (o CrawlOptions) Normalize() CrawlOptions {
+	if !o.Enabled {
+		return CrawlOptions{}
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultCrawlDepth
+	}
+	if o.MaxDepth > MaxCrawlDepth {
+		o.MaxDepth = MaxCrawlDepth
+	}
+	if o.MaxPages <= 0 {
+		o.MaxPages = DefaultCrawlPages
+	}
+	if o.MaxPages > MaxCrawlPages {
+		o.MaxPages = MaxCrawlPages
+	}
+	return o
+}
+
+// crawlState, ziyaret edilen sayfaların hangi konuya ait olduğunu tutar.
+// Konu bağlantısı ve konunun sonraki sayfaları aynı ThreadData'ya yazılır.
+type crawlState struct {
+	owners map[string]int // canonical URL -> result.Threads indeksi
+}
+
+func newCrawlState() *crawlState {
+	return &crawlState{owners: make(map[string]int)}
+}
+
+// canonicalURL, karşılaştırma için URL'deki fragment kısmını atar.
+func canonicalURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	return u.String()
+}
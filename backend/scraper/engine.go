@@ -1,10 +1,13 @@
 package scraper
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math/rand"
-	"net"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -16,430 +19,588 @@ import (
 )
 
 type ScrapeResult struct {
-	URL          string       `json:"url"`
-	IsForum      bool         `json:"is_forum"`
-	Title        string       `json:"title"`
-	ThreadCount  int          `json:"thread_count"`
-	PostCount    int          `json:"post_count"`
-	ErrorMessage string       `json:"error_message"`
-	Threads      []ThreadData `json:"threads"`
-	UserAgent    string       `json:"user_agent"`
+	URL          string         `json:"url"`
+	IsForum      bool           `json:"is_forum"`
+	Title        string         `json:"title"`
+	ThreadCount  int            `json:"thread_count"`
+	PostCount    int            `json:"post_count"`
+	ErrorMessage string         `json:"error_message"`
+	Threads      []ThreadData   `json:"threads"`
+	UserAgent    string         `json:"user_agent"`
+	Engine       string         `json:"engine"`    // Tespit edilen forum yazılımı
+	Responses    []ResponseMeta `json:"responses"` // Alınan her sayfanın HTTP üst verisi
 }
 
 type ThreadData struct {
-	Title    string     `json:"title"`
-	Link     string     `json:"link"`
-	Author   string     `json:"author"`
-	Date     string     `json:"date"`
-	Content  string     `json:"content"`
-	Category string     `json:"category"` // Tespit edilen kategori
-	Posts    []PostData `json:"posts"`
+	ThreadID string              `json:"thread_id"` // Forumun kendi konu numarası
+	Title    string              `json:"title"`
+	Link     string              `json:"link"`
+	Author   string              `json:"author"`
+	Date     string              `json:"date"`
+	Content  string              `json:"content"`
+	Category string              `json:"category"` // Etiket puanı en yüksek kategori
+	Tags     []models.ContentTag `json:"tags"`     // Başlıkta eşleşen keyword'ler
+	Replies  int                 `json:"replies"`
+	Views    int                 `json:"views"`
+	Posts    []PostData          `json:"posts"`
 }
 
 type PostData struct {
-	Author     string `json:"author"`
-	Content    string `json:"content"`
-	Date       string `json:"date"`
-	Reactions  string `json:"reactions"`
-	LastEdited string `json:"last_edited"`
+	PostID     string              `json:"post_id"` // Forumun kendi ileti numarası
+	Author     string              `json:"author"`
+	Content    string              `json:"content"`
+	Date       string              `json:"date"`
+	Reactions  string              `json:"reactions"`
+	LastEdited string              `json:"last_edited"`
+	Quotes     []QuoteData         `json:"quotes"` // İçerikten ayrılan alıntı blokları
+	Tags       []models.ContentTag `json:"tags"`   // İçerikte eşleşen keyword'ler
+}
+
+type QuoteData struct {
+	Author  string `json:"author"`
+	Content string `json:"content"`
+}
+
+// ModelQuotes, alıntıları veritabanı modeline çevirir.
+func (p PostData) ModelQuotes() []models.PostQuote {
+	quotes := make([]models.PostQuote, 0, len(p.Quotes))
+	for _, q := range p.Quotes {
+		quotes = append(quotes, models.PostQuote{Author: q.Author, Content: q.Content})
+	}
+	return quotes
+}
+
+// Motor ayarlarının varsayılanları (EngineOptions alanı boş bırakıldığında)
+const (
+	DefaultRequestTimeout = 15 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultRetryDelay     = 2 * time.Second
+	DefaultContentLimit   = 2000 // Karakter
+)
+
+// EngineOptions, istek zaman aşımı, yeniden deneme ve içerik kırpma sınırlarını belirler.
+type EngineOptions struct {
+	RequestTimeout time.Duration
+	Retry          RetryPolicy
+	ContentLimit   int // Ayrıştırılamayan sayfalardan alınan ham metnin karakter sınırı
+}
+
+// Normalize, boş veya geçersiz değerlere varsayılanları atar.
+func (o EngineOptions) Normalize() EngineOptions {
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = DefaultRequestTimeout
+	}
+	o.Retry = o.Retry.Normalize()
+	if o.ContentLimit <= 0 {
+		o.ContentLimit = DefaultContentLimit
+	}
+	return o
+}
+
+// ScanOptions, bir taramanın proxy, sınıflandırma ve ayrıştırma ayarlarını toplar.
+type ScanOptions struct {
+	Route        string      // Bağlantı yolu adı (direct, tor, i2p veya adlandırılmış proxy), olaylarda gösterilir
+	Proxy        string      // Sabit SOCKS5/HTTP proxy (boşsa doğrudan); Proxies verilmişse her deneme havuzdan alır
+	Proxies      ProxySource // Varsa her deneme için ayrı proxy/Tor devresi alınır
+	IsolationKey string      // Aynı taramanın denemelerini gruplayan SOCKS kullanıcı adı eki
+	Engine       EngineOptions
+	Keywords     []models.Keyword
+	UserAgents   []string                  // Boş değilse rastgele biri seçilir
+	Crawl        CrawlOptions              // Crawl.Enabled false ise sadece hedef sayfa ayrıştırılır
+	Profile      *models.ExtractionProfile // Varsa motor ayrıştırıcılarından önce denenir
+	OnEvent      func(ScanEvent)           // Varsa tarama olayları anlık olarak iletilir
+	OnAttempt    func(AttemptRecord)       // Varsa her denemenin sonucu iletilir
+	Transport    http.RoundTripper         // Varsa istekler ağa değil buna gider (arşivden çevrimdışı ayrıştırma)
 }
 
 // AnalyzeSite, hedef siteyi tarar ve sonuçları döndürür.
-func AnalyzeSite(targetURL string, torProxy string, keywords []models.Keyword, userAgents []string) (*ScrapeResult, error) {
+// Başarısız denemeler Engine.Retry politikasına göre artan beklemelerle tekrarlanır.
+// Tarama başarısız olursa hatayla birlikte son denemenin kısmi sonucu da döner (en azından alınan
+// yanıtların üst verisi; 403 veya CAPTCHA sayfası gibi). Bağlantı hiç kurulamadıysa sonuç nil olabilir.
+// ctx iptal edilirse devam eden istek kesilir ve ctx.Err() döner.
+func AnalyzeSite(ctx context.Context, targetURL string, opts ScanOptions) (*ScrapeResult, error) {
 	var result *ScrapeResult
 	var err error
-	maxRetries := 3
-
-	for i := 0; i < maxRetries; i++ {
-		// Loglama: Deneme sayısı
-		if i > 0 {
-			msg := fmt.Sprintf("Yeniden deneniyor (%d/%d): %s", i+1, maxRetries, targetURL)
-			log.Println(msg)
-			time.Sleep(2 * time.Second) // Bekleme süresi
+	opts.Crawl = opts.Crawl.Normalize()
+	opts.Engine = opts.Engine.Normalize()
+	policy := opts.Engine.Retry
+
+	var lease ProxyLease
+	leased := false
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		var backoff time.Duration
+		if attempt > 1 {
+			backoff = policy.Backoff(attempt - 1)
+			msg := fmt.Sprintf("Yeniden deneniyor (%d/%d, %s bekleme): %s", attempt, policy.MaxAttempts, backoff.Round(time.Millisecond), targetURL)
+			opts.emit(EventRetry, targetURL, msg, map[string]interface{}{"attempt": attempt, "max_retries": policy.MaxAttempts, "backoff_ms": backoff.Milliseconds()})
+
+			// Bekleme süresi (iptal edilirse beklemeden çık)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
 		} else {
-			log.Printf("Bağlantı başlatılıyor: %s", targetURL)
+			opts.emit(EventConnect, targetURL, "Bağlantı başlatılıyor: "+targetURL, nil)
 		}
 
-		result, err = performScan(targetURL, torProxy, keywords, userAgents)
+		// Devre değiştirme kapalıysa aynı uç nokta ve SOCKS kimliğiyle (aynı Tor devresi) tekrar denenir.
+		// Proxy'ye hiç ulaşılamadıysa her durumda başka uç nokta alınır.
+		current := opts
+		if opts.Proxies != nil {
+			if !leased || policy.SwitchCircuit || errors.Is(err, ErrProxyUnavailable) {
+				lease, err = opts.Proxies.Acquire(opts.IsolationKey)
+				if err != nil {
+					opts.emit(EventError, targetURL, fmt.Sprintf("Kritik Hata: %v", err), nil)
+					return result, err
+				}
+				leased = true
+			}
+			current.Proxy = lease.Proxy
+		}
 
-		// Başarılıysa veya kritik olmayan bir hata varsa dön
+		start := time.Now()
+		result, err = performScan(ctx, targetURL, current)
+		if opts.Proxies != nil {
+			opts.Proxies.Release(lease, err)
+		}
+		opts.recordAttempt(attempt, current.Proxy, start, backoff, err)
+
+		// Başarılıysa veya yeniden denenemeyecek bir hata varsa dön
 		if err == nil {
 			return result, nil
 		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "geçersiz") || strings.Contains(errMsg, "desteklenmeyen") ||
-			strings.Contains(errMsg, "timeout") || strings.Contains(errMsg, "zaman aşımı") ||
-			strings.Contains(errMsg, "unreachable") || strings.Contains(errMsg, "connection refused") ||
-			strings.Contains(errMsg, "no such host") {
-			log.Printf("Kritik Hata: %v", err)
-			return nil, err
+		se := AsScanError(err)
+		// Bildirilen işaret taramanın gerçekte yaptığıyla aynı olsun (politika timeout'u tekrarlayabilir, 503'ü tekrarlamayabilir)
+		if retry := policy.ShouldRetry(se); se.Retryable != retry {
+			c := *se
+			c.Retryable = retry
+			se = &c
 		}
+		err = se
+		errData := map[string]interface{}{"code": se.Code, "retryable": se.Retryable}
+		if !se.Retryable {
+			opts.emit(EventError, targetURL, fmt.Sprintf("Kritik Hata: %v", se), errData)
+			return result, se
+		}
+		opts.emit(EventError, targetURL, fmt.Sprintf("Hata alındı: %v", se), errData)
 
-		log.Printf("Hata alındı: %v. Bekleniyor...", err)
+		if policy.SwitchCircuit && opts.Proxies != nil && attempt < policy.MaxAttempts {
+			opts.Proxies.Rotate(lease)
+		}
 	}
 
-	return nil, fmt.Errorf("Maksimum deneme sayısına ulaşıldı. Son hata: %v", err)
+	return result, fmt.Errorf("Maksimum deneme sayısına ulaşıldı (%d). Son hata: %w", policy.MaxAttempts, err)
+}
+
+// recordAttempt, denemenin süresini ve hatasını OnAttempt'e iletir.
+func (o ScanOptions) recordAttempt(attempt int, proxy string, start time.Time, backoff time.Duration, err error) {
+	if o.OnAttempt == nil {
+		return
+	}
+	rec := AttemptRecord{
+		Attempt:   attempt,
+		StartedAt: start,
+		Duration:  time.Since(start).Seconds(),
+		Backoff:   backoff.Seconds(),
+	}
+	if proxy != "" {
+		rec.Proxy = ProxyLabel(proxy)
+	}
+	if se := AsScanError(err); se != nil {
+		rec.Code = se.Code
+		rec.Status = se.Status
+		rec.Error = se.Detail()
+	}
+	o.OnAttempt(rec)
 }
 
-func performScan(targetURL string, torProxy string, keywords []models.Keyword, userAgents []string) (*ScrapeResult, error) {
-	c := colly.NewCollector(
-		colly.AllowURLRevisit(),
-	)
+func performScan(ctx context.Context, targetURL string, opts ScanOptions) (*ScrapeResult, error) {
+	matcher, crawl := NewKeywordMatcher(opts.Keywords), opts.Crawl
+	c := colly.NewCollector(colly.StdlibContext(ctx))
+
+	if crawl.Enabled {
+		// Crawl modunda aynı sayfaya tekrar gitmek döngüye yol açar
+		c.MaxDepth = crawl.MaxDepth + 1 // Kök sayfa derinlik 1'dir
+		c.MaxRequests = uint32(crawl.MaxPages)
+		if !crawl.AllowExternal {
+			if u, err := url.Parse(targetURL); err == nil {
+				c.AllowedDomains = []string{u.Hostname()}
+			}
+		}
+	} else {
+		c.AllowURLRevisit = true
+	}
 
 	// Zaman aşımını ayarla
-	c.SetRequestTimeout(15 * time.Second)
+	c.SetRequestTimeout(opts.Engine.RequestTimeout)
 
 	// User Agent Ayarla
 	c.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
 
-	if len(userAgents) > 0 {
+	if len(opts.UserAgents) > 0 {
 		rand.Seed(time.Now().UnixNano())
-		c.UserAgent = userAgents[rand.Intn(len(userAgents))]
-		log.Printf("Random UA Seçildi: %s", c.UserAgent)
+		c.UserAgent = opts.UserAgents[rand.Intn(len(opts.UserAgents))]
+		opts.emit(EventUserAgent, targetURL, "Random UA Seçildi: "+c.UserAgent, map[string]interface{}{"user_agent": c.UserAgent})
 	}
 
 	// Proxy Yapılandır
-	if torProxy != "" {
-		rp, err := proxy.RoundRobinProxySwitcher(torProxy)
+	if opts.Transport != nil {
+		c.WithTransport(opts.Transport)
+		opts.emit(EventProxy, targetURL, "Çevrimdışı: sayfalar arşivden okunuyor", map[string]interface{}{"route": "offline"})
+	} else if opts.Proxy != "" {
+		rp, err := proxy.RoundRobinProxySwitcher(opts.Proxy)
 		if err != nil {
 			return nil, fmt.Errorf("proxy error: %v", err)
 		}
 		c.SetProxyFunc(rp)
+		// Denemeye özel SOCKS kimlik bilgisi olay akışına yazılmaz
+		label := ProxyLabel(opts.Proxy)
+		opts.emit(EventProxy, targetURL, "Proxy kullanılıyor: "+label, map[string]interface{}{"route": opts.Route, "proxy": label, "isolated": opts.Proxies != nil && label != opts.Proxy})
+	} else {
+		opts.emit(EventProxy, targetURL, "Doğrudan bağlantı (proxy yok)", map[string]interface{}{"route": RouteDirect})
 	}
 
 	result := &ScrapeResult{
 		URL: targetURL,
 	}
+	state := newCrawlState()
+	recorder := newResponseRecorder()
+	c.SetRedirectHandler(recorder.redirect)
+	var fallback *ThreadData
+	var rootErr *ScanError // Kök sayfanın hatası (alt sayfa hataları taramayı bozmaz)
+	captcha := false
+
+	// Kullanıcı profili varsa motor ayrıştırıcılarından önce denenir
+	parsers := parsersWithLimit(opts.Engine.ContentLimit)
+	if opts.Profile != nil {
+		parsers = append([]Parser{NewProfileParser(*opts.Profile)}, parsers...)
+	}
 
-	// Başlık Çekme
-	c.OnHTML("title", func(e *colly.HTMLElement) {
-		if result.Title == "" {
-			result.Title = strings.TrimSpace(e.Text)
-		}
+	c.OnRequest(func(r *colly.Request) {
+		recorder.start(r)
+		opts.emit(EventPage, r.URL.String(), fmt.Sprintf("Sayfa isteniyor: %s (derinlik %d)", r.URL, r.Depth),
+			map[string]interface{}{"depth": r.Depth})
 	})
 
-	c.OnHTML("h1", func(e *colly.HTMLElement) {
-		// h1 varsa öncelik ver veya title boşsa doldur
-		h1 := strings.TrimSpace(e.Text)
-		if h1 != "" {
-			result.Title = h1
-		}
-	})
-
-	c.OnHTML(".p-title-value, .ipbType_sectionTitle", func(e *colly.HTMLElement) {
-		// Forum özel başlık sınıfları
-		title := strings.TrimSpace(e.Text)
-		if title != "" {
-			result.Title = title
-		}
+	c.OnResponse(func(r *colly.Response) {
+		result.Responses = append(result.Responses, recorder.finish(r))
 	})
 
 	c.OnHTML("html", func(e *colly.HTMLElement) {
-		// --- Forum Tespiti ---
-		detectionScore := 0
-
-		// 1. Meta Etiketlerini Kontrol Et
-		e.DOM.Find("meta[name='generator']").Each(func(i int, s *goquery.Selection) {
-			content, exists := s.Attr("content")
-			if exists {
-				content = strings.ToLower(content)
-				if strings.Contains(content, "vbulletin") || strings.Contains(content, "xenforo") ||
-					strings.Contains(content, "mybb") || strings.Contains(content, "phpbb") ||
-					strings.Contains(content, "fluxbb") || strings.Contains(content, "smf") ||
-					strings.Contains(content, "discuz") || strings.Contains(content, "nodebb") {
-					detectionScore += 10
-				}
-			}
-		})
-
-		// 2. URL Yapısını Analiz Et
-		urlLower := strings.ToLower(e.Request.URL.String())
-		if strings.Contains(urlLower, "thread") || strings.Contains(urlLower, "topic") ||
-			strings.Contains(urlLower, "showthread") || strings.Contains(urlLower, "viewtopic") ||
-			strings.Contains(urlLower, "board") || strings.Contains(urlLower, "forums") {
-			detectionScore += 5
-		}
+		page := parsePage(e, parsers, opts.Engine.ContentLimit)
+		pageURL := canonicalURL(e.Request.URL.String())
+		isRoot := e.Request.Depth <= 1
 
-		// 3. İçerik ve Anahtar Kelime Analizi
-		text := strings.ToLower(e.Text)
-		forumKeywords := []string{
-			"thread", "post", "topic", "forum", "vbulletin", "xenforo",
-			"phpbb", "mybb", "discussion", "board", "kategori", "başlık",
-			"cevap", "reply", "quote", "alıntı", "last post", "son mesaj",
-			"started by", "gönderen", "registered", "kayıtlı",
+		if len(page.Posts) > 0 || len(page.Rows) > 0 {
+			opts.emit(EventPosts, pageURL, fmt.Sprintf("Sayfa ayrıştırıldı (%s): %d ileti, %d konu satırı", page.Engine, len(page.Posts), len(page.Rows)),
+				map[string]interface{}{"engine": page.Engine, "posts": len(page.Posts), "rows": len(page.Rows)})
 		}
 
-		for _, kw := range forumKeywords {
-			if strings.Contains(text, kw) {
-				detectionScore += 1
-			}
+		if isRoot {
+			result.Title = page.Title
+			result.Engine = page.Engine
+			captcha = page.Captcha
 		}
-
-		// 4. DOM Yapısal Analizi
-		if e.DOM.Find(".thread, .topic, .row, .threadbit, .windowbg").Length() > 0 {
-			detectionScore += 3
-		}
-		if e.DOM.Find(".post, .message, .entry, .postbit, .post_block").Length() > 0 {
-			detectionScore += 3
-		}
-		if e.DOM.Find(".pagination, .pagenav, .pages").Length() > 0 {
-			detectionScore += 2
-		}
-		if e.DOM.Find(".breadcrumb, .navbit").Length() > 0 {
-			detectionScore += 2
+		if page.IsForum {
+			result.IsForum = true
 		}
 
-		// Meta tag varsa direkt kabul et, yoksa diğer işaretlerin toplamına bak
-		if detectionScore >= 5 {
+		// 1. İleti içeren sayfa: Konu olarak kaydet
+		if len(page.Posts) > 0 {
 			result.IsForum = true
-		}
 
-		// 1. İletileri Tespit Et
-		var posts []PostData
+			idx, ok := state.owners[pageURL]
+			if !ok {
+				// Sayfa başlığı konu başlığıdır
+				title := page.Title
+				if isRoot {
+					title = result.Title
+				}
+				link := pageURL
+				if isRoot {
+					link = result.URL
+				}
+				result.Threads = append(result.Threads, ThreadData{Title: title, Link: link})
+				idx = len(result.Threads) - 1
+				state.owners[pageURL] = idx
+			}
 
-		// Yaygın forum yazılımlarının kullandığı kapsayıcı sınıflar
-		postSelectors := []string{
-			".post", ".message", ".entry", "article", ".comment", ".post-container", "div[id^='post']",
-			".postbit", ".post-content", ".message-content", ".post_body", ".entry-content",
-			".ItemBody", ".CommentBody", ".lia-message-body-content", ".js-post__content-text",
-			".cooked", ".topic-body", ".post-message", ".post_wrapper", ".post_block",
-			"table.post", "div.post", "td.post_content",
-		}
+			for i := range page.Posts {
+				page.Posts[i].Tags = matcher.Match(page.Posts[i].Content)
+			}
 
-		// Seçicileri dene
-		for _, selector := range postSelectors {
-			e.DOM.Find(selector).Each(func(i int, s *goquery.Selection) {
-				// --- Meta Verileri Çek ---
-
-				// Meta Verileri Çek
-				lastEdited := strings.TrimSpace(s.Find(".message-lastEdit, .post-edit, .edited-by").Text())
-				lastEdited = strings.TrimSpace(strings.ReplaceAll(lastEdited, "\n", " "))
-
-				// --- İçerik Temizliği ---
-
-				// İçerik
-				contentSel := s.Find(".content, .message, .body, .text, .entry-content, .post_body, .post_content, .posttext, .post-text, .messageText, .uu_post")
-
-				// Gereksiz etiketleri temizle
-				contentSel.Find(`
-					script, style, button, isindex,
-					.footer, .signature, .kutu, 
-					.message-cell--user, .message-userInfo, .post-sidebar, .postprofile, .user-details, .post-left, .user_info, .author_info,
-					.message-userExtras, .message-avatar-wrapper, .message-userTitle, .message-userBanner,
-					.bbCodeBlock-expandLink, .attribution,
-					.reaction-bar, .reactions, .message-attribution, .message-footer, .message-lastEdit, .privateControls, .publicControls,
-					.post_head, .post-head, .node-controls, .post-date, .date, .permalink, .post-number,
-					dl.pairs
-				`).Remove()
-
-				// Metin temizliği
-				content := strings.TrimSpace(contentSel.Text())
-				content = strings.ReplaceAll(content, "Click to expand...", "")
-				content = strings.ReplaceAll(content, "Tıkla ve genişlet...", "")
-
-				// Eğer özel içerik seçicisi işe yaramazsa (veya yanlışlıkla her şeyi sildiyse) ana konteynerden al
-				if content == "" {
-					// Ana konteynerin textini al ama temizleyerek
-					clone := s.Clone()
-					// Buradaki remove listesi de aynı olmalı
-					clone.Find(`
-						script, style, button, 
-						.footer, .signature, .user_info, .author_info, .post_head, .post-head,
-						.message-cell--user, .message-userInfo, .postprofile,
-						.message-attribution, .message-footer, .message-lastEdit, .reaction-bar
-					`).Remove()
-
-					content = strings.TrimSpace(clone.Text())
-					content = strings.ReplaceAll(content, "Click to expand...", "")
-
-					if len(content) > 2000 {
-						content = content[:2000] + "..."
-					}
-				}
+			thread := &result.Threads[idx]
+			if len(thread.Posts) == 0 {
+				// İlk postu başlatan kişi olarak alabiliriz, ilk post ana içeriktir
+				thread.Author = page.Posts[0].Author
+				thread.Date = page.Posts[0].Date
+				thread.Content = page.Posts[0].Content
+				thread.Tags = matcher.Match(thread.Title)
+				thread.Category = BestCategory(thread.Tags, page.Posts[0].Tags)
+			}
+			thread.Posts = append(thread.Posts, page.Posts...)
 
-				// Çok kısa içerikleri yoksay (gürültü önleme)
-				if len(content) < 3 {
-					return
+			// Konunun sonraki sayfası aynı konuya eklenir
+			if crawl.Enabled && page.NextPage != "" {
+				if _, seen := state.owners[page.NextPage]; !seen {
+					state.owners[page.NextPage] = idx
+					e.Request.Visit(page.NextPage)
 				}
+			}
+			return
+		}
 
-				// Yazar
-				author := strings.TrimSpace(s.Find(".author, .user, .username, .name, a[href*='user'], .poster, .user-details, .popupctrl, .mem_profile").First().Text())
-				if author == "" {
-					author = strings.TrimSpace(s.Find(".user_info, .author_info, .post_author").First().Text())
-				}
-				if author == "" {
-					author = "Anonymous"
-				}
+		if !result.IsForum {
+			return
+		}
 
-				// Tarih
-				date := strings.TrimSpace(s.Find(".date, .time, time, .timestamp, .published, .post-date, .date-header, .post_date").First().Text())
-				if date == "" {
-					// Başlık veya meta kısımlarında tarih arayalım
-					date = strings.TrimSpace(s.Find(".post_head, .post-head, .thead").Text())
-				}
-				if date == "" {
-					date = time.Now().Format("2006-01-02 15:04")
-				}
+		// 2. Konu listesi ayrıştırması
+		for _, row := range page.Rows {
+			// Bağlantısı olmayan satır boş bağlantıyla kalır; sayfa adresi verilirse kayıtta tüm bu satırlar tek konuya birleşir
+			if _, seen := state.owners[row.Link]; row.Link != "" && seen {
+				continue
+			}
 
-				posts = append(posts, PostData{
-					Author:     cleanText(author),
-					Content:    cleanText(content), // Temizleme fonksiyonu kullan
-					Date:       cleanText(date),
-					Reactions:  "",
-					LastEdited: lastEdited,
-				})
+			author := row.Author
+			if author == "" {
+				author = "Unknown"
+			}
+			date := row.Date
+			if date == "" {
+				date = time.Now().Format("2006-01-02")
+			}
+
+			tags := matcher.Match(row.Title)
+			result.Threads = append(result.Threads, ThreadData{
+				ThreadID: row.ThreadID,
+				Title:    row.Title,
+				Link:     row.Link,
+				Author:   author,
+				Date:     date,
+				Category: BestCategory(tags),
+				Tags:     tags,
+				Replies:  row.Replies,
+				Views:    row.Views,
 			})
 
-			// Eğer post bulduysak ve yeterli sayıdaysa (false pozitifleri önlemek için)
-			if len(posts) > 0 {
-				break
+			if crawl.Enabled && row.Link != "" {
+				state.owners[row.Link] = len(result.Threads) - 1
+				e.Request.Visit(row.Link)
 			}
 		}
 
-		// 2. Thread Yapısını Oluştur
-		// Eğer postlar bulunduysa, bu sayfayı bir "Konu" olarak kabul et
-		if len(posts) > 0 {
-			result.IsForum = true
-			result.PostCount = len(posts)
-
-			// İlk postu başlatan kişi olarak alabiliriz
-			threadAuthor := posts[0].Author
-			threadDate := posts[0].Date
-			threadContent := posts[0].Content // İlk post ana içeriktir
-
-			result.Threads = []ThreadData{
-				{
-					Title:    result.Title, // Sayfa başlığı konu başlığıdır
-					Link:     result.URL,
-					Author:   threadAuthor,
-					Date:     threadDate,
-					Content:  threadContent,
-					Category: detectCategory(threadContent+" "+result.Title, keywords),
-					Posts:    posts, // Tüm postları ekle
-				},
-			}
-			result.ThreadCount = 1
-		} else {
-			// Eğer post bulunamadıysa ama "Forum" tespit edildiyse (keywordlerle)
-			if result.IsForum {
-				// Konu listesi ayrıştırması
-				e.DOM.Find(".thread, .topic, .row").Each(func(i int, s *goquery.Selection) {
-					title := strings.TrimSpace(s.Find(".title, .subject, h3, a").First().Text())
-					if title != "" {
-						result.Threads = append(result.Threads, ThreadData{
-							Title:    title,
-							Link:     result.URL,
-							Author:   "Unknown",
-							Date:     time.Now().Format("2006-01-02"),
-							Category: detectCategory(title, keywords),
-						})
-					}
-				})
-				result.ThreadCount = len(result.Threads)
-				if result.ThreadCount == 0 {
-					// Fallback: Eğer hiçbir yapısal veri bulunamazsa, sayfayı tek bir konu gibi kaydet
-					// Böylece kullanıcı en azından metin içeriğini görebilir.
-
-					// Tüm metni al
-					rawContent := strings.TrimSpace(e.DOM.Find("body").Text())
-					if len(rawContent) > 2000 {
-						rawContent = rawContent[:2000] + "... (devamı kırpıldı)"
-					}
-
-					result.Threads = []ThreadData{
-						{
-							Title:    result.Title,
-							Link:     result.URL,
-							Author:   "System (Fallback)",
-							Date:     time.Now().Format("2006-01-02 15:04"),
-							Content:  "Otomatik ayrıştırma başarısız oldu. Ham içerik:\n\n" + rawContent,
-							Category: detectCategory(result.Title, keywords),
-							Posts:    []PostData{},
-						},
-					}
-					result.ThreadCount = 1
-					result.PostCount = 0
-				}
+		if crawl.Enabled && page.NextPage != "" {
+			e.Request.Visit(page.NextPage)
+		}
+
+		if isRoot && len(page.Rows) == 0 {
+			// Fallback: Eğer hiçbir yapısal veri bulunamazsa, sayfayı tek bir konu gibi kaydet
+			// Böylece kullanıcı en azından metin içeriğini görebilir.
+			tags := matcher.Match(result.Title)
+			fallback = &ThreadData{
+				Title:    result.Title,
+				Link:     result.URL,
+				Author:   "System (Fallback)",
+				Date:     time.Now().Format("2006-01-02 15:04"),
+				Content:  "Otomatik ayrıştırma başarısız oldu. Ham içerik:\n\n" + page.RawContent,
+				Category: BestCategory(tags),
+				Tags:     tags,
+				Posts:    []PostData{},
 			}
 		}
 	})
 
 	// Hata yönetimi
 	c.OnError(func(r *colly.Response, err error) {
-		log.Printf("Request URL: %s failed with %v", r.Request.URL, err)
-		errMsg := err.Error()
-
-		// SOCKS Hata Kodları Analizi
-		if strings.Contains(errMsg, "unknown code: 246") || strings.Contains(errMsg, "0xF6") {
-			errMsg = "Onion adresi geçersiz veya desteklenmeyen formatta (V2)."
-		} else if strings.Contains(errMsg, "timeout") {
-			errMsg = "Bağlantı zaman aşımına uğradı. Site çok yavaş veya kapalı."
-		} else if strings.Contains(errMsg, "host unreachable") {
-			errMsg = "Hedef sunucuya ulaşılamıyor."
-		} else if strings.Contains(errMsg, "connection refused") {
-			errMsg = "Bağlantı reddedildi. Tor proxy çalışmıyor olabilir."
-		} else if strings.Contains(errMsg, "EOF") {
-			errMsg = "Sunucu bağlantıyı kesti (Boş Yanıt). Site çevrimdışı veya Tor devresi koptu."
+		opts.emit(EventError, r.Request.URL.String(), fmt.Sprintf("Request URL: %s failed with %v", r.Request.URL, err),
+			map[string]interface{}{"depth": r.Request.Depth})
+		if r.StatusCode != 0 {
+			result.Responses = append(result.Responses, recorder.finish(r))
 		}
 
-		result.ErrorMessage = errMsg
+		// Alt sayfalardaki hatalar taramayı başarısız saymaz
+		if r.Request.Depth > 1 {
+			return
+		}
+
+		// Yanıt geldiyse hata durum kodundandır (colly 2xx dışını hata sayar); bot koruması ara sayfası CAPTCHA sayılır
+		switch {
+		case isChallengeResponse(r.StatusCode, r.Body):
+			rootErr = ErrCaptcha.wrap(err)
+			rootErr.Status = r.StatusCode
+		case r.StatusCode != 0:
+			rootErr = NewHTTPStatusError(r.StatusCode)
+			rootErr.Err = err
+		default:
+			rootErr = ClassifyError(err)
+		}
+		result.ErrorMessage = rootErr.Message
 	})
 
-	err := c.Visit(targetURL)
-	if err != nil {
-		// Eğer OnError içinde daha anlamlı bir hata mesajı varsa onu döndür
-		if result.ErrorMessage != "" {
-			return nil, fmt.Errorf("%s", result.ErrorMessage)
+	// Hata durumunda da alınan yanıtların üst verisi kaybolmasın diye kısmi sonuç döndürülür
+	if err := c.Visit(targetURL); err != nil {
+		// OnError içinde sınıflandırılmış hata varsa onu döndür
+		if rootErr != nil {
+			return result, rootErr
 		}
-		return nil, err
+		return result, ClassifyError(err)
+	}
+
+	// Forum olmayan CAPTCHA sayfası hata sayılır, kaydedilmez
+	if captcha && !result.IsForum {
+		return result, ErrCaptcha.wrap(nil)
+	}
+
+	if len(result.Threads) == 0 && fallback != nil {
+		result.Threads = []ThreadData{*fallback}
+	}
+
+	// Sayaçları tüm sayfalar üzerinden hesapla
+	result.ThreadCount = len(result.Threads)
+	result.PostCount = 0
+	for _, t := range result.Threads {
+		result.PostCount += len(t.Posts)
 	}
 
 	result.UserAgent = c.UserAgent
 	return result, nil
 }
 
-// GetActiveTorProxy, sistemde çalışan Tor bağlantısını (9050 veya 9150) tespit eder.
-func GetActiveTorProxy() (string, error) {
-	// Docker için host adreslerini de kontrol et
-	addresses := []string{
-		"127.0.0.1:9050", "127.0.0.1:9150",
-		"host.docker.internal:9050", "host.docker.internal:9150",
+// pageData, tek bir sayfadan çıkarılan verileri taşır.
+type pageData struct {
+	ParsedPage
+	Engine     string // Sayfayı ayrıştıran motor (örn: "phpbb", "generic")
+	IsForum    bool
+	Captcha    bool   // Sayfa CAPTCHA/bot koruması gibi görünüyor
+	RawContent string // Fallback için kırpılmış ham metin
+}
+
+// parsePage, sayfanın forum olup olmadığını tespit eder ve iletileri/konu satırlarını
+// sayfayı tanıyan ayrıştırıcıyla çıkarır.
+func parsePage(e *colly.HTMLElement, parsers []Parser, contentLimit int) pageData {
+	page := pageData{}
+
+	// --- Forum Tespiti ---
+	detectionScore := 0
+
+	// 1. Meta Etiketlerini Kontrol Et
+	e.DOM.Find("meta[name='generator']").Each(func(i int, s *goquery.Selection) {
+		content, exists := s.Attr("content")
+		if exists {
+			content = strings.ToLower(content)
+			if strings.Contains(content, "vbulletin") || strings.Contains(content, "xenforo") ||
+				strings.Contains(content, "mybb") || strings.Contains(content, "phpbb") ||
+				strings.Contains(content, "fluxbb") || strings.Contains(content, "smf") ||
+				strings.Contains(content, "discuz") || strings.Contains(content, "nodebb") {
+				detectionScore += 10
+			}
+		}
+	})
+
+	// 2. URL Yapısını Analiz Et
+	urlLower := strings.ToLower(e.Request.URL.String())
+	if strings.Contains(urlLower, "thread") || strings.Contains(urlLower, "topic") ||
+		strings.Contains(urlLower, "showthread") || strings.Contains(urlLower, "viewtopic") ||
+		strings.Contains(urlLower, "board") || strings.Contains(urlLower, "forums") {
+		detectionScore += 5
 	}
 
-	for _, address := range addresses {
-		conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
-		if err == nil {
-			conn.Close()
-			return "socks5://" + address, nil
+	// 3. İçerik ve Anahtar Kelime Analizi
+	text := strings.ToLower(e.Text)
+	forumKeywords := []string{
+		"thread", "post", "topic", "forum", "vbulletin", "xenforo",
+		"phpbb", "mybb", "discussion", "board", "kategori", "başlık",
+		"cevap", "reply", "quote", "alıntı", "last post", "son mesaj",
+		"started by", "gönderen", "registered", "kayıtlı",
+	}
+
+	for _, kw := range forumKeywords {
+		if strings.Contains(text, kw) {
+			detectionScore += 1
 		}
 	}
 
-	return "", fmt.Errorf("Sistem Tor (9050) veya Tor Browser (9150) açık değil. Lütfen Tor bağlantınızı kontrol edin.")
-}
+	// 4. DOM Yapısal Analizi
+	if e.DOM.Find(".thread, .topic, .row, .threadbit, .windowbg").Length() > 0 {
+		detectionScore += 3
+	}
+	if e.DOM.Find(".post, .message, .entry, .postbit, .post_block").Length() > 0 {
+		detectionScore += 3
+	}
+	if e.DOM.Find(".pagination, .pagenav, .pages").Length() > 0 {
+		detectionScore += 2
+	}
+	if e.DOM.Find(".breadcrumb, .navbit").Length() > 0 {
+		detectionScore += 2
+	}
 
-// IsProxyConnectionError, hatanın Tor proxy'sine bağlanamama ile ilgili olup olmadığını kontrol eder
-func IsProxyConnectionError(err error) bool {
-	if err == nil {
-		return false
+	// Meta tag varsa direkt kabul et, yoksa diğer işaretlerin toplamına bak
+	if detectionScore >= 5 {
+		page.IsForum = true
+	}
+
+	// İletileri, konu satırlarını ve sayfalamayı ayrıştır
+	page.ParsedPage, page.Engine = parseDocument(e.DOM, e.Request.URL, parsers)
+	if page.Title == "" {
+		page.Title = extractPageTitle(e.DOM)
+	}
+	if page.NextPage == "" {
+		page.NextPage = findNextPageLink(e.DOM, e.Request.URL)
+	}
+	if page.Engine != "" && page.Engine != "generic" {
+		// Motoru tanınan sayfa forumdur
+		page.IsForum = true
 	}
-	msg := strings.ToLower(err.Error())
-	// Proxy bağlantı reddedildi veya bulunamadı hataları
-	return strings.Contains(msg, "connection refused") ||
-		strings.Contains(msg, "proxyconnect tcp") ||
-		strings.Contains(msg, "dial tcp 127.0.0.1")
+
+	page.Captcha = detectCaptcha(e.DOM)
+
+	if len(page.Posts) == 0 {
+		// Tüm metni al
+		rawContent := strings.TrimSpace(e.DOM.Find("body").Text())
+		page.RawContent = truncateRunes(rawContent, contentLimit, "... (devamı kırpıldı)")
+	}
+
+	return page
 }
 
-// detectCategory: Metin içinde anahtar kelime tarayarak kategori belirler
-func detectCategory(text string, keywords []models.Keyword) string {
-	textLower := strings.ToLower(text)
+// captchaSelectors, CAPTCHA ve bot koruması ara sayfalarının öğeleridir: bilinen sağlayıcıların betik ve
+// iframe adresleri ile doğrulama form alanları. Sayfa metni bakılmaz; CAPTCHA'dan bahseden bir konu bu öğeleri içermez.
+const captchaSelectors = "script[src*='google.com/recaptcha'], script[src*='recaptcha.net'], script[src*='hcaptcha.com'], script[src*='challenges.cloudflare.com']," +
+	" iframe[src*='google.com/recaptcha'], iframe[src*='recaptcha.net'], iframe[src*='hcaptcha.com'], iframe[src*='challenges.cloudflare.com']," +
+	" .g-recaptcha, .h-captcha, .cf-turnstile, #challenge-form, #cf-challenge-running," +
+	" input[name*='captcha'], textarea[name='g-recaptcha-response'], textarea[name='h-captcha-response']"
+
+// challengePhrases, bot korumalarının hata durum koduyla döndürdüğü ara sayfalarda geçen ifadelerdir.
+var challengePhrases = []string{"captcha", "ddos protection", "checking your browser", "just a moment", "i am not a robot", "ben robot değilim"}
+
+// detectCaptcha, sayfanın CAPTCHA veya bot koruması ara sayfası olup olmadığını öğelerinden anlar.
+func detectCaptcha(doc *goquery.Selection) bool {
+	return doc.Find(captchaSelectors).Length() > 0
+}
 
-	// En uzun kelimeden başlayarak eşleşme ara
-	for _, kw := range keywords {
-		if strings.Contains(textLower, strings.ToLower(kw.Word)) {
-			return kw.Category
+// isChallengeResponse, 403/429/503 yanıtının bot koruması ara sayfası olup olmadığını söyler.
+// Bu durum kodlarıyla gelen sayfalarda metindeki ifadeler de yeterlidir.
+func isChallengeResponse(status int, body []byte) bool {
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+		return false
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	if detectCaptcha(doc.Selection) {
+		return true
+	}
+	text := strings.ToLower(doc.Text())
+	for _, phrase := range challengePhrases {
+		if strings.Contains(text, phrase) {
+			return true
 		}
 	}
-
-	return "Genel"
+	return false
 }
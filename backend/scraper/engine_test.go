@@ -0,0 +1,149 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// roundTripFunc, testlerde ağa çıkmadan yanıt veya hata üreten Transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestAnalyzeSiteReturnsResponsesOnFailure(t *testing.T) {
+	const target = "http://forum.test/"
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+	}{
+		{"yeniden denenmeyen 403", 403, 1},
+		{"denemeleri tüketen 503", 503, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := []SnapshotPage{{
+				URL:         target,
+				StatusCode:  tt.status,
+				ContentType: "text/html",
+				Body:        []byte("<html><body>Access denied</body></html>"),
+			}}
+			attempts := 0
+			result, err := AnalyzeSite(context.Background(), target, ScanOptions{
+				Engine:    EngineOptions{Retry: RetryPolicy{MaxAttempts: 2, BaseBackoff: 1, RetryOn: []string{CodeHTTPStatus}}},
+				Transport: NewOfflineTransport(pages),
+				OnAttempt: func(AttemptRecord) { attempts++ },
+			})
+
+			var se *ScanError
+			if !errors.As(err, &se) || se.Code != CodeHTTPStatus || se.Status != tt.status {
+				t.Fatalf("HTTP %d hatası bekleniyordu, %v", tt.status, err)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("%d deneme yapıldı, beklenen %d", attempts, tt.attempts)
+			}
+			if result == nil || len(result.Responses) != 1 {
+				t.Fatalf("hatayla birlikte son denemenin yanıtı dönmeliydi: %+v", result)
+			}
+			if r := result.Responses[0]; r.StatusCode != tt.status || r.URL != target || len(r.Body) == 0 {
+				t.Errorf("yanıt üst verisi eksik: %+v", r)
+			}
+		})
+	}
+}
+
+func TestReportedRetryableFollowsPolicy(t *testing.T) {
+	const target = "http://forum.test/"
+	timeout := roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, context.DeadlineExceeded })
+	unavailable := NewOfflineTransport([]SnapshotPage{{URL: target, StatusCode: 503, ContentType: "text/html", Body: []byte("<html></html>")}})
+
+	tests := []struct {
+		name      string
+		transport http.RoundTripper
+		retryOn   []string
+		code      string
+		retryable bool
+		attempts  int
+	}{
+		{"varsayılan politika zaman aşımını tekrarlar", timeout, nil, CodeTimeout, true, 2},
+		{"listede olmayan zaman aşımı", timeout, []string{CodeHTTPStatus}, CodeTimeout, false, 1},
+		{"listede olmayan 503", unavailable, []string{CodeTimeout}, CodeHTTPStatus, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			var reported []interface{}
+			_, err := AnalyzeSite(context.Background(), target, ScanOptions{
+				Engine:    EngineOptions{Retry: RetryPolicy{MaxAttempts: 2, BaseBackoff: 1, RetryOn: tt.retryOn}},
+				Transport: tt.transport,
+				OnAttempt: func(AttemptRecord) { attempts++ },
+				OnEvent: func(ev ScanEvent) {
+					if ev.Type == EventError && ev.Data["code"] != nil {
+						reported = append(reported, ev.Data["retryable"])
+					}
+				},
+			})
+
+			var se *ScanError
+			if !errors.As(err, &se) || se.Code != tt.code {
+				t.Fatalf("%s hatası bekleniyordu, %v", tt.code, err)
+			}
+			if se.Retryable != tt.retryable {
+				t.Errorf("dönen hata retryable=%v, beklenen %v", se.Retryable, tt.retryable)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("%d deneme yapıldı, beklenen %d", attempts, tt.attempts)
+			}
+			if len(reported) != tt.attempts {
+				t.Fatalf("%d hata olayı, beklenen %d", len(reported), tt.attempts)
+			}
+			for _, r := range reported {
+				if r != tt.retryable {
+					t.Errorf("olayda retryable=%v, beklenen %v", r, tt.retryable)
+				}
+			}
+		})
+	}
+}
+
+func TestCaptchaDetection(t *testing.T) {
+	const target = "http://forum.test/"
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		code   string // Boşsa tarama başarılı olmalı
+	}{
+		{"captcha'dan bahseden sayfa", 200, `<html><body><h1>Captcha çözücü önerisi</h1><p>Hangi captcha servisini kullanıyorsunuz?</p></body></html>`, ""},
+		{"reCAPTCHA formu", 200, `<html><head><script src="https://www.google.com/recaptcha/api.js"></script></head><body><form><div class="g-recaptcha" data-sitekey="x"></div></form></body></html>`, CodeCaptcha},
+		{"doğrulama alanı", 200, `<html><body><form><img src="/kod.php"><input name="captcha_code"></form></body></html>`, CodeCaptcha},
+		{"503 tarayıcı kontrolü", 503, `<html><body><h1>Just a moment...</h1><p>Checking your browser before accessing forum.test.</p></body></html>`, CodeCaptcha},
+		{"403 düz hata", 403, `<html><body>Access denied</body></html>`, CodeHTTPStatus},
+		{"404 captcha metni", 404, `<html><body>Sayfa yok. Captcha konusu taşındı.</body></html>`, CodeHTTPStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := []SnapshotPage{{URL: target, StatusCode: tt.status, ContentType: "text/html", Body: []byte(tt.body)}}
+			_, err := AnalyzeSite(context.Background(), target, ScanOptions{
+				Engine:    EngineOptions{Retry: RetryPolicy{MaxAttempts: 1}},
+				Transport: NewOfflineTransport(pages),
+			})
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("tarama başarılı olmalıydı: %v", err)
+				}
+				return
+			}
+			var se *ScanError
+			if !errors.As(err, &se) || se.Code != tt.code {
+				t.Fatalf("%s hatası bekleniyordu, %v", tt.code, err)
+			}
+			if tt.status != 200 && se.Status != tt.status {
+				t.Errorf("durum kodu %d, beklenen %d", se.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// Tarama hata kodları. API yanıtlarında, sistem loglarında ve watchlist sayaçlarında aynen kullanılır.
+const (
+	CodeProxyUnavailable  = "proxy_unavailable"  // Tor/upstream proxy'ye bağlanılamadı
+	CodeOnionInvalid      = "onion_invalid"      // Tor 0xF6: geçersiz veya V2 onion adresi
+	CodeTimeout           = "timeout"            // İstek zaman aşımı
+	CodeHostUnreachable   = "host_unreachable"   // Hedefe devre kurulamadı
+	CodeConnectionRefused = "connection_refused" // Hedef bağlantıyı reddetti
+	CodeConnectionClosed  = "connection_closed"  // Sunucu boş yanıtla bağlantıyı kesti
+	CodeDNS               = "dns"                // Alan adı çözümlenemedi
+	CodeHTTPStatus        = "http_status"        // 2xx dışı HTTP yanıtı
+	CodeCaptcha           = "captcha"            // CAPTCHA veya bot koruması sayfası
+	CodeNotForum          = "not_forum"          // Sayfa forum yapısına uymuyor
+	CodeInvalidURL        = "invalid_url"
+	CodeCancelled         = "cancelled"
+	CodeUnknown           = "unknown"
+)
+
+// ScanError, taramanın neden başarısız olduğunu sabit bir kodla taşır.
+// Retryable, aynı hedefin yeni bir denemede başarılı olma ihtimalinin olup olmadığını söyler.
+// AnalyzeSite'ın döndürdüğü ve olaylarda bildirilen değer, taramanın yeniden deneme politikasının kararıdır.
+type ScanError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`          // Kullanıcıya gösterilen açıklama
+	Retryable bool   `json:"retryable"`        // Yeniden denenebilir mi
+	Status    int    `json:"status,omitempty"` // CodeHTTPStatus için HTTP durum kodu
+	Err       error  `json:"-"`                // Alttaki ağ/proxy hatası
+}
+
+func (e *ScanError) Error() string { return e.Message }
+
+func (e *ScanError) Unwrap() error { return e.Err }
+
+// Is, aynı koda sahip hataları eşit sayar (errors.Is(err, ErrTimeout) gibi).
+func (e *ScanError) Is(target error) bool {
+	t, ok := target.(*ScanError)
+	return ok && t.Code == e.Code
+}
+
+// Detail, mesaja alttaki hatayı da ekler (log için).
+func (e *ScanError) Detail() string {
+	if e.Err == nil || strings.Contains(e.Message, e.Err.Error()) {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%v)", e.Message, e.Err)
+}
+
+// HTTPStatus, hatanın API'de hangi durum koduyla döneceğini belirler.
+func (e *ScanError) HTTPStatus() int {
+	switch e.Code {
+	case CodeInvalidURL, CodeOnionInvalid:
+		return http.StatusBadRequest
+	case CodeProxyUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeTimeout:
+		return http.StatusGatewayTimeout
+	case CodeNotForum, CodeCaptcha:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadGateway
+}
+
+// Hata sınıfları. errors.Is ile kod karşılaştırması için kullanılır.
+var (
+	ErrProxyUnavailable  = &ScanError{Code: CodeProxyUnavailable, Message: "Proxy'ye bağlanılamadı. Tor veya yönlendirme proxy'sinin açık olduğundan emin olun.", Retryable: true}
+	ErrOnionInvalid      = &ScanError{Code: CodeOnionInvalid, Message: "Onion adresi geçersiz veya desteklenmeyen formatta (V2)."}
+	ErrTimeout           = &ScanError{Code: CodeTimeout, Message: "Bağlantı zaman aşımına uğradı. Site çok yavaş veya kapalı.", Retryable: true}
+	ErrHostUnreachable   = &ScanError{Code: CodeHostUnreachable, Message: "Hedef sunucuya ulaşılamıyor.", Retryable: true}
+	ErrConnectionRefused = &ScanError{Code: CodeConnectionRefused, Message: "Bağlantı reddedildi. Hedef sunucu bağlantı kabul etmiyor."}
+	ErrConnectionClosed  = &ScanError{Code: CodeConnectionClosed, Message: "Sunucu bağlantıyı kesti (Boş Yanıt). Site çevrimdışı veya Tor devresi koptu.", Retryable: true}
+	ErrDNS               = &ScanError{Code: CodeDNS, Message: "Alan adı çözümlenemedi."}
+	ErrHTTPStatus        = &ScanError{Code: CodeHTTPStatus, Message: "Sunucu hata yanıtı döndürdü."}
+	ErrCaptcha           = &ScanError{Code: CodeCaptcha, Message: "Sayfa CAPTCHA veya bot koruması arkasında."}
+	ErrNotForum          = &ScanError{Code: CodeNotForum, Message: "Hedef forum yapısına uymuyor."}
+	ErrInvalidURL        = &ScanError{Code: CodeInvalidURL, Message: "Geçersiz URL."}
+	ErrCancelled         = &ScanError{Code: CodeCancelled, Message: "Tarama iptal edildi."}
+)
+
+// wrap, sınıf hatasının alttaki hatayı taşıyan bir kopyasını döndürür.
+func (e *ScanError) wrap(err error) *ScanError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// NewHTTPStatusError, 2xx dışı yanıt için hata üretir. 5xx ve 429 geçici sayılır.
+func NewHTTPStatusError(status int) *ScanError {
+	e := ErrHTTPStatus.wrap(nil)
+	e.Status = status
+	e.Message = fmt.Sprintf("Sunucu HTTP %d döndürdü (%s).", status, http.StatusText(status))
+	e.Retryable = status >= 500 || status == http.StatusTooManyRequests
+	return e
+}
+
+// AsScanError, hatayı sınıflandırır. Zaten ScanError ise aynen döner, nil için nil döner.
+func AsScanError(err error) *ScanError {
+	if err == nil {
+		return nil
+	}
+	var se *ScanError
+	if errors.As(err, &se) {
+		return se
+	}
+	return ClassifyError(err)
+}
+
+// ClassifyError, ağ, SOCKS ve HTTP istemcisi hatalarını kodlara çevirir.
+func ClassifyError(err error) *ScanError {
+	if errors.Is(err, context.Canceled) {
+		return ErrCancelled.wrap(err)
+	}
+	if isProxyDialError(err) {
+		return ErrProxyUnavailable.wrap(err)
+	}
+
+	// Tor'un SOCKS yanıt kodları (x/net/proxy bunları düz metin olarak döndürür)
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "unknown code: 246") || strings.Contains(msg, "0xf6"):
+		return ErrOnionInvalid.wrap(err)
+	case strings.Contains(msg, "host unreachable") || strings.Contains(msg, "network unreachable") || strings.Contains(msg, "ttl expired"):
+		return ErrHostUnreachable.wrap(err)
+	}
+
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout.wrap(err)
+	case errors.As(err, &dnsErr):
+		return ErrDNS.wrap(err)
+	case errors.Is(err, syscall.ECONNREFUSED), strings.Contains(msg, "connection refused"):
+		return ErrConnectionRefused.wrap(err)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNRESET):
+		return ErrConnectionClosed.wrap(err)
+	case strings.Contains(msg, "unsupported protocol scheme") || strings.Contains(msg, "missing url") || strings.Contains(msg, "invalid url"):
+		return ErrInvalidURL.wrap(err)
+	}
+
+	return &ScanError{Code: CodeUnknown, Message: err.Error(), Retryable: true, Err: err}
+}
+
+// isProxyDialError, proxy sunucusunun kendisine bağlanılamadığını ayırt eder.
+// SOCKS için "socks connect" işleminin içinde bir "dial" hatası, HTTP proxy için "proxyconnect" işlemi aranır.
+func isProxyDialError(err error) bool {
+	var op *net.OpError
+	if !errors.As(err, &op) {
+		return false
+	}
+	switch {
+	case op.Op == "proxyconnect":
+		return true
+	case strings.HasPrefix(op.Op, "socks"):
+		var inner *net.OpError
+		return errors.As(op.Err, &inner) && inner.Op == "dial"
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package scraper
+
+import (
+	"log"
+	"time"
+)
+
+// Tarama sırasında yayınlanan olay türleri
+const (
+	EventConnect   = "connect"    // Hedefe bağlantı başlatıldı
+	EventRetry     = "retry"      // Hata sonrası yeniden deneme
+	EventUserAgent = "user_agent" // Rastgele UA seçildi
+	EventProxy     = "proxy"      // Kullanılan proxy
+	EventPage      = "page"       // Sayfa ziyaret edildi
+	EventPosts     = "posts"      // Sayfadan ileti/konu çıkarıldı
+	EventError     = "error"      // İstek hatası
+)
+
+// ScanEvent, taramanın ilerleyişine dair yapılandırılmış bir mesajdır.
+type ScanEvent struct {
+	Type    string                 `json:"type"`
+	Message string                 `json:"message"`
+	URL     string                 `json:"url,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	Time    time.Time              `json:"time"`
+}
+
+// emit, olayı stdout'a yazar ve OnEvent tanımlıysa iletir.
+func (o ScanOptions) emit(eventType, pageURL, message string, data map[string]interface{}) {
+	log.Println(message)
+	if o.OnEvent == nil {
+		return
+	}
+	o.OnEvent(ScanEvent{
+		Type:    eventType,
+		Message: message,
+		URL:     pageURL,
+		Data:    data,
+		Time:    time.Now(),
+	})
+}
@@ -0,0 +1,231 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scraper/models"
+)
+
+// DefaultCategory, hiçbir keyword eşleşmediğinde kullanılan kategoridir.
+const DefaultCategory = "Genel"
+
+// KeywordMatcher, keyword listesini bir kez derleyip metinleri etiketler.
+type KeywordMatcher struct {
+	rules []keywordRule
+}
+
+type keywordRule struct {
+	keyword models.Keyword
+	needle  []rune         // contains/word modları için katlanmış kelime
+	re      *regexp.Regexp // regex modu için
+}
+
+// ValidateKeyword, eşleştirme modunu ve regex sözdizimini kontrol eder.
+func ValidateKeyword(kw models.Keyword) error {
+	if strings.TrimSpace(kw.Word) == "" {
+		return fmt.Errorf("Keyword boş olamaz")
+	}
+	switch kw.Mode {
+	case "", models.MatchContains, models.MatchWord:
+	case models.MatchRegex:
+		if _, err := regexp.Compile(kw.Word); err != nil {
+			return fmt.Errorf("Geçersiz düzenli ifade: %v", err)
+		}
+	default:
+		return fmt.Errorf("Geçersiz eşleştirme modu: %s (contains, word, regex)", kw.Mode)
+	}
+	if kw.Weight < 0 {
+		return fmt.Errorf("Ağırlık negatif olamaz")
+	}
+	return nil
+}
+
+// NewKeywordMatcher, keyword'leri derler. Geçersiz regex'ler atlanır.
+// Uzun kelimeler önce denenir; eşit puanda kategori seçimi böylece belirli olur.
+func NewKeywordMatcher(keywords []models.Keyword) *KeywordMatcher {
+	m := &KeywordMatcher{}
+	for _, kw := range keywords {
+		if ValidateKeyword(kw) != nil {
+			continue
+		}
+		rule := keywordRule{keyword: kw}
+		if kw.Mode == models.MatchRegex {
+			pattern := kw.Word
+			if !kw.CaseSensitive {
+				pattern = "(?i)" + pattern
+			}
+			rule.re = regexp.MustCompile(pattern)
+		} else {
+			rule.needle = foldRunes(kw, []rune(strings.TrimSpace(kw.Word)))
+		}
+		m.rules = append(m.rules, rule)
+	}
+	sort.SliceStable(m.rules, func(i, j int) bool {
+		return utf8.RuneCountInString(m.rules[i].keyword.Word) > utf8.RuneCountInString(m.rules[j].keyword.Word)
+	})
+	return m
+}
+
+// Match, metinde eşleşen tüm keyword'leri puan sırasına göre döndürür.
+// Puan = ağırlık x eşleşme sayısı; konumlar karakter (rune) cinsindendir.
+func (m *KeywordMatcher) Match(text string) []models.ContentTag {
+	if m == nil || len(m.rules) == 0 || text == "" {
+		return nil
+	}
+	original := []rune(text)
+
+	var tags []models.ContentTag
+	for _, rule := range m.rules {
+		folded := foldRunes(rule.keyword, original)
+
+		var spans []models.MatchSpan
+		if rule.re != nil {
+			spans = matchRegex(rule.re, folded, original)
+		} else {
+			spans = matchNeedle(rule.needle, folded, original, rule.keyword.Mode != models.MatchContains)
+		}
+		if len(spans) == 0 {
+			continue
+		}
+
+		weight := rule.keyword.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		tags = append(tags, models.ContentTag{
+			KeywordID: rule.keyword.ID,
+			Word:      rule.keyword.Word,
+			Category:  rule.keyword.Category,
+			Score:     weight * float64(len(spans)),
+			Matches:   spans,
+		})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Score > tags[j].Score })
+	return tags
+}
+
+// BestCategory, etiket puanlarını kategori bazında toplayıp en yüksek olanı seçer.
+func BestCategory(tags ...[]models.ContentTag) string {
+	scores := map[string]float64{}
+	var order []string
+	for _, list := range tags {
+		for _, t := range list {
+			if t.Category == "" {
+				continue
+			}
+			if _, ok := scores[t.Category]; !ok {
+				order = append(order, t.Category)
+			}
+			scores[t.Category] += t.Score
+		}
+	}
+
+	best := DefaultCategory
+	bestScore := 0.0
+	for _, category := range order {
+		if scores[category] > bestScore {
+			best, bestScore = category, scores[category]
+		}
+	}
+	return best
+}
+
+// foldRunes, büyük/küçük harf duyarsız keyword'ler için metni karakter karakter küçültür.
+// Her karakter tek karaktere dönüştüğü için konumlar orijinal metinle aynı kalır
+// (strings.ToLower "İ" harfini iki karaktere çevirir).
+func foldRunes(kw models.Keyword, runes []rune) []rune {
+	if kw.CaseSensitive {
+		return runes
+	}
+	out := make([]rune, len(runes))
+	for i, r := range runes {
+		if kw.TurkishCase {
+			out[i] = unicode.TurkishCase.ToLower(r)
+		} else {
+			out[i] = unicode.ToLower(r)
+		}
+	}
+	return out
+}
+
+// matchNeedle, kelimenin çakışmayan tüm geçişlerini bulur.
+// wholeWord ise kelimenin harf/rakam ile başlayan/biten kenarlarında sözcük sınırı aranır.
+func matchNeedle(needle, folded, original []rune, wholeWord bool) []models.MatchSpan {
+	n := len(needle)
+	if n == 0 {
+		return nil
+	}
+
+	var spans []models.MatchSpan
+	for i := 0; i+n <= len(folded); {
+		if !runesEqual(folded[i:i+n], needle) {
+			i++
+			continue
+		}
+		if wholeWord && !atWordBoundary(folded, i, i+n) {
+			i++
+			continue
+		}
+		spans = append(spans, models.MatchSpan{Start: i, End: i + n, Text: string(original[i : i+n])})
+		i += n
+	}
+	return spans
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// atWordBoundary: Eşleşmenin önündeki ve arkasındaki karakter kelimenin devamı olmamalı
+func atWordBoundary(text []rune, start, end int) bool {
+	if isWordRune(text[start]) && start > 0 && isWordRune(text[start-1]) {
+		return false
+	}
+	if isWordRune(text[end-1]) && end < len(text) && isWordRune(text[end]) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// matchRegex, bayt konumlarını karakter konumlarına çevirir
+func matchRegex(re *regexp.Regexp, folded, original []rune) []models.MatchSpan {
+	text := string(folded)
+	locs := re.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return nil
+	}
+
+	// Bayt konumu -> karakter konumu
+	runeAt := make(map[int]int, len(folded)+1)
+	pos := 0
+	for i, r := range folded {
+		runeAt[pos] = i
+		pos += utf8.RuneLen(r)
+	}
+	runeAt[pos] = len(folded)
+
+	var spans []models.MatchSpan
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue // Boş eşleşmeler etiket sayılmaz
+		}
+		start, end := runeAt[loc[0]], runeAt[loc[1]]
+		spans = append(spans, models.MatchSpan{Start: start, End: end, Text: string(original[start:end])})
+	}
+	return spans
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// maxRedirects, Go'nun HTTP istemcisindeki sınırla aynıdır.
+const maxRedirects = 10
+
+// ResponseMeta, taramada alınan bir HTTP yanıtının üst verisi.
+// Sunucu başlığının değişmesi veya ani 403 gibi sinyaller için her taramada saklanır.
+type ResponseMeta struct {
+	URL         string            `json:"url"`       // İstenen adres
+	FinalURL    string            `json:"final_url"` // Yönlendirmelerden sonraki adres
+	Depth       int               `json:"depth"`
+	StatusCode  int               `json:"status_code"`
+	ContentType string            `json:"content_type"`
+	Server      string            `json:"server"`
+	BodySize    int               `json:"body_size"`  // Bayt
+	LatencyMs   float64           `json:"latency_ms"` // İstekten gövdenin okunmasına kadar
+	Headers     map[string]string `json:"headers"`
+	Redirects   []Redirect        `json:"redirects,omitempty"`
+	Body        []byte            `json:"-"` // Ham gövde (arşiv için; colly UTF-8 dışı karakter setlerini dönüştürmüş olabilir)
+}
+
+// Redirect, yönlendirme zincirinin bir adımı.
+type Redirect struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	StatusCode int    `json:"status_code"`
+}
+
+// responseRecorder, isteklerin başlangıç zamanlarını ve yönlendirme zincirlerini yanıt gelene kadar tutar.
+type responseRecorder struct {
+	mu        sync.Mutex
+	started   map[uint32]time.Time
+	requested map[uint32]string
+	redirects map[string][]Redirect // İlk istenen adrese göre
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{
+		started:   make(map[uint32]time.Time),
+		requested: make(map[uint32]string),
+		redirects: make(map[string][]Redirect),
+	}
+}
+
+// start, OnRequest içinde çağrılır.
+func (rr *responseRecorder) start(r *colly.Request) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.started[r.ID] = time.Now()
+	rr.requested[r.ID] = r.URL.String()
+}
+
+// redirect, colly'nin yönlendirme işleyicisidir. Zinciri kaydeder; varsayılan işleyicinin
+// sınır ve Authorization kurallarını korur (alan adı filtreleri colly tarafından önceden uygulanır).
+func (rr *responseRecorder) redirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return http.ErrUseLastResponse
+	}
+	last := via[len(via)-1]
+	if req.URL.Host != last.URL.Host {
+		req.Header.Del("Authorization")
+	}
+
+	hop := Redirect{From: last.URL.String(), To: req.URL.String()}
+	if req.Response != nil {
+		hop.StatusCode = req.Response.StatusCode
+	}
+	origin := via[0].URL.String()
+	rr.mu.Lock()
+	rr.redirects[origin] = append(rr.redirects[origin], hop)
+	rr.mu.Unlock()
+	return nil
+}
+
+// finish, yanıtın üst verisini çıkarır ve isteğe ait kayıtları temizler.
+func (rr *responseRecorder) finish(r *colly.Response) ResponseMeta {
+	rr.mu.Lock()
+	started, ok := rr.started[r.Request.ID]
+	requested := rr.requested[r.Request.ID]
+	redirects := rr.redirects[requested]
+	delete(rr.started, r.Request.ID)
+	delete(rr.requested, r.Request.ID)
+	delete(rr.redirects, requested)
+	rr.mu.Unlock()
+
+	final := r.Request.URL.String()
+	if requested == "" {
+		requested = final
+	}
+	meta := ResponseMeta{
+		URL:        requested,
+		FinalURL:   final,
+		Depth:      r.Request.Depth,
+		StatusCode: r.StatusCode,
+		BodySize:   len(r.Body),
+		Body:       r.Body,
+		Headers:    map[string]string{},
+		Redirects:  redirects,
+	}
+	if ok {
+		meta.LatencyMs = float64(time.Since(started).Microseconds()) / 1000
+	}
+	if r.Headers != nil {
+		meta.ContentType = r.Headers.Get("Content-Type")
+		meta.Server = r.Headers.Get("Server")
+		for name, values := range *r.Headers {
+			meta.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return meta
+}
@@ -0,0 +1,453 @@
+package scraper
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// Parser, belirli bir forum yazılımının sayfalarını tanır ve ayrıştırır.
+type Parser interface {
+	// Name, motorun kısa adıdır (örn: "xenforo").
+	Name() string
+	// Detect, sayfanın bu motor tarafından üretilip üretilmediğini kontrol eder.
+	Detect(doc *goquery.Selection) bool
+	// Parse, sayfadan iletileri, konu satırlarını ve sayfalama bağlantısını çıkarır.
+	// base, göreli bağlantıları çözmek için kullanılır.
+	Parse(doc *goquery.Selection, base *url.URL) ParsedPage
+}
+
+// ParsedPage, bir Parser'ın tek sayfadan çıkardığı yapısal veridir.
+type ParsedPage struct {
+	Title    string
+	Posts    []PostData
+	Rows     []ThreadRow // Konu listesi satırları
+	NextPage string      // Sonraki sayfa bağlantısı (varsa)
+}
+
+// HasContent, sayfadan ileti veya konu satırı çıkarılıp çıkarılmadığını döndürür.
+func (p ParsedPage) HasContent() bool {
+	return len(p.Posts) > 0 || len(p.Rows) > 0
+}
+
+// ThreadRow, konu listesindeki tek bir satırdır.
+type ThreadRow struct {
+	ThreadID string
+	Title    string
+	Link     string
+	Author   string
+	Date     string
+	Replies  int
+	Views    int
+}
+
+// engineParsers, meta generator kontrolünün tanıdığı motorların ayrıştırıcılarıdır.
+// Sıra önemlidir: ilk tespit eden kazanır.
+var engineParsers = []Parser{
+	xenforoParser,
+	vbulletinParser,
+	phpbbParser,
+	mybbParser,
+	smfParser,
+	discuzParser,
+	nodebbParser,
+}
+
+// Parsers, motor ayrıştırıcılarını ve en sonda genel (heuristik) ayrıştırıcıyı döndürür.
+func Parsers() []Parser {
+	return parsersWithLimit(DefaultContentLimit)
+}
+
+// parsersWithLimit, genel ayrıştırıcının ham içerik sınırını ayarlayarak listeyi kurar.
+func parsersWithLimit(contentLimit int) []Parser {
+	return append(append([]Parser{}, engineParsers...), genericParser{contentLimit: contentLimit})
+}
+
+// parseDocument, sayfayı tanıyan ilk ayrıştırıcıyla işler.
+// Tespit edilen motor veri çıkaramazsa listedeki sonraki ayrıştırıcılara düşülür.
+func parseDocument(doc *goquery.Selection, base *url.URL, parsers []Parser) (ParsedPage, string) {
+	for _, p := range parsers {
+		if !p.Detect(doc) {
+			continue
+		}
+		page := p.Parse(doc, base)
+		if page.HasContent() {
+			return page, p.Name()
+		}
+	}
+	return ParsedPage{}, ""
+}
+
+// --- Kural tabanlı motor ayrıştırıcısı ---
+
+// Kurallar "seçici@öznitelik" biçimindedir. Öznitelik yoksa metin alınır,
+// seçici boşsa (örn: "@data-author") kapsayıcının kendisine bakılır.
+// "xpath:" ile başlayan kurallar XPath olarak değerlendirilir (örn: "xpath:.//a/@href").
+// Aynı alan için birden fazla kural verilirse ilk boş olmayan sonuç kullanılır.
+
+type postRules struct {
+	Container    string
+	ID           []string
+	Author       []string
+	Date         []string
+	Content      []string
+	LastEdited   []string
+	Reactions    []string
+	Quote        string // Alıntı bloğu seçicisi (içerikten çıkarılır)
+	QuoteAuthor  []string
+	QuoteContent []string
+	Noise        string // İçerikten silinecek ek öğeler
+}
+
+type rowRules struct {
+	Container string
+	ID        []string
+	Title     []string
+	Link      []string
+	Author    []string
+	Date      []string
+	Replies   []string
+	Views     []string
+}
+
+// engineParser, seçici kurallarıyla tanımlanan bir forum motoru ayrıştırıcısıdır.
+type engineParser struct {
+	name       string
+	generators []string // meta generator içinde aranacak ifadeler
+	markers    string   // motoru tanıtan DOM seçicisi
+	title      []string
+	post       postRules
+	row        rowRules
+	next       []string
+	cleanQuote func(author string) string // Alıntı yazarındaki "wrote:" gibi ekleri temizler
+}
+
+func (p *engineParser) Name() string {
+	return p.name
+}
+
+func (p *engineParser) Detect(doc *goquery.Selection) bool {
+	if generatorMatches(doc, p.generators...) {
+		return true
+	}
+	return p.markers != "" && doc.Find(p.markers).Length() > 0
+}
+
+func (p *engineParser) Parse(doc *goquery.Selection, base *url.URL) ParsedPage {
+	page := ParsedPage{
+		Title: selectValue(doc, p.title),
+	}
+
+	if p.post.Container != "" {
+		findAll(doc, p.post.Container).Each(func(i int, s *goquery.Selection) {
+			if post, ok := p.parsePost(s); ok {
+				page.Posts = append(page.Posts, post)
+			}
+		})
+	}
+
+	if len(page.Posts) == 0 && p.row.Container != "" {
+		findAll(doc, p.row.Container).Each(func(i int, s *goquery.Selection) {
+			if row, ok := p.parseRow(s, base); ok {
+				page.Rows = append(page.Rows, row)
+			}
+		})
+	}
+
+	if next := selectValue(doc, p.next); next != "" {
+		page.NextPage = resolveLink(base, next)
+	}
+
+	return page
+}
+
+func (p *engineParser) parsePost(s *goquery.Selection) (PostData, bool) {
+	post := PostData{
+		PostID:     lastNumber(selectValue(s, p.post.ID)),
+		Author:     cleanText(selectValue(s, p.post.Author)),
+		Date:       cleanText(selectValue(s, p.post.Date)),
+		LastEdited: cleanText(strings.ReplaceAll(selectValue(s, p.post.LastEdited), "\n", " ")),
+		Reactions:  cleanText(selectValue(s, p.post.Reactions)),
+	}
+
+	content := selectFirst(s, p.post.Content)
+	if content == nil {
+		return post, false
+	}
+	content = content.Clone()
+
+	// Alıntıları ayrı yapıya al ve içerikten çıkar
+	if p.post.Quote != "" {
+		content.Find(p.post.Quote).Each(func(i int, q *goquery.Selection) {
+			// İç içe alıntılar dış alıntının parçası sayılır
+			if q.ParentsFiltered(p.post.Quote).Length() > 0 {
+				return
+			}
+			author := selectValue(q, p.post.QuoteAuthor)
+			if p.cleanQuote != nil {
+				author = p.cleanQuote(author)
+			}
+
+			// Alıntı başlığı içerik metnine karışmasın
+			body := q.Clone()
+			body.Find("cite, .quoteheader").Remove()
+			for _, rule := range p.post.QuoteAuthor {
+				if sel, _ := splitRule(rule); sel != "" {
+					body.Find(sel).Remove()
+				}
+			}
+
+			quote := QuoteData{
+				Author:  author,
+				Content: cleanText(selectValue(body, p.post.QuoteContent)),
+			}
+			if quote.Content != "" {
+				post.Quotes = append(post.Quotes, quote)
+			}
+		})
+		content.Find(p.post.Quote).Remove()
+	}
+
+	content.Find("script, style, button, noscript").Remove()
+	if p.post.Noise != "" {
+		content.Find(p.post.Noise).Remove()
+	}
+
+	post.Content = cleanText(strings.ReplaceAll(content.Text(), "Click to expand...", ""))
+	if len(post.Content) < 3 && len(post.Quotes) == 0 {
+		return post, false
+	}
+	if post.Author == "" {
+		post.Author = "Anonymous"
+	}
+	// Bazı motorlar düzenleme notunu tarih alanının içine yazar
+	if post.LastEdited != "" {
+		post.Date = strings.TrimSpace(strings.Replace(post.Date, post.LastEdited, "", 1))
+	}
+	return post, true
+}
+
+func (p *engineParser) parseRow(s *goquery.Selection, base *url.URL) (ThreadRow, bool) {
+	row := ThreadRow{
+		Title:   cleanText(selectValue(s, p.row.Title)),
+		Author:  cleanText(selectValue(s, p.row.Author)),
+		Date:    cleanText(selectValue(s, p.row.Date)),
+		Replies: parseCount(selectValue(s, p.row.Replies)),
+		Views:   parseCount(selectValue(s, p.row.Views)),
+	}
+	if row.Title == "" {
+		return row, false
+	}
+	if link := selectValue(s, p.row.Link); link != "" {
+		row.Link = resolveLink(base, link)
+	}
+	row.ThreadID = lastNumber(selectValue(s, p.row.ID))
+	if row.ThreadID == "" {
+		row.ThreadID = lastNumber(row.Link)
+	}
+	return row, true
+}
+
+// --- Yardımcılar ---
+
+// generatorMatches, meta generator etiketinde verilen ifadelerden biri geçiyor mu bakar.
+func generatorMatches(doc *goquery.Selection, names ...string) bool {
+	matched := false
+	doc.Find("meta[name='generator']").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		content := strings.ToLower(s.AttrOr("content", ""))
+		for _, name := range names {
+			if strings.Contains(content, name) {
+				matched = true
+				return false
+			}
+		}
+		return true
+	})
+	return matched
+}
+
+// splitRule, "seçici@öznitelik" kuralını parçalarına ayırır.
+func splitRule(rule string) (string, string) {
+	if i := strings.LastIndex(rule, "@"); i >= 0 {
+		return strings.TrimSpace(rule[:i]), strings.TrimSpace(rule[i+1:])
+	}
+	return strings.TrimSpace(rule), ""
+}
+
+// findAll, CSS veya "xpath:" seçicisine uyan tüm alt öğeleri döndürür.
+func findAll(s *goquery.Selection, selector string) *goquery.Selection {
+	expr, isXPath := strings.CutPrefix(selector, xpathPrefix)
+	if !isXPath {
+		return s.Find(selector)
+	}
+	return s.FindNodes(queryXPath(s, expr)...)
+}
+
+// selectFirst, kurallardan ilk eşleşen öğeyi döndürür (öznitelikler yok sayılır).
+func selectFirst(s *goquery.Selection, rules []string) *goquery.Selection {
+	for _, rule := range rules {
+		if strings.HasPrefix(rule, xpathPrefix) {
+			if target := findAll(s, rule).First(); target.Length() > 0 {
+				return target
+			}
+			continue
+		}
+
+		sel, _ := splitRule(rule)
+		target := s
+		if sel != "" {
+			target = s.Find(sel).First()
+		}
+		if target.Length() > 0 {
+			return target
+		}
+	}
+	return nil
+}
+
+// selectValue, kurallardan ilk boş olmayan metni veya öznitelik değerini döndürür.
+func selectValue(s *goquery.Selection, rules []string) string {
+	for _, rule := range rules {
+		if expr, isXPath := strings.CutPrefix(rule, xpathPrefix); isXPath {
+			for _, node := range queryXPath(s, expr) {
+				if value := strings.TrimSpace(htmlquery.InnerText(node)); value != "" {
+					return value
+				}
+			}
+			continue
+		}
+
+		sel, attr := splitRule(rule)
+		target := s
+		if sel != "" {
+			target = s.Find(sel).First()
+		}
+		if target.Length() == 0 {
+			continue
+		}
+
+		var value string
+		if attr != "" {
+			value = target.AttrOr(attr, "")
+		} else {
+			value = target.Text()
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// xpathPrefix, kuralın CSS yerine XPath olarak değerlendirileceğini belirtir.
+const xpathPrefix = "xpath:"
+
+// queryXPath, ifadeyi seçimin ilk öğesi bağlamında çalıştırır. Geçersiz ifade boş sonuç verir.
+func queryXPath(s *goquery.Selection, expr string) []*html.Node {
+	if s.Length() == 0 {
+		return nil
+	}
+	nodes, err := htmlquery.QueryAll(s.Get(0), expr)
+	if err != nil {
+		return nil
+	}
+	return nodes
+}
+
+// ValidateRule, kuralın geçerli bir CSS veya XPath seçicisi olup olmadığını kontrol eder.
+func ValidateRule(rule string) error {
+	if expr, isXPath := strings.CutPrefix(rule, xpathPrefix); isXPath {
+		_, err := xpath.Compile(expr)
+		return err
+	}
+	if sel, _ := splitRule(rule); sel != "" {
+		_, err := cascadia.ParseGroup(sel)
+		return err
+	}
+	return nil
+}
+
+// resolveLink, göreli bağlantıyı sayfa adresine göre mutlak hale getirir.
+func resolveLink(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	ref.Fragment = ""
+	return ref.String()
+}
+
+// trimQuoteSuffix, alıntı başlığındaki "Quote from:", "wrote:", "yazdı:" gibi kalıpları
+// ve tarih kısmını atarak sadece yazar adını bırakır.
+func trimQuoteSuffix(author string) string {
+	author = strings.TrimSpace(author)
+	for _, prefix := range []string{"Quote from:", "Originally Posted by", "Alıntı:", "@"} {
+		if len(author) >= len(prefix) && strings.EqualFold(author[:len(prefix)], prefix) {
+			author = strings.TrimSpace(author[len(prefix):])
+		}
+	}
+
+	lower := strings.ToLower(author)
+	for _, suffix := range []string{" wrote", " said", " yazdı", " dedi", " on ", " 发表于"} {
+		if i := strings.Index(lower, suffix); i > 0 {
+			return strings.TrimSpace(author[:i])
+		}
+	}
+	return strings.TrimSuffix(author, ":")
+}
+
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// lastNumber, "post_123", "js-post-123" veya "?t=123" gibi değerlerdeki son sayıyı döndürür.
+func lastNumber(value string) string {
+	matches := numberPattern.FindAllString(value, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
+
+// countPattern, sayaçtaki ilk sayıyı ve varsa K/M kısaltmasını yakalar ("Views: 9.8K", "1,204 Views").
+var countPattern = regexp.MustCompile(`(\d[\d.,]*)\s*([KM])?\b`)
+
+// parseCount, "1,234", "1.2K" veya "3M" gibi sayaçları tam sayıya çevirir.
+// Sayının önünde veya arkasında "Replies:" gibi etiketler olabilir.
+func parseCount(value string) int {
+	match := countPattern.FindStringSubmatch(strings.ToUpper(value))
+	if match == nil {
+		return 0
+	}
+	number := match[1]
+
+	multiplier := 1.0
+	switch match[2] {
+	case "K":
+		multiplier = 1000
+	case "M":
+		multiplier = 1000000
+	}
+
+	if multiplier > 1 {
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", "."), 64); err == nil {
+			return int(f * multiplier)
+		}
+	}
+
+	digits := strings.Join(numberPattern.FindAllString(number, -1), "")
+	n, _ := strconv.Atoi(digits)
+	return n
+}
@@ -0,0 +1,33 @@
+package scraper
+
+// discuzParser, Discuz! X sayfalarını ayrıştırır.
+var discuzParser = &engineParser{
+	name:       "discuz",
+	generators: []string{"discuz"},
+	markers:    "#postlist div[id^='post_'] td.t_f, #threadlisttableid",
+	title:      []string{"#thread_subject", "h1"},
+	post: postRules{
+		Container:    "#postlist > div[id^='post_']",
+		ID:           []string{"@id"},
+		Author:       []string{".authi a.xw1", ".authi a"},
+		Date:         []string{"em[id^='authorposton'] span@title", "em[id^='authorposton']"},
+		Content:      []string{"td.t_f"},
+		LastEdited:   []string{"i.pstatus"},
+		Quote:        "div.quote",
+		QuoteAuthor:  []string{"blockquote font[color='#999999']", "font"},
+		QuoteContent: []string{"blockquote"},
+		Noise:        "i.pstatus, .jammer, span[style*='display:none']",
+	},
+	row: rowRules{
+		Container: "#threadlisttableid tbody[id^='normalthread_'], #threadlisttableid tbody[id^='stickthread_']",
+		ID:        []string{"@id"},
+		Title:     []string{"a.s.xst", "th a.xst"},
+		Link:      []string{"a.s.xst@href", "th a.xst@href"},
+		Author:    []string{"td.by cite a"},
+		Date:      []string{"td.by em span@title", "td.by em"},
+		Replies:   []string{"td.num a"},
+		Views:     []string{"td.num em"},
+	},
+	next:       []string{"a.nxt@href", "link[rel='next']@href"},
+	cleanQuote: trimQuoteSuffix,
+}
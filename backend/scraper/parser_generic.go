@@ -0,0 +1,196 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// genericParser, motoru tanınmayan sayfalar için yaygın seçici listelerini deneyen
+// heuristik ayrıştırıcıdır. Her sayfayı kabul eder, bu yüzden listede en sonda durur.
+type genericParser struct {
+	contentLimit int // Kapsayıcıdan alınan yedek içeriğin karakter sınırı
+}
+
+func (genericParser) Name() string {
+	return "generic"
+}
+
+func (genericParser) Detect(doc *goquery.Selection) bool {
+	return true
+}
+
+func (p genericParser) Parse(doc *goquery.Selection, base *url.URL) ParsedPage {
+	page := ParsedPage{
+		Title:    extractPageTitle(doc),
+		Posts:    extractPosts(doc, p.contentLimit),
+		NextPage: findNextPageLink(doc, base),
+	}
+	if len(page.Posts) == 0 {
+		page.Rows = extractThreadRows(doc, base)
+	}
+	return page
+}
+
+// extractPageTitle, forum özel başlık sınıflarına, h1'e ve title etiketine sırasıyla bakar.
+func extractPageTitle(dom *goquery.Selection) string {
+	for _, selector := range []string{".p-title-value, .ipbType_sectionTitle", "h1", "title"} {
+		var title string
+		// Son eşleşen öğe kazanır
+		dom.Find(selector).Each(func(i int, s *goquery.Selection) {
+			if t := strings.TrimSpace(s.Text()); t != "" {
+				title = t
+			}
+		})
+		if title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// extractPosts, yaygın forum yazılımlarının ileti kapsayıcılarından iletileri çıkarır.
+func extractPosts(dom *goquery.Selection, contentLimit int) []PostData {
+	var posts []PostData
+
+	// Yaygın forum yazılımlarının kullandığı kapsayıcı sınıflar
+	postSelectors := []string{
+		".post", ".message", ".entry", "article", ".comment", ".post-container", "div[id^='post']",
+		".postbit", ".post-content", ".message-content", ".post_body", ".entry-content",
+		".ItemBody", ".CommentBody", ".lia-message-body-content", ".js-post__content-text",
+		".cooked", ".topic-body", ".post-message", ".post_wrapper", ".post_block",
+		"table.post", "div.post", "td.post_content",
+	}
+
+	// Seçicileri dene
+	for _, selector := range postSelectors {
+		dom.Find(selector).Each(func(i int, s *goquery.Selection) {
+			// --- Meta Verileri Çek ---
+
+			// Meta Verileri Çek
+			lastEdited := strings.TrimSpace(s.Find(".message-lastEdit, .post-edit, .edited-by").Text())
+			lastEdited = strings.TrimSpace(strings.ReplaceAll(lastEdited, "\n", " "))
+
+			// --- İçerik Temizliği ---
+
+			// İçerik
+			contentSel := s.Find(".content, .message, .body, .text, .entry-content, .post_body, .post_content, .posttext, .post-text, .messageText, .uu_post")
+
+			// Gereksiz etiketleri temizle
+			contentSel.Find(`
+				script, style, button, isindex,
+				.footer, .signature, .kutu, 
+				.message-cell--user, .message-userInfo, .post-sidebar, .postprofile, .user-details, .post-left, .user_info, .author_info,
+				.message-userExtras, .message-avatar-wrapper, .message-userTitle, .message-userBanner,
+				.bbCodeBlock-expandLink, .attribution,
+				.reaction-bar, .reactions, .message-attribution, .message-footer, .message-lastEdit, .privateControls, .publicControls,
+				.post_head, .post-head, .node-controls, .post-date, .date, .permalink, .post-number,
+				dl.pairs
+			`).Remove()
+
+			// Metin temizliği
+			content := strings.TrimSpace(contentSel.Text())
+			content = strings.ReplaceAll(content, "Click to expand...", "")
+			content = strings.ReplaceAll(content, "Tıkla ve genişlet...", "")
+
+			// Eğer özel içerik seçicisi işe yaramazsa (veya yanlışlıkla her şeyi sildiyse) ana konteynerden al
+			if content == "" {
+				// Ana konteynerin textini al ama temizleyerek
+				clone := s.Clone()
+				// Buradaki remove listesi de aynı olmalı
+				clone.Find(`
+					script, style, button, 
+					.footer, .signature, .user_info, .author_info, .post_head, .post-head,
+					.message-cell--user, .message-userInfo, .postprofile,
+					.message-attribution, .message-footer, .message-lastEdit, .reaction-bar
+				`).Remove()
+
+				content = strings.TrimSpace(clone.Text())
+				content = strings.ReplaceAll(content, "Click to expand...", "")
+
+				content = truncateRunes(content, contentLimit, "...")
+			}
+
+			// Çok kısa içerikleri yoksay (gürültü önleme)
+			if len(content) < 3 {
+				return
+			}
+
+			// Yazar
+			author := strings.TrimSpace(s.Find(".author, .user, .username, .name, a[href*='user'], .poster, .user-details, .popupctrl, .mem_profile").First().Text())
+			if author == "" {
+				author = strings.TrimSpace(s.Find(".user_info, .author_info, .post_author").First().Text())
+			}
+			if author == "" {
+				author = "Anonymous"
+			}
+
+			// Tarih
+			date := strings.TrimSpace(s.Find(".date, .time, time, .timestamp, .published, .post-date, .date-header, .post_date").First().Text())
+			if date == "" {
+				// Başlık veya meta kısımlarında tarih arayalım
+				date = strings.TrimSpace(s.Find(".post_head, .post-head, .thead").Text())
+			}
+
+			posts = append(posts, PostData{
+				Author:     cleanText(author),
+				Content:    cleanText(content), // Temizleme fonksiyonu kullan
+				Date:       cleanText(date),
+				Reactions:  "",
+				LastEdited: lastEdited,
+			})
+		})
+
+		// Eğer post bulduysak ve yeterli sayıdaysa (false pozitifleri önlemek için)
+		if len(posts) > 0 {
+			break
+		}
+	}
+
+	return posts
+}
+
+// extractThreadRows, konu listesi satırlarından başlık ve bağlantıları çıkarır.
+func extractThreadRows(dom *goquery.Selection, base *url.URL) []ThreadRow {
+	var rows []ThreadRow
+	dom.Find(".thread, .topic, .row").Each(func(i int, s *goquery.Selection) {
+		title := strings.TrimSpace(s.Find(".title, .subject, h3, a").First().Text())
+		if title == "" {
+			return
+		}
+
+		row := ThreadRow{Title: title}
+		link := s.Find(".title a[href], .subject a[href], h3 a[href]").First()
+		if link.Length() == 0 {
+			link = s.Find("a[href]").First()
+		}
+		if href, ok := link.Attr("href"); ok {
+			row.Link = resolveLink(base, href)
+		}
+		rows = append(rows, row)
+	})
+	return rows
+}
+
+// findNextPageLink, sayfalama bloğundaki "sonraki sayfa" bağlantısını bulur.
+func findNextPageLink(dom *goquery.Selection, base *url.URL) string {
+	href, ok := dom.Find("link[rel='next'], a[rel='next']").First().Attr("href")
+
+	if !ok {
+		dom.Find(".pagination a[href], .pagenav a[href], .pages a[href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+			text := strings.ToLower(strings.TrimSpace(s.Text()))
+			if strings.Contains(text, "next") || strings.Contains(text, "sonraki") ||
+				text == "»" || text == "›" || text == ">" {
+				href, ok = s.Attr("href")
+				return false
+			}
+			return true
+		})
+	}
+
+	if !ok {
+		return ""
+	}
+	return resolveLink(base, href)
+}
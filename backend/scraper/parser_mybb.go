@@ -0,0 +1,31 @@
+package scraper
+
+// mybbParser, MyBB 1.8 sayfalarını ayrıştırır.
+var mybbParser = &engineParser{
+	name:       "mybb",
+	generators: []string{"mybb"},
+	markers:    "#posts div.post .post_author, tr.inline_row span[id^='tid_']",
+	title:      []string{"td.thead strong", "h1"},
+	post: postRules{
+		Container:    "#posts div.post",
+		ID:           []string{"@id"},
+		Author:       []string{".post_author .largetext a", ".author_information strong a", ".post_author strong"},
+		Date:         []string{".post_date span@title", ".post_date"},
+		Content:      []string{".post_body"},
+		LastEdited:   []string{".post_date .edited_post"},
+		Quote:        "blockquote.mycode_quote",
+		QuoteAuthor:  []string{"cite"},
+		QuoteContent: []string{""},
+		Noise:        ".signature",
+	},
+	row: rowRules{
+		Container: "tr.inline_row",
+		ID:        []string{"span[id^='tid_']@id"},
+		Title:     []string{"span[id^='tid_'] a", "span.subject_new a", "span.subject_old a"},
+		Link:      []string{"span[id^='tid_'] a@href", "span.subject_new a@href", "span.subject_old a@href"},
+		Author:    []string{".author a"},
+		Replies:   []string{"a[onclick*='whoPosted']"},
+	},
+	next:       []string{"a.pagination_next@href", "link[rel='next']@href"},
+	cleanQuote: trimQuoteSuffix,
+}
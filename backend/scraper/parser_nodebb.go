@@ -0,0 +1,33 @@
+package scraper
+
+// nodebbParser, NodeBB sayfalarını ayrıştırır (sunucu tarafında üretilen HTML).
+var nodebbParser = &engineParser{
+	name:       "nodebb",
+	generators: []string{"nodebb"},
+	markers:    "[component='post'][data-pid], [component='category/topic']",
+	title:      []string{"[component='topic/title']", "h1"},
+	post: postRules{
+		Container:    "[component='post'][data-pid]",
+		ID:           []string{"@data-pid"},
+		Author:       []string{"@data-username", "[itemprop='author'] [itemprop='name']", "a[data-username]@data-username"},
+		Date:         []string{".timeago@title", "[itemprop='datePublished']@content"},
+		Content:      []string{"[component='post/content']"},
+		LastEdited:   []string{"[component='post/editor']", ".edit-icon@title"},
+		Reactions:    []string{"[component='post/vote-count']"},
+		Quote:        "blockquote",
+		QuoteAuthor:  []string{"p:first-child:has(a[href*='/user/'])"},
+		QuoteContent: []string{""},
+	},
+	row: rowRules{
+		Container: "[component='category/topic']",
+		ID:        []string{"@data-tid"},
+		Title:     []string{"[component='topic/header'] a", "h3 a"},
+		Link:      []string{"[component='topic/header'] a@href", "h3 a@href"},
+		Author:    []string{"[data-username]@data-username", ".avatar@title"},
+		Date:      []string{".timeago@title"},
+		Replies:   []string{".stats-postcount .human-readable-number@title", "[component='topic/post-count']"},
+		Views:     []string{".stats-viewcount .human-readable-number@title"},
+	},
+	next:       []string{"link[rel='next']@href", "a[rel='next']@href"},
+	cleanQuote: trimQuoteSuffix,
+}
@@ -0,0 +1,32 @@
+package scraper
+
+// phpbbParser, phpBB 3.x (prosilver ve türevleri) sayfalarını ayrıştırır.
+var phpbbParser = &engineParser{
+	name:       "phpbb",
+	generators: []string{"phpbb"},
+	markers:    "body#phpbb, div.post .postbody, ul.topiclist.topics",
+	title:      []string{"h2.topic-title", "h2.forum-title", "h2"},
+	post: postRules{
+		Container:    "div.post",
+		ID:           []string{"@id"},
+		Author:       []string{".postprofile .username", ".postprofile .username-coloured", "p.author .username", "p.author .username-coloured"},
+		Date:         []string{"p.author time@datetime", "p.author"},
+		Content:      []string{".postbody .content", ".content"},
+		LastEdited:   []string{".postbody .notice"},
+		Quote:        "blockquote",
+		QuoteAuthor:  []string{"cite"},
+		QuoteContent: []string{""},
+		Noise:        "div.signature, .notice",
+	},
+	row: rowRules{
+		Container: "ul.topiclist.topics li.row",
+		Title:     []string{"a.topictitle"},
+		Link:      []string{"a.topictitle@href"},
+		Author:    []string{".topic-poster .username", ".topic-poster .username-coloured", "div.responsive-hide .username"},
+		Date:      []string{".topic-poster time@datetime"},
+		Replies:   []string{"dd.posts"},
+		Views:     []string{"dd.views"},
+	},
+	next:       []string{".pagination li.next a@href", "a[rel='next']@href"},
+	cleanQuote: trimQuoteSuffix,
+}
@@ -0,0 +1,151 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"scraper/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// profileParser, kullanıcının tanımladığı seçici profiliyle çalışan ayrıştırıcıdır.
+// Profil siteye bilerek atandığı için her sayfayı kabul eder.
+type profileParser struct {
+	*engineParser
+}
+
+func (profileParser) Detect(doc *goquery.Selection) bool {
+	return true
+}
+
+// NewProfileParser, veritabanındaki profili kural tabanlı bir ayrıştırıcıya çevirir.
+func NewProfileParser(profile models.ExtractionProfile) Parser {
+	content := []string{""} // Boşsa kapsayıcının tamamı alınır
+	if profile.Content != "" {
+		content = []string{profile.Content}
+	}
+
+	threadLink := profile.ThreadLink
+	if threadLink == "" {
+		threadLink = "a"
+	}
+
+	return profileParser{&engineParser{
+		name: "profile:" + profile.Name,
+		post: postRules{
+			Container: profile.PostContainer,
+			Author:    nonEmpty(profile.Author),
+			Date:      nonEmpty(profile.Date),
+			Content:   content,
+		},
+		row: rowRules{
+			Container: profile.ThreadList,
+			Title:     []string{textRule(threadLink)},
+			Link:      []string{hrefRule(threadLink)},
+		},
+		next: nonEmpty(hrefRule(profile.NextPage)),
+	}}
+}
+
+// ValidateProfile, profildeki tüm seçicilerin derlenebildiğini kontrol eder.
+func ValidateProfile(profile models.ExtractionProfile) error {
+	if profile.PostContainer == "" && profile.ThreadList == "" {
+		return fmt.Errorf("İleti kapsayıcısı veya konu listesi seçicisinden en az biri gerekli")
+	}
+	if profile.SiteID == nil && profile.HostPattern == "" {
+		return fmt.Errorf("Profil bir siteye veya host desenine bağlanmalı")
+	}
+	if _, err := path.Match(profile.HostPattern, ""); err != nil {
+		return fmt.Errorf("Geçersiz host deseni: %v", err)
+	}
+
+	return ValidateProfileRules(profile)
+}
+
+// ValidateProfileRules, sadece seçicileri kontrol eder (site/host bağı aranmaz).
+func ValidateProfileRules(profile models.ExtractionProfile) error {
+	fields := []struct{ name, rule string }{
+		{"thread_list", profile.ThreadList},
+		{"thread_link", profile.ThreadLink},
+		{"post_container", profile.PostContainer},
+		{"author", profile.Author},
+		{"date", profile.Date},
+		{"content", profile.Content},
+		{"next_page", profile.NextPage},
+	}
+	for _, f := range fields {
+		if err := ValidateRule(f.rule); err != nil {
+			return fmt.Errorf("Geçersiz seçici (%s): %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// MatchProfile, hedefe uygulanacak profili seçer. Siteye doğrudan bağlı profil
+// host desenine göre eşleşenden önce gelir; desenler arasında en uzunu kazanır.
+func MatchProfile(profiles []models.ExtractionProfile, siteID uint, targetURL string) *models.ExtractionProfile {
+	host := ""
+	if u, err := url.Parse(targetURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+
+	var best *models.ExtractionProfile
+	for i := range profiles {
+		p := &profiles[i]
+		if !p.IsActive {
+			continue
+		}
+		if siteID != 0 && p.SiteID != nil && *p.SiteID == siteID {
+			return p
+		}
+		if p.HostPattern == "" || host == "" {
+			continue
+		}
+		if ok, _ := path.Match(strings.ToLower(p.HostPattern), host); ok {
+			if best == nil || len(p.HostPattern) > len(best.HostPattern) {
+				best = p
+			}
+		}
+	}
+	return best
+}
+
+// xpathAttrStep, XPath ifadesinin bir öznitelik adımıyla (örn: "/@href") bitip bitmediğini yakalar.
+var xpathAttrStep = regexp.MustCompile(`/@[\w:-]+$`)
+
+func nonEmpty(rule string) []string {
+	if rule == "" {
+		return nil
+	}
+	return []string{rule}
+}
+
+// textRule, bağlantı seçicisinden metin kuralı üretir (öznitelik kısmı atılır).
+func textRule(rule string) string {
+	if strings.HasPrefix(rule, xpathPrefix) {
+		return rule
+	}
+	sel, _ := splitRule(rule)
+	return sel
+}
+
+// hrefRule, öznitelik belirtilmemiş bağlantı seçicisine href ekler.
+func hrefRule(rule string) string {
+	if rule == "" {
+		return ""
+	}
+	if strings.HasPrefix(rule, xpathPrefix) {
+		if xpathAttrStep.MatchString(rule) {
+			return rule
+		}
+		return rule + "/@href"
+	}
+	if _, attr := splitRule(rule); attr != "" {
+		return rule
+	}
+	return rule + "@href"
+}
@@ -0,0 +1,31 @@
+package scraper
+
+// smfParser, Simple Machines Forum 2.0/2.1 sayfalarını ayrıştırır.
+var smfParser = &engineParser{
+	name:       "smf",
+	generators: []string{"smf", "simple machines"},
+	markers:    "#forumposts .post_wrapper, #messageindex, #topic_container",
+	title:      []string{"#top_subject", "h3.catbg", "h1"},
+	post: postRules{
+		Container:    "#forumposts .post_wrapper",
+		ID:           []string{".inner[id^='msg_']@id", ".postarea [id^='subject_']@id"},
+		Author:       []string{".poster h4 a", ".poster h4"},
+		Date:         []string{".postinfo a.smalltext", ".keyinfo .smalltext"},
+		Content:      []string{".inner"},
+		LastEdited:   []string{".modified"},
+		Quote:        "blockquote",
+		QuoteAuthor:  []string{"cite"},
+		QuoteContent: []string{""},
+		Noise:        ".quoteheader, .quotefooter",
+	},
+	row: rowRules{
+		Container: "#messageindex tbody tr, #topic_container > div",
+		ID:        []string{"span[id^='msg_']@id"},
+		Title:     []string{"span[id^='msg_'] a"},
+		Link:      []string{"span[id^='msg_'] a@href"},
+		Author:    []string{"a[href*='action=profile']"},
+		Replies:   []string{".stats", "td.stats"},
+	},
+	next:       []string{"a.nav_page:has(.next_page)@href", ".pagelinks strong + a@href", "link[rel='next']@href"},
+	cleanQuote: trimQuoteSuffix,
+}
@@ -0,0 +1,32 @@
+package scraper
+
+// vbulletinParser, vBulletin 3.x/4.x sayfalarını ayrıştırır.
+var vbulletinParser = &engineParser{
+	name:       "vbulletin",
+	generators: []string{"vbulletin"},
+	markers:    "#postlist li.postcontainer, li.postbitlegacy, table[id^='post'] div[id^='post_message_'], li.threadbit",
+	title:      []string{"#pagetitle .threadtitle", "span.threadtitle", "h1", "td.navbar strong"},
+	post: postRules{
+		Container:    "li.postcontainer, li.postbitlegacy, table[id^='post']",
+		ID:           []string{"@id"},
+		Author:       []string{".username strong", "a.username", ".bigusername"},
+		Date:         []string{"span.date", "td.thead"},
+		Content:      []string{"blockquote.postcontent", "div[id^='post_message_']"},
+		LastEdited:   []string{"blockquote.postcontent.lastedited", ".lastedited"},
+		Quote:        "div.bbcode_container",
+		QuoteAuthor:  []string{".bbcode_postedby strong", "div.smallfont strong"},
+		QuoteContent: []string{".message", ".quote_container", "td.alt2"},
+		Noise:        ".signaturecontainer, .lastedited",
+	},
+	row: rowRules{
+		Container: "li.threadbit, tbody[id^='threadbits_forum'] tr",
+		ID:        []string{"@id", "a[id^='thread_title_']@id"},
+		Title:     []string{"a.title", "a[id^='thread_title_']"},
+		Link:      []string{"a.title@href", "a[id^='thread_title_']@href"},
+		Author:    []string{".author a.username", "div.smallfont span[style*='cursor']", "div.smallfont"},
+		Date:      []string{".author span.label@title", ".threadmeta .label"},
+		Replies:   []string{".threadstats li:first-child a", "td.alt2[title] a"},
+		Views:     []string{".threadstats li:nth-child(2)"},
+	},
+	next: []string{"a[rel='next']@href", ".pagenav a[title^='Next']@href"},
+}
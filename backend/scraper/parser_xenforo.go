@@ -0,0 +1,33 @@
+package scraper
+
+// xenforoParser, XenForo 2.x sayfalarını ayrıştırır.
+var xenforoParser = &engineParser{
+	name:       "xenforo",
+	generators: []string{"xenforo"},
+	markers:    "html#XF, article.message--post, .structItem--thread",
+	title:      []string{".p-title-value", "h1"},
+	post: postRules{
+		Container:    "article.message--post",
+		ID:           []string{"@data-content", "@id"},
+		Author:       []string{"@data-author", ".message-name .username", ".message-name"},
+		Date:         []string{".message-attribution-main time@datetime", "time.u-dt@datetime", "time@title"},
+		Content:      []string{".message-body .bbWrapper", ".message-content .bbWrapper", ".message-body"},
+		LastEdited:   []string{".message-lastEdit time@datetime", ".message-lastEdit"},
+		Reactions:    []string{".reactionsBar-link"},
+		Quote:        "blockquote.bbCodeBlock--quote",
+		QuoteAuthor:  []string{"@data-quote", ".bbCodeBlock-sourceJump"},
+		QuoteContent: []string{".bbCodeBlock-expandContent", ".bbCodeBlock-content"},
+		Noise:        ".bbCodeBlock-expandLink, .js-selectToQuoteEnd",
+	},
+	row: rowRules{
+		Container: ".structItem--thread",
+		ID:        []string{".structItem-title a[href*='threads/']@href", "@class"},
+		Title:     []string{".structItem-title a[href*='threads/']", ".structItem-title"},
+		Link:      []string{".structItem-title a[href*='threads/']@href", ".structItem-title a@href"},
+		Author:    []string{"@data-author", ".structItem-minor .username"},
+		Date:      []string{".structItem-startDate time@datetime", ".structItem-startDate"},
+		Replies:   []string{".structItem-cell--meta dl.pairs:first-child dd"},
+		Views:     []string{".structItem-cell--meta dl.structItem-minor dd"},
+	},
+	next: []string{"a.pageNav-jump--next@href", "link[rel='next']@href"},
+}
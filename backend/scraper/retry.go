@@ -0,0 +1,126 @@
+package scraper
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Yeniden deneme politikasının varsayılanları ve sınırları
+const (
+	DefaultMaxBackoff  = 30 * time.Second
+	DefaultRetryJitter = 0.2
+
+	maxRetryAttempts = 10
+	maxBaseBackoff   = 1 * time.Minute
+	maxMaxBackoff    = 5 * time.Minute
+)
+
+// DefaultRetryOn, politika hata sınıfı belirtmediğinde yeniden denenen kodlar.
+var DefaultRetryOn = []string{CodeProxyUnavailable, CodeTimeout, CodeHostUnreachable, CodeConnectionClosed, CodeHTTPStatus, CodeUnknown}
+
+// errorCodes, RetryOn içinde kullanılabilecek kodlar
+var errorCodes = map[string]bool{
+	CodeProxyUnavailable: true, CodeOnionInvalid: true, CodeTimeout: true, CodeHostUnreachable: true,
+	CodeConnectionRefused: true, CodeConnectionClosed: true, CodeDNS: true, CodeHTTPStatus: true,
+	CodeCaptcha: true, CodeInvalidURL: true, CodeUnknown: true,
+}
+
+// RetryPolicy, başarısız denemelerin ne zaman ve ne kadar bekleyerek tekrarlanacağını belirler.
+// Bekleme her denemede ikiye katlanır (BaseBackoff, 2x, 4x...) ve MaxBackoff ile sınırlanır.
+type RetryPolicy struct {
+	MaxAttempts   int           `json:"max_attempts"`   // İlk deneme dahil toplam deneme
+	BaseBackoff   time.Duration `json:"base_backoff"`   // İlk yeniden denemeden önceki bekleme
+	MaxBackoff    time.Duration `json:"max_backoff"`    // Beklemenin üst sınırı
+	Jitter        float64       `json:"jitter"`         // 0-1: beklemenin rastgele kısaltılabilecek oranı
+	RetryOn       []string      `json:"retry_on"`       // Yeniden denenecek hata kodları (boşsa DefaultRetryOn)
+	SwitchCircuit bool          `json:"switch_circuit"` // Yeniden denemeden önce yeni Tor devresi/uç noktası alınır
+}
+
+// Normalize, boş değerlere varsayılanları atar.
+func (p RetryPolicy) Normalize() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxRetries
+	}
+	if p.BaseBackoff <= 0 {
+		// Beklemesiz yeniden deneme hedefe art arda istek yağdırır
+		p.BaseBackoff = DefaultRetryDelay
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	if p.MaxBackoff < p.BaseBackoff {
+		p.MaxBackoff = p.BaseBackoff
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = DefaultRetryJitter
+	}
+	if len(p.RetryOn) == 0 {
+		p.RetryOn = DefaultRetryOn
+	}
+	return p
+}
+
+// Validate, Ayarlar sayfasından veya watchlist öğesinden gelen politikayı kontrol eder.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("Deneme sayısı 1-%d arasında olmalı", maxRetryAttempts)
+	}
+	if p.BaseBackoff <= 0 || p.BaseBackoff > maxBaseBackoff {
+		return fmt.Errorf("Denemeler arası bekleme 0'dan büyük ve en fazla %s olmalı", maxBaseBackoff)
+	}
+	if p.MaxBackoff < p.BaseBackoff || p.MaxBackoff > maxMaxBackoff {
+		return fmt.Errorf("En uzun bekleme ilk beklemeden kısa olamaz ve %s değerini aşamaz", maxMaxBackoff)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("Jitter 0 ile 1 arasında olmalı")
+	}
+	for _, code := range p.RetryOn {
+		if !errorCodes[code] {
+			return fmt.Errorf("Yeniden denenemeyecek veya tanımsız hata kodu: %s", code)
+		}
+	}
+	return nil
+}
+
+// ShouldRetry, hatanın politikaya göre yeniden denenip denenmeyeceğini söyler.
+// HTTP durum hatalarında sadece geçici olanlar (5xx, 429) tekrarlanır.
+func (p RetryPolicy) ShouldRetry(se *ScanError) bool {
+	if se == nil || (se.Code == CodeHTTPStatus && !se.Retryable) {
+		return false
+	}
+	for _, code := range p.RetryOn {
+		if code == se.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff, n. yeniden denemeden (1'den başlar) önce beklenecek süre.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := p.BaseBackoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		// Aynı anda düşen taramaların aynı anda tekrar denenmemesi için beklemenin bir kısmı rastgele kısaltılır
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// AttemptRecord, tek bir tarama denemesinin sonucu (iş kaydında saklanır).
+type AttemptRecord struct {
+	Attempt   int       `json:"attempt"`
+	Proxy     string    `json:"proxy,omitempty"` // Kimlik bilgisi olmadan uç nokta
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration"`          // Saniye
+	Backoff   float64   `json:"backoff,omitempty"` // Denemeden önce beklenen süre (saniye)
+	Code      string    `json:"code,omitempty"`
+	Status    int       `json:"status,omitempty"` // Hata yanıtının HTTP durum kodu (örn. ani 403)
+	Error     string    `json:"error,omitempty"`
+}
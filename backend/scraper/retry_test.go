@@ -0,0 +1,28 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyNeverRetriesWithoutBackoff(t *testing.T) {
+	for _, base := range []time.Duration{0, -time.Second} {
+		p := RetryPolicy{BaseBackoff: base, Jitter: 0}.Normalize()
+		if p.BaseBackoff != DefaultRetryDelay {
+			t.Errorf("bekleme %s için varsayılan atanmadı: %s", base, p.BaseBackoff)
+		}
+		if d := p.Backoff(1); d <= 0 {
+			t.Errorf("bekleme %s ile ilk yeniden deneme beklemesiz: %s", base, d)
+		}
+	}
+
+	valid := RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: 10 * time.Second, RetryOn: []string{CodeTimeout}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("geçerli politika reddedildi: %v", err)
+	}
+	zero := valid
+	zero.BaseBackoff = 0
+	if err := zero.Validate(); err == nil {
+		t.Error("beklemesiz politika kabul edildi")
+	}
+}
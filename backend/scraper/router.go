@@ -0,0 +1,106 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Yerleşik bağlantı yolları
+const (
+	RouteDirect = "direct"
+	RouteTor    = "tor"
+)
+
+// Route, bir hedefe hangi yoldan gidileceğini söyler.
+// Tor yolunda proxy Tor havuzundan alınır; diğer yollarda Proxy sabittir (direct için boş).
+type Route struct {
+	Name  string `json:"name"`
+	Proxy string `json:"-"` // Kimlik bilgisi içerebilir, API'ye yazılmaz
+}
+
+// RouteRule, host soneki ile yol adını eşleştirir.
+type RouteRule struct {
+	Suffix string
+	Route  string
+}
+
+// Router, hedef host'a göre yol seçer. Sıfır değeri her şeyi Tor'a yönlendirir.
+type Router struct {
+	def     string
+	rules   []RouteRule       // Uzun sonekler önce
+	proxies map[string]string // Yol adı -> proxy adresi (Tor ve direct dışındakiler)
+}
+
+// NewRouter, kuralları en uzun sonek önce gelecek şekilde sıralar.
+// proxies, Tor ve direct dışındaki yolların (örn. i2p, kurumsal proxy) adreslerini içerir.
+func NewRouter(def string, rules []RouteRule, proxies map[string]string) *Router {
+	r := &Router{def: def, proxies: make(map[string]string, len(proxies))}
+	for name, p := range proxies {
+		r.proxies[name] = p
+	}
+	for _, rule := range rules {
+		r.rules = append(r.rules, RouteRule{Suffix: strings.ToLower(rule.Suffix), Route: rule.Route})
+	}
+	sort.SliceStable(r.rules, func(i, j int) bool {
+		return len(strings.Trim(r.rules[i].Suffix, ".")) > len(strings.Trim(r.rules[j].Suffix, "."))
+	})
+	return r
+}
+
+// Resolve, hedef için yolu seçer. override boş değilse kurallar atlanır.
+func (r *Router) Resolve(targetURL, override string) (Route, error) {
+	name := override
+	if name == "" {
+		name = r.def
+		if rule, ok := r.Match(hostOf(targetURL)); ok {
+			name = rule.Route
+		}
+	}
+	if name == "" {
+		name = RouteTor
+	}
+	return r.Lookup(name)
+}
+
+// Lookup, yol adını adrese çevirir.
+func (r *Router) Lookup(name string) (Route, error) {
+	switch name {
+	case RouteDirect, RouteTor:
+		return Route{Name: name}, nil
+	}
+	if p, ok := r.proxies[name]; ok {
+		return Route{Name: name, Proxy: p}, nil
+	}
+	return Route{}, fmt.Errorf("Tanımsız bağlantı yolu: %s", name)
+}
+
+// Match, host'a uyan en uzun sonekli kuralı döndürür.
+func (r *Router) Match(host string) (RouteRule, bool) {
+	host = strings.TrimRight(strings.ToLower(host), ".")
+	for _, rule := range r.rules {
+		if matchHostSuffix(host, rule.Suffix) {
+			return rule, true
+		}
+	}
+	return RouteRule{}, false
+}
+
+// matchHostSuffix, "pastebin.com" sonekinin pastebin.com ve alt alan adlarıyla,
+// ".onion" sonekinin ise tüm .onion adresleriyle eşleşmesini sağlar.
+func matchHostSuffix(host, suffix string) bool {
+	suffix = strings.Trim(suffix, ".")
+	if suffix == "" {
+		return false
+	}
+	return host == suffix || strings.HasSuffix(host, "."+suffix)
+}
+
+func hostOf(targetURL string) string {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
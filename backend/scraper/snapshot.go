@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// SnapshotPage, arşivden çevrimdışı ayrıştırmada sunulan kayıtlı sayfa.
+type SnapshotPage struct {
+	URL         string // İstenen adres
+	FinalURL    string // Yönlendirmelerden sonraki adres (boşsa URL)
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// OfflineTransport, istekleri ağa çıkmadan kayıtlı sayfalardan yanıtlar.
+// Yönlendirilmiş sayfalar aynı zinciri izlemek için 302 ile son adrese gönderilir; arşivde olmayan sayfalar 404 döner.
+type OfflineTransport struct {
+	pages     map[string]SnapshotPage
+	redirects map[string]string
+}
+
+// NewOfflineTransport, sayfaları istenen ve son adreslerine göre dizinler.
+func NewOfflineTransport(pages []SnapshotPage) *OfflineTransport {
+	t := &OfflineTransport{pages: make(map[string]SnapshotPage), redirects: make(map[string]string)}
+	for _, p := range pages {
+		final := p.FinalURL
+		if final == "" {
+			final = p.URL
+		}
+		t.pages[final] = p
+		if final != p.URL {
+			t.redirects[p.URL] = final
+		}
+	}
+	return t
+}
+
+func (t *OfflineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := req.URL.String()
+	if to, ok := t.redirects[u]; ok {
+		if _, stored := t.pages[u]; !stored {
+			return t.response(req, http.StatusFound, http.Header{"Location": {to}}, nil), nil
+		}
+	}
+
+	page, ok := t.pages[u]
+	if !ok {
+		return t.response(req, http.StatusNotFound, http.Header{}, []byte("Sayfa arşivde yok")), nil
+	}
+	status := page.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := http.Header{}
+	if page.ContentType != "" {
+		header.Set("Content-Type", page.ContentType)
+	}
+	return t.response(req, status, header, page.Body), nil
+}
+
+func (t *OfflineTransport) response(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
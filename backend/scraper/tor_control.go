@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// SendNewnym, Tor kontrol protokolüyle (control-spec) kimlik doğrular ve SIGNAL NEWNYM gönderir.
+// Tor bundan sonra açılan bağlantılar için yeni devre kullanır. Şifre boşsa kimlik doğrulamasız
+// (CookieAuthentication ve HashedControlPassword kapalı) kontrol portu varsayılır.
+func SendNewnym(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), addr, password string) error {
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Tor kontrol portuna bağlanılamadı: %v", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(torDialTimeout))
+	}
+
+	r := bufio.NewReader(conn)
+	if err := torCommand(conn, r, "AUTHENTICATE "+quoteControlString(password)); err != nil {
+		return fmt.Errorf("Tor kontrol kimlik doğrulaması başarısız: %v", err)
+	}
+	if err := torCommand(conn, r, "SIGNAL NEWNYM"); err != nil {
+		return fmt.Errorf("NEWNYM reddedildi: %v", err)
+	}
+	torCommand(conn, r, "QUIT")
+	return nil
+}
+
+// torCommand, komutu gönderip yanıtın son satırını bekler. 250 dışındaki durum kodları hatadır.
+func torCommand(conn net.Conn, r *bufio.Reader, command string) error {
+	if _, err := conn.Write([]byte(command + "\r\n")); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) < 4 {
+			return fmt.Errorf("geçersiz yanıt: %q", line)
+		}
+		// "250-..." ve "250+..." ara satırlardır, "250 OK" yanıtın sonudur
+		if line[3] != ' ' {
+			continue
+		}
+		if line[:3] != "250" {
+			return fmt.Errorf("%s", line)
+		}
+		return nil
+	}
+}
+
+// quoteControlString, şifreyi kontrol protokolünün tırnaklı dize biçimine çevirir.
+func quoteControlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,208 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeControl: Komutları kaydeden sahte Tor kontrol portu. replies, komut adına (AUTHENTICATE, SIGNAL) göre
+// yanıtı verir; tanımsız komutlar "250 OK" alır.
+func fakeControl(t *testing.T, replies map[string]string) (string, func() []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("dinleyici açılamadı: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var commands []string
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					command := strings.TrimRight(line, "\r\n")
+					mu.Lock()
+					commands = append(commands, command)
+					mu.Unlock()
+
+					reply, ok := replies[strings.Fields(command)[0]]
+					if !ok {
+						reply = "250 OK"
+					}
+					conn.Write([]byte(reply + "\r\n"))
+					if command == "QUIT" || !strings.HasSuffix(reply, "250 OK") {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), commands...)
+	}
+}
+
+func TestSendNewnym(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		replies  map[string]string
+		wantErr  string
+		commands []string
+	}{
+		{
+			name:     "şifresiz",
+			commands: []string{`AUTHENTICATE ""`, "SIGNAL NEWNYM", "QUIT"},
+		},
+		{
+			name:     "tırnaklı şifre",
+			password: `gi"zli\sifre`,
+			commands: []string{`AUTHENTICATE "gi\"zli\\sifre"`, "SIGNAL NEWNYM", "QUIT"},
+		},
+		{
+			name:     "çok satırlı yanıt",
+			replies:  map[string]string{"AUTHENTICATE": "250-Bilgi satırı\r\n250 OK"},
+			commands: []string{`AUTHENTICATE ""`, "SIGNAL NEWNYM", "QUIT"},
+		},
+		{
+			name:     "kimlik doğrulama reddi",
+			password: "yanlis",
+			replies:  map[string]string{"AUTHENTICATE": "515 Authentication failed: Password did not match HashedControlPassword value from configuration"},
+			wantErr:  "Tor kontrol kimlik doğrulaması başarısız: 515 Authentication failed",
+			commands: []string{`AUTHENTICATE "yanlis"`},
+		},
+		{
+			name:     "sinyal reddi",
+			replies:  map[string]string{"SIGNAL": "552 Unrecognized signal"},
+			wantErr:  "NEWNYM reddedildi: 552 Unrecognized signal",
+			commands: []string{`AUTHENTICATE ""`, "SIGNAL NEWNYM"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, commands := fakeControl(t, tt.replies)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err := SendNewnym(ctx, (&net.Dialer{}).DialContext, addr, tt.password)
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("beklenmeyen hata: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)):
+				t.Fatalf("%q ile başlayan hata bekleniyordu, %v", tt.wantErr, err)
+			}
+			// QUIT yanıtı beklenmez; sunucunun kaydetmesi için kısa süre tanınır
+			deadline := time.Now().Add(time.Second)
+			for len(commands()) < len(tt.commands) && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := commands(); !reflect.DeepEqual(got, tt.commands) {
+				t.Errorf("komutlar %q, beklenen %q", got, tt.commands)
+			}
+		})
+	}
+}
+
+func TestSendNewnymUnreachableControlPort(t *testing.T) {
+	err := SendNewnym(context.Background(), (&net.Dialer{}).DialContext, closedAddr(t), "")
+	if err == nil || !strings.HasPrefix(err.Error(), "Tor kontrol portuna bağlanılamadı") {
+		t.Fatalf("bağlantı hatası bekleniyordu, %v", err)
+	}
+}
+
+func TestTorPoolNewCircuit(t *testing.T) {
+	socksA, _ := fakeSocks(t, []byte{0x05, 0x00})
+	socksB, _ := fakeSocks(t, []byte{0x05, 0x00})
+	controlA, commandsA := fakeControl(t, nil)
+	controlB, _ := fakeControl(t, map[string]string{"AUTHENTICATE": "515 Authentication failed"})
+
+	pool, err := NewTorPool([]TorEndpoint{
+		{Proxy: "socks5://" + socksA, Control: controlA, ControlPassword: "sifre"},
+		{Proxy: "socks5://" + socksB, Control: controlB},
+	}, false)
+	if err != nil {
+		t.Fatalf("havuz kurulamadı: %v", err)
+	}
+	pool.CheckAll()
+	labelA, labelB := pool.Stats()[0].Proxy, pool.Stats()[1].Proxy
+
+	if err := pool.NewCircuit(labelA); err != nil {
+		t.Fatalf("NEWNYM gönderilemedi: %v", err)
+	}
+	if got := commandsA(); len(got) < 2 || got[0] != `AUTHENTICATE "sifre"` || got[1] != "SIGNAL NEWNYM" {
+		t.Errorf("kontrol portuna giden komutlar %q", got)
+	}
+
+	err = pool.NewCircuit("")
+	if err == nil || !strings.Contains(err.Error(), labelB+": ") || strings.Contains(err.Error(), labelA+": ") {
+		t.Errorf("yalnızca reddeden uç nokta hata vermeli: %v", err)
+	}
+	if err := pool.NewCircuit("socks5://127.0.0.1:1"); err == nil {
+		t.Error("havuzda olmayan proxy için hata bekleniyordu")
+	}
+
+	stats := pool.Stats()
+	if stats[0].Newnyms != 2 || stats[0].LastNewnym == nil {
+		t.Errorf("başarılı NEWNYM sayılmadı: %+v", stats[0])
+	}
+	if stats[1].Newnyms != 0 {
+		t.Errorf("reddedilen NEWNYM sayıldı: %+v", stats[1])
+	}
+}
+
+func TestTorPoolRotateRespectsInterval(t *testing.T) {
+	socks, _ := fakeSocks(t, []byte{0x05, 0x00})
+	control, commands := fakeControl(t, nil)
+	pool, err := NewTorPool([]TorEndpoint{{Proxy: "socks5://" + socks, Control: control}}, false)
+	if err != nil {
+		t.Fatalf("havuz kurulamadı: %v", err)
+	}
+	pool.CheckAll()
+	lease, _ := pool.Acquire("job")
+
+	pool.Rotate(lease)
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats()[0].Newnyms == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pool.Stats()[0].Newnyms != 1 {
+		t.Fatal("Rotate NEWNYM göndermedi")
+	}
+
+	// Aralık dolmadan gelen ikinci istek Tor'a gitmez
+	if err := pool.newnym(lease.state, false); err != nil {
+		t.Fatalf("aralık içindeki istek hata verdi: %v", err)
+	}
+	if n := pool.Stats()[0].Newnyms; n != 1 {
+		t.Errorf("aralık sınırı aşıldı: %d NEWNYM", n)
+	}
+	signals := 0
+	for _, c := range commands() {
+		if c == "SIGNAL NEWNYM" {
+			signals++
+		}
+	}
+	if signals != 1 {
+		t.Errorf("kontrol portuna %d SIGNAL NEWNYM gitti", signals)
+	}
+}
@@ -0,0 +1,392 @@
+package scraper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tor havuzu ayarları
+const (
+	DefaultTorHealthInterval = 30 * time.Second
+	torDialTimeout           = 5 * time.Second
+	torNewnymInterval        = 10 * time.Second // Tor bu aralıktan sık gelen NEWNYM isteklerini geciktirir
+	torLatencyAlpha          = 0.3              // Gecikme ortalamasında son ölçümün ağırlığı
+)
+
+// ErrNoHealthyProxy, havuzda bağlantı kabul eden Tor uç noktası kalmadığında döner (kodu CodeProxyUnavailable).
+var ErrNoHealthyProxy = &ScanError{Code: CodeProxyUnavailable, Message: "Sistem Tor (9050) veya Tor Browser (9150) açık değil. Lütfen Tor bağlantınızı kontrol edin."}
+
+// TorEndpoint, havuzdaki bir SOCKS5 proxy ve varsa ona bağlı Tor kontrol portudur.
+type TorEndpoint struct {
+	Proxy           string // socks5://host:port
+	Control         string // host:port, boşsa NEWNYM gönderilmez
+	ControlPassword string
+}
+
+// DefaultTorEndpoints, ayar verilmediğinde denenen yerel Tor ve Tor Browser portlarıdır.
+// Docker için host adresleri de eklenir.
+func DefaultTorEndpoints() []TorEndpoint {
+	var endpoints []TorEndpoint
+	for _, host := range []string{"127.0.0.1", "host.docker.internal"} {
+		endpoints = append(endpoints,
+			TorEndpoint{Proxy: "socks5://" + host + ":9050", Control: host + ":9051"},
+			TorEndpoint{Proxy: "socks5://" + host + ":9150", Control: host + ":9151"},
+		)
+	}
+	return endpoints
+}
+
+// ProxyStats, bir uç noktanın panelde gösterilen sağlık ve kullanım bilgisidir.
+type ProxyStats struct {
+	Proxy        string     `json:"proxy"`
+	Control      string     `json:"control,omitempty"`
+	Healthy      bool       `json:"healthy"`
+	LatencyMs    float64    `json:"latency_ms"`     // Son sağlık kontrolündeki SOCKS el sıkışma süresi
+	AvgLatencyMs float64    `json:"avg_latency_ms"` // Sağlık kontrollerinin üssel ortalaması
+	Requests     int64      `json:"requests"`       // Bu uç noktayla yapılan tarama denemeleri
+	Failures     int64      `json:"failures"`
+	ErrorRate    float64    `json:"error_rate"` // 0-1 arası
+	LastError    string     `json:"last_error,omitempty"`
+	LastCheck    *time.Time `json:"last_check,omitempty"`
+	Newnyms      int64      `json:"newnyms"`
+	LastNewnym   *time.Time `json:"last_newnym,omitempty"`
+}
+
+// ProxySource, her tarama denemesi için proxy sağlar ve sonucu geri alır.
+// Rotate, yeniden denemeden önce uç noktadan yeni devre ister.
+type ProxySource interface {
+	Acquire(isolationKey string) (ProxyLease, error)
+	Release(lease ProxyLease, err error)
+	Rotate(lease ProxyLease)
+}
+
+// ProxyLease, tek bir tarama denemesine verilen proxy adresidir.
+// İzolasyon açıksa adres, Tor'un ayrı devre açması için denemeye özel SOCKS kimlik bilgisi içerir.
+type ProxyLease struct {
+	Proxy    string // Colly'ye verilen adres (kimlik bilgisiyle birlikte)
+	Endpoint string // Kimlik bilgisi olmadan uç nokta (log ve olaylar için)
+	state    *torEndpointState
+}
+
+type torEndpointState struct {
+	TorEndpoint
+	host  string // SOCKS sunucusunun host:port değeri
+	socks bool   // false ise (http proxy) sağlık kontrolü sadece TCP bağlantısıdır
+	stats ProxyStats
+}
+
+// TorPool, birden fazla Tor SOCKS uç noktasını sağlık kontrolüyle yönetir.
+// Denemeler sağlıklı uç noktalar arasında sırayla dağıtılır; yeniden denemeden önce Rotate ile NEWNYM gönderilir.
+type TorPool struct {
+	mu        sync.Mutex
+	endpoints []*torEndpointState
+	next      int
+	isolate   bool
+	stop      chan struct{}
+
+	// dial, testlerde sahte SOCKS/kontrol sunucusuna yönlendirmek için değiştirilebilir
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// OnHealthChange, bir uç noktanın sağlık durumu değiştiğinde çağrılır (kilit dışında)
+	OnHealthChange func(ProxyStats)
+}
+
+// NewTorPool, uç noktaları doğrulayıp havuzu kurar. Sağlık kontrolü yapılana kadar hiçbir uç nokta sağlıklı sayılmaz.
+func NewTorPool(endpoints []TorEndpoint, isolate bool) (*TorPool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("Tor havuzu için en az bir proxy gerekli")
+	}
+	p := &TorPool{isolate: isolate, dial: (&net.Dialer{}).DialContext}
+	for _, ep := range endpoints {
+		u, err := url.Parse(ep.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("Geçersiz proxy adresi: %s", ep.Proxy)
+		}
+		p.endpoints = append(p.endpoints, &torEndpointState{
+			TorEndpoint: ep,
+			host:        u.Host,
+			socks:       strings.HasPrefix(u.Scheme, "socks5"),
+			stats:       ProxyStats{Proxy: ProxyLabel(ep.Proxy), Control: ep.Control},
+		})
+	}
+	return p, nil
+}
+
+// StartHealthChecks, tüm uç noktaları hemen ve ardından her aralıkta kontrol eder.
+func (p *TorPool) StartHealthChecks(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultTorHealthInterval
+	}
+	p.mu.Lock()
+	if p.stop != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.stop = make(chan struct{})
+	stop := p.stop
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.CheckAll()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close, sağlık kontrollerini durdurur.
+func (p *TorPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// CheckAll, uç noktaları paralel olarak kontrol eder.
+func (p *TorPool) CheckAll() {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *torEndpointState) {
+			defer wg.Done()
+			p.check(ep)
+		}(ep)
+	}
+	wg.Wait()
+}
+
+// check, SOCKS5 el sıkışmasını (veya http proxy için TCP bağlantısını) ölçer.
+func (p *TorPool) check(ep *torEndpointState) {
+	start := time.Now()
+	err := p.probe(ep)
+	latency := float64(time.Since(start).Microseconds()) / 1000
+
+	now := time.Now()
+	p.mu.Lock()
+	was := ep.stats.Healthy
+	ep.stats.LastCheck = &now
+	ep.stats.Healthy = err == nil
+	if err != nil {
+		ep.stats.LastError = err.Error()
+	} else {
+		ep.stats.LatencyMs = latency
+		if ep.stats.AvgLatencyMs == 0 {
+			ep.stats.AvgLatencyMs = latency
+		} else {
+			ep.stats.AvgLatencyMs = torLatencyAlpha*latency + (1-torLatencyAlpha)*ep.stats.AvgLatencyMs
+		}
+	}
+	snapshot := ep.stats
+	p.mu.Unlock()
+
+	if was != snapshot.Healthy && p.OnHealthChange != nil {
+		p.OnHealthChange(snapshot)
+	}
+}
+
+func (p *TorPool) probe(ep *torEndpointState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), torDialTimeout)
+	defer cancel()
+	conn, err := p.dial(ctx, "tcp", ep.host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if !ep.socks {
+		return nil
+	}
+
+	conn.SetDeadline(time.Now().Add(torDialTimeout))
+	// Sürüm 5, iki yöntem: kimlik doğrulamasız (0x00) ve kullanıcı/şifre (0x02)
+	if _, err := conn.Write([]byte{0x05, 0x02, 0x00, 0x02}); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS yanıtı okunamadı: %v", err)
+	}
+	if reply[0] != 0x05 || reply[1] == 0xFF {
+		return fmt.Errorf("SOCKS5 sunucusu değil veya yöntem kabul edilmedi")
+	}
+	return nil
+}
+
+// Acquire, sıradaki sağlıklı uç noktayı verir. Sağlıklı uç nokta yoksa hemen yeniden kontrol edilir.
+// İzolasyon açıksa her çağrı yeni SOCKS kimlik bilgisi, dolayısıyla yeni bir Tor devresi alır.
+func (p *TorPool) Acquire(isolationKey string) (ProxyLease, error) {
+	ep := p.pick()
+	if ep == nil {
+		p.CheckAll()
+		if ep = p.pick(); ep == nil {
+			return ProxyLease{}, ErrNoHealthyProxy
+		}
+	}
+
+	lease := ProxyLease{Proxy: ep.Proxy, Endpoint: ep.stats.Proxy, state: ep}
+	if p.isolate && ep.socks {
+		u, _ := url.Parse(ep.Proxy)
+		u.User = url.UserPassword("galileoff-"+isolationKey, randomHex(8))
+		lease.Proxy = u.String()
+	}
+	return lease, nil
+}
+
+func (p *TorPool) pick() *torEndpointState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := 0; i < len(p.endpoints); i++ {
+		ep := p.endpoints[(p.next+i)%len(p.endpoints)]
+		if ep.stats.Healthy {
+			p.next = (p.next + i + 1) % len(p.endpoints)
+			return ep
+		}
+	}
+	return nil
+}
+
+// Release, deneme sonucunu kaydeder. İptal edilen denemeler sayılmaz.
+// Proxy'ye bağlanılamadıysa uç nokta sağlıksız sayılır.
+func (p *TorPool) Release(lease ProxyLease, err error) {
+	ep := lease.state
+	if ep == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCancelled) {
+		return
+	}
+
+	p.mu.Lock()
+	ep.stats.Requests++
+	if err != nil {
+		ep.stats.Failures++
+		ep.stats.LastError = err.Error()
+	}
+	ep.stats.ErrorRate = float64(ep.stats.Failures) / float64(ep.stats.Requests)
+	wasHealthy := ep.stats.Healthy
+	if errors.Is(err, ErrProxyUnavailable) {
+		ep.stats.Healthy = false
+	}
+	snapshot := ep.stats
+	p.mu.Unlock()
+
+	if wasHealthy && !snapshot.Healthy && p.OnHealthChange != nil {
+		p.OnHealthChange(snapshot)
+	}
+}
+
+// Rotate, uç noktanın Tor örneğine arka planda NEWNYM gönderir (aralık sınırına uyulur).
+// Sağlıksız uç noktalar atlanır; onlar için zaten başka uç nokta seçilir.
+func (p *TorPool) Rotate(lease ProxyLease) {
+	ep := lease.state
+	if ep == nil {
+		return
+	}
+	p.mu.Lock()
+	healthy := ep.stats.Healthy
+	p.mu.Unlock()
+	if healthy {
+		go p.newnym(ep, false)
+	}
+}
+
+// NewCircuit, verilen uç noktanın (boşsa tümünün) Tor örneğine NEWNYM gönderir.
+func (p *TorPool) NewCircuit(proxy string) error {
+	var errs []string
+	found := false
+	for _, ep := range p.endpoints {
+		if proxy != "" && ep.stats.Proxy != proxy {
+			continue
+		}
+		found = true
+		if err := p.newnym(ep, true); err != nil {
+			errs = append(errs, ep.stats.Proxy+": "+err.Error())
+		}
+	}
+	if !found {
+		return fmt.Errorf("Proxy havuzda yok: %s", proxy)
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// newnym, kontrol portu tanımlı uç nokta için NEWNYM gönderir. force değilse aralık sınırına uyulur.
+func (p *TorPool) newnym(ep *torEndpointState, force bool) error {
+	if ep.Control == "" {
+		return fmt.Errorf("Kontrol portu tanımlı değil")
+	}
+
+	now := time.Now()
+	p.mu.Lock()
+	if !force && ep.stats.LastNewnym != nil && now.Sub(*ep.stats.LastNewnym) < torNewnymInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	ep.stats.LastNewnym = &now
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), torDialTimeout)
+	defer cancel()
+	if err := SendNewnym(ctx, p.dial, ep.Control, ep.ControlPassword); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	ep.stats.Newnyms++
+	p.mu.Unlock()
+	return nil
+}
+
+// Stats, uç noktaların anlık durumunu döndürür.
+func (p *TorPool) Stats() []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]ProxyStats, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		stats[i] = ep.stats
+	}
+	return stats
+}
+
+// Healthy, en az bir uç noktanın bağlantı kabul edip etmediğini söyler.
+func (p *TorPool) Healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		if ep.stats.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxyLabel, proxy adresini kullanıcı adı ve şifre olmadan döndürür (log ve panel için).
+func ProxyLabel(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return proxy
+	}
+	u.User = nil
+	return u.String()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
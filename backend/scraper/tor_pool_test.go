@@ -0,0 +1,242 @@
+package scraper
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeSocks: SOCKS5 selamlamasını okuyup verilen yanıtı dönen sahte proxy, gelen selamlamaları kaydeder
+func fakeSocks(t *testing.T, reply []byte) (string, func() [][]byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("dinleyici açılamadı: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var greetings [][]byte
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				greeting := make([]byte, 4)
+				if _, err := io.ReadFull(conn, greeting); err != nil {
+					return
+				}
+				mu.Lock()
+				greetings = append(greetings, greeting)
+				mu.Unlock()
+				conn.Write(reply)
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), func() [][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([][]byte(nil), greetings...)
+	}
+}
+
+// closedAddr: Bağlantı kabul etmeyen bir adres döndürür
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("dinleyici açılamadı: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// healthyPool: Tüm uç noktaları sahte SOCKS5 sunucusuna bağlı ve kontrol edilmiş havuz kurar
+func healthyPool(t *testing.T, n int, isolate bool) *TorPool {
+	t.Helper()
+	var endpoints []TorEndpoint
+	for i := 0; i < n; i++ {
+		addr, _ := fakeSocks(t, []byte{0x05, 0x00})
+		endpoints = append(endpoints, TorEndpoint{Proxy: "socks5://" + addr})
+	}
+	pool, err := NewTorPool(endpoints, isolate)
+	if err != nil {
+		t.Fatalf("havuz kurulamadı: %v", err)
+	}
+	pool.CheckAll()
+	return pool
+}
+
+func TestTorPoolHealthChecks(t *testing.T) {
+	okAddr, greetings := fakeSocks(t, []byte{0x05, 0x00})
+	rejectAddr, _ := fakeSocks(t, []byte{0x05, 0xFF})
+	notSocksAddr, _ := fakeSocks(t, []byte("HT"))
+	httpAddr, _ := fakeSocks(t, nil)
+	downAddr := closedAddr(t)
+
+	pool, err := NewTorPool([]TorEndpoint{
+		{Proxy: "socks5://" + okAddr},
+		{Proxy: "socks5h://" + rejectAddr},
+		{Proxy: "socks5://" + notSocksAddr},
+		{Proxy: "http://" + httpAddr},
+		{Proxy: "socks5://" + downAddr},
+	}, false)
+	if err != nil {
+		t.Fatalf("havuz kurulamadı: %v", err)
+	}
+	if pool.Healthy() {
+		t.Fatal("kontrol edilmeden sağlıklı uç nokta olmamalı")
+	}
+
+	var mu sync.Mutex
+	var changes []ProxyStats
+	pool.OnHealthChange = func(s ProxyStats) {
+		mu.Lock()
+		changes = append(changes, s)
+		mu.Unlock()
+	}
+	pool.CheckAll()
+
+	want := []bool{true, false, false, true, false}
+	stats := pool.Stats()
+	for i, s := range stats {
+		if s.Healthy != want[i] {
+			t.Errorf("%s sağlıklı=%v, beklenen %v (%s)", s.Proxy, s.Healthy, want[i], s.LastError)
+		}
+		if s.LastCheck == nil {
+			t.Errorf("%s için kontrol zamanı yazılmadı", s.Proxy)
+		}
+		if !s.Healthy && s.LastError == "" {
+			t.Errorf("%s için hata kaydedilmedi", s.Proxy)
+		}
+	}
+	if stats[0].AvgLatencyMs <= 0 || stats[0].AvgLatencyMs != stats[0].LatencyMs {
+		t.Errorf("ilk ölçümde ortalama gecikme son gecikmeye eşit olmalı: %+v", stats[0])
+	}
+	if got := greetings(); len(got) != 1 || !reflect.DeepEqual(got[0], []byte{0x05, 0x02, 0x00, 0x02}) {
+		t.Errorf("SOCKS5 selamlaması hatalı: %v", got)
+	}
+	// Sadece sağlıksızdan sağlıklıya geçenler bildirilir
+	if len(changes) != 2 {
+		t.Errorf("2 durum değişikliği bekleniyordu, %d", len(changes))
+	}
+
+	mu.Lock()
+	changes = nil
+	mu.Unlock()
+	pool.CheckAll()
+	if len(changes) != 0 {
+		t.Errorf("durum değişmeden bildirim yapıldı: %+v", changes)
+	}
+	if !pool.Healthy() {
+		t.Error("havuzda sağlıklı uç nokta olmalı")
+	}
+}
+
+func TestTorPoolPickRoundRobin(t *testing.T) {
+	pool := healthyPool(t, 3, false)
+	labels := func(n int) []string {
+		var out []string
+		for i := 0; i < n; i++ {
+			lease, err := pool.Acquire("job")
+			if err != nil {
+				t.Fatalf("proxy alınamadı: %v", err)
+			}
+			out = append(out, lease.Endpoint)
+		}
+		return out
+	}
+	stats := pool.Stats()
+	a, b, c := stats[0].Proxy, stats[1].Proxy, stats[2].Proxy
+
+	if got := labels(4); !reflect.DeepEqual(got, []string{a, b, c, a}) {
+		t.Errorf("sıra %v, beklenen %v", got, []string{a, b, c, a})
+	}
+
+	// Sağlıksız uç nokta atlanır, sıra bir sonrakinden devam eder
+	pool.mu.Lock()
+	pool.endpoints[1].stats.Healthy = false
+	pool.mu.Unlock()
+	if got := labels(3); !reflect.DeepEqual(got, []string{c, a, c}) {
+		t.Errorf("sağlıksız uç nokta atlanmadı: %v", got)
+	}
+}
+
+func TestTorPoolAcquireRechecksWhenAllUnhealthy(t *testing.T) {
+	pool, err := NewTorPool([]TorEndpoint{{Proxy: "socks5://" + closedAddr(t)}}, false)
+	if err != nil {
+		t.Fatalf("havuz kurulamadı: %v", err)
+	}
+	if _, err := pool.Acquire("job"); !errors.Is(err, ErrNoHealthyProxy) {
+		t.Fatalf("ErrNoHealthyProxy bekleniyordu, %v", err)
+	}
+
+	// Uç nokta sonradan açılırsa Acquire yeniden kontrol edip onu verir
+	addr, _ := fakeSocks(t, []byte{0x05, 0x00})
+	pool.endpoints[0].host = addr
+	if _, err := pool.Acquire("job"); err != nil {
+		t.Fatalf("yeniden kontrolden sonra proxy alınamadı: %v", err)
+	}
+}
+
+func TestTorPoolIsolation(t *testing.T) {
+	pool := healthyPool(t, 1, true)
+	first, _ := pool.Acquire("job-1")
+	second, _ := pool.Acquire("job-1")
+
+	u, err := url.Parse(first.Proxy)
+	if err != nil || u.User == nil {
+		t.Fatalf("izolasyonda kimlik bilgisi bekleniyordu: %q", first.Proxy)
+	}
+	if u.User.Username() != "galileoff-job-1" {
+		t.Errorf("kullanıcı adı %q", u.User.Username())
+	}
+	if first.Proxy == second.Proxy {
+		t.Error("her deneme ayrı devre için farklı şifre almalı")
+	}
+	if first.Endpoint != ProxyLabel(first.Proxy) || first.Endpoint != pool.Stats()[0].Proxy {
+		t.Errorf("uç nokta etiketinde kimlik bilgisi olmamalı: %q", first.Endpoint)
+	}
+}
+
+func TestTorPoolReleaseMarksUnavailableProxyUnhealthy(t *testing.T) {
+	pool := healthyPool(t, 2, false)
+	var changes []ProxyStats
+	pool.OnHealthChange = func(s ProxyStats) { changes = append(changes, s) }
+
+	lease, _ := pool.Acquire("job")
+	pool.Release(lease, nil)
+	pool.Release(lease, NewHTTPStatusError(503))
+	pool.Release(lease, ErrCancelled)
+	if s := pool.Stats()[0]; !s.Healthy || s.Requests != 2 || s.Failures != 1 || s.ErrorRate != 0.5 {
+		t.Fatalf("hedef hatası uç noktayı etkilememeli, iptal sayılmamalı: %+v", s)
+	}
+	if len(changes) != 0 {
+		t.Errorf("beklenmeyen durum değişikliği: %+v", changes)
+	}
+
+	pool.Release(lease, ErrProxyUnavailable.wrap(errors.New("connection refused")))
+	s := pool.Stats()[0]
+	if s.Healthy || s.Failures != 2 {
+		t.Fatalf("proxy hatasından sonra uç nokta sağlıksız olmalı: %+v", s)
+	}
+	if len(changes) != 1 || changes[0].Proxy != lease.Endpoint || changes[0].Healthy {
+		t.Errorf("sağlıksız geçiş bildirilmedi: %+v", changes)
+	}
+
+	// Sonraki denemeler diğer uç noktaya gider
+	for i := 0; i < 3; i++ {
+		next, err := pool.Acquire("job")
+		if err != nil || next.Endpoint == lease.Endpoint {
+			t.Fatalf("sağlıksız uç nokta yeniden verildi: %q, %v", next.Endpoint, err)
+		}
+	}
+}
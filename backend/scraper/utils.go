@@ -3,10 +3,12 @@ package scraper
 import (
 	"net/url"
 	"strings"
+	"unicode/utf8"
 )
 
 // NormalizeURL, verilen urlin başında http/https ve sonunda .onion gibi uzantıların olup olmadığını kontrol eder ve eksikse tamamlar.
-func NormalizeURL(input string) string {
+// completeOnion false ise host olduğu gibi bırakılır (clearnet ve I2P hedefleri için).
+func NormalizeURL(input string, completeOnion bool) string {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return ""
@@ -24,7 +26,7 @@ func NormalizeURL(input string) string {
 	}
 
 	host := u.Host
-	if strings.Contains(host, ":") {
+	if !completeOnion || strings.Contains(host, ":") {
 		return input
 	}
 
@@ -81,3 +83,16 @@ func cleanText(text string) string {
 	// 4. Temiz satırları birleştir
 	return strings.Join(cleanedLines, "\n")
 }
+
+// truncateRunes, metni karakter (rune) sınırında keser ve sonuna ek koyar.
+// Bayt üzerinden kesmek çok baytlı karakterleri (ş, ğ, ı) bozabilir.
+// limit 0 veya negatifse varsayılan sınır kullanılır.
+func truncateRunes(text string, limit int, suffix string) string {
+	if limit <= 0 {
+		limit = DefaultContentLimit
+	}
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit]) + suffix
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"fmt"
+	"scraper/models"
+	"scraper/scraper"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Bir bildirimde gönderilecek en fazla eşleşme ve alıntı uzunluğu
+const (
+	maxAlertHits    = 20
+	alertExcerptLen = 200
+)
+
+// freshItem: Bu taramada ilk kez görülen konu başlığı veya ileti
+type freshItem struct {
+	ThreadTitle string
+	ThreadLink  string
+	Author      string
+	Date        string
+	Text        string
+}
+
+// EvaluateAlerts: Taramada ilk kez görülen konu ve iletileri aktif alarm kurallarıyla eşleştirir
+// ve eşleşen her kural için kanallarına bildirimi sıraya alır (gönderim arka planda yapılır).
+// Tetiklenen kural sayısını döndürür.
+func EvaluateAlerts(db *gorm.DB, stats models.Stats, watchlistID *uint) int {
+	var rules []models.AlertRule
+	if err := db.Preload("Keyword").Where("is_active = ?", true).Find(&rules).Error; err != nil || len(rules) == 0 {
+		return 0
+	}
+
+	var applicable []models.AlertRule
+	for _, rule := range rules {
+		if rule.Keyword.ID != 0 && ruleApplies(rule, stats.SiteID, watchlistID) {
+			applicable = append(applicable, rule)
+		}
+	}
+	if len(applicable) == 0 {
+		return 0
+	}
+
+	items := loadFreshItems(db, stats.ID)
+	if len(items) == 0 {
+		return 0
+	}
+
+	var site models.Site
+	db.First(&site, stats.SiteID)
+
+	triggered := 0
+	for _, rule := range applicable {
+		hits := matchKeyword(items, rule.Keyword)
+		if len(hits) == 0 {
+			continue
+		}
+		triggered++
+
+		msg := AlertMessage{
+			Rule:     rule.Name,
+			Severity: rule.Severity,
+			Keyword:  rule.Keyword.Word,
+			Category: rule.Keyword.Category,
+			SiteURL:  site.URL,
+			StatsID:  stats.ID,
+			Hits:     hits,
+			Time:     time.Now(),
+		}
+		LogWarn(db, "ALERT", fmt.Sprintf("Alarm tetiklendi: %s [%s] \"%s\" - %s (%d eşleşme)", rule.Name, rule.Severity, rule.Keyword.Word, site.URL, len(hits)))
+
+		if len(rule.ChannelIDs) == 0 {
+			continue
+		}
+		var channels []models.AlertChannel
+		db.Where("id IN ? AND is_active = ?", rule.ChannelIDs, true).Find(&channels)
+
+		ruleID, statsID := rule.ID, stats.ID
+		for _, ch := range channels {
+			QueueAlert(db, ch, msg, &ruleID, &statsID)
+		}
+	}
+	return triggered
+}
+
+// ruleApplies: Kural site veya watchlist ile sınırlandırılmışsa taramanın bunlardan birine ait olması gerekir
+func ruleApplies(rule models.AlertRule, siteID uint, watchlistID *uint) bool {
+	if len(rule.SiteIDs) == 0 && len(rule.WatchlistIDs) == 0 {
+		return true
+	}
+	for _, id := range rule.SiteIDs {
+		if id == siteID {
+			return true
+		}
+	}
+	if watchlistID != nil {
+		for _, id := range rule.WatchlistIDs {
+			if id == *watchlistID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadFreshItems: Daha önceki bir taramada görülmemiş konu ve iletileri getirir
+func loadFreshItems(db *gorm.DB, statsID uint) []freshItem {
+	var items []freshItem
+
+	var threads []models.Thread
+	db.Table("threads").
+		Select("threads.*").
+		Joins("JOIN thread_sightings ts ON ts.thread_id = threads.id AND ts.stats_id = ?", statsID).
+		Where("NOT EXISTS (SELECT 1 FROM thread_sightings old WHERE old.thread_id = threads.id AND old.stats_id < ?)", statsID).
+		Find(&threads)
+	for _, t := range threads {
+		items = append(items, freshItem{ThreadTitle: t.Title, ThreadLink: t.Link, Author: t.Author, Date: t.Date, Text: t.Title})
+	}
+
+	type postRow struct {
+		Title   string
+		Link    string
+		Author  string
+		Date    string
+		Content string
+	}
+	var posts []postRow
+	db.Table("posts").
+		Select("threads.title, threads.link, posts.author, posts.date, posts.content").
+		Joins("JOIN post_sightings ps ON ps.post_id = posts.id AND ps.stats_id = ?", statsID).
+		Joins("JOIN threads ON threads.id = posts.thread_id").
+		Where("NOT EXISTS (SELECT 1 FROM post_sightings old WHERE old.post_id = posts.id AND old.stats_id < ?)", statsID).
+		Scan(&posts)
+	for _, p := range posts {
+		items = append(items, freshItem{ThreadTitle: p.Title, ThreadLink: p.Link, Author: p.Author, Date: p.Date, Text: p.Content})
+	}
+
+	return items
+}
+
+// matchKeyword: Keyword'ün eşleştirme moduna göre geçtiği öğeleri bulur
+func matchKeyword(items []freshItem, keyword models.Keyword) []AlertHit {
+	matcher := scraper.NewKeywordMatcher([]models.Keyword{keyword})
+
+	var hits []AlertHit
+	for _, item := range items {
+		tags := matcher.Match(item.Text)
+		if len(tags) == 0 {
+			continue
+		}
+		// Eşleşme konumu karakter cinsindendir, alıntı için bayta çevrilir
+		span := tags[0].Matches[0]
+		idx := len(string([]rune(item.Text)[:span.Start]))
+		hits = append(hits, AlertHit{
+			ThreadTitle: item.ThreadTitle,
+			ThreadLink:  item.ThreadLink,
+			Author:      item.Author,
+			Date:        item.Date,
+			Excerpt:     excerpt(item.Text, idx, len(span.Text)),
+		})
+		if len(hits) >= maxAlertHits {
+			break
+		}
+	}
+	return hits
+}
+
+// excerpt: Eşleşmenin çevresinden kısa bir alıntı çıkarır
+func excerpt(text string, idx, length int) string {
+	start := idx - alertExcerptLen/2
+	if start < 0 {
+		start = 0
+	}
+	end := idx + length + alertExcerptLen/2
+	if end > len(text) {
+		end = len(text)
+	}
+
+	// UTF-8 karakterlerini ortadan bölme
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	out := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(text) {
+		out += "..."
+	}
+	return out
+}
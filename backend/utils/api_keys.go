@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"errors"
+	"scraper/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API anahtarlarının ön eki; sızan anahtarların kod taramalarında tanınmasını kolaylaştırır
+const apiKeyPrefix = "gsk_"
+
+// Son kullanım zamanı en fazla bu aralıkla yazılır
+const apiKeyTouchInterval = 1 * time.Minute
+
+var errInvalidAPIKey = errors.New("Geçersiz, iptal edilmiş veya süresi dolmuş API anahtarı")
+
+// GenerateAPIKey: Yeni anahtar üretir; düz metin sadece bir kez kullanıcıya gösterilir
+func GenerateAPIKey() (plain, prefix, hash string, err error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	plain = apiKeyPrefix + secret
+	return plain, plain[:len(apiKeyPrefix)+6], HashToken(plain), nil
+}
+
+// authenticateAPIKey: Anahtarı ve sahibini doğrular, son kullanım bilgisini günceller
+func authenticateAPIKey(db *gorm.DB, plain, ip string) (models.APIKey, models.User, error) {
+	var key models.APIKey
+	var user models.User
+	if err := db.Where("key_hash = ?", HashToken(plain)).First(&key).Error; err != nil {
+		return key, user, errInvalidAPIKey
+	}
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return key, user, errInvalidAPIKey
+	}
+	// Silinen kullanıcının anahtarları da geçersizdir
+	if err := db.First(&user, key.UserID).Error; err != nil {
+		return key, user, errInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != ip {
+		db.Model(&key).UpdateColumns(map[string]interface{}{"last_used_at": &now, "last_used_ip": ip})
+	}
+	return key, user, nil
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"scraper/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Audit: İşlemi yapan kullanıcıyı ve istemci IP'sini gin bağlamından alarak denetim kaydı yazar.
+// before/after JSON'a çevrilir; nil olanlar boş bırakılır. Gizli alanlar çağıran tarafından temizlenmelidir.
+func Audit(c *gin.Context, db *gorm.DB, action, entityType string, entityID interface{}, before, after interface{}) {
+	entry := models.AuditLog{
+		Username:   c.GetString(ContextUsername),
+		Action:     action,
+		EntityType: entityType,
+		Before:     auditValue(before),
+		After:      auditValue(after),
+		IP:         c.ClientIP(),
+	}
+	if entityID != nil {
+		entry.EntityID = fmt.Sprint(entityID)
+	}
+	if id := c.GetUint(ContextUserID); id != 0 {
+		entry.UserID = &id
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Audit Failed (%s): %v", action, err)
+	}
+}
+
+func auditValue(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"scraper/models"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB: Her test için ayrı bellek içi veritabanı
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("veritabanı açılamadı: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.Site{}, &models.Stats{}, &models.ScanMetadata{}, &models.SystemLog{},
+		&models.Thread{}, &models.Post{}, &models.ThreadSighting{}, &models.PostSighting{}, &models.ContentTag{},
+		&models.AlertChannel{}, &models.AlertDelivery{}, &models.Watchlist{})
+	if err != nil {
+		t.Fatalf("taşıma başarısız: %v", err)
+	}
+	if err := EnsureSearchIndex(db); err != nil {
+		t.Fatalf("arama dizini oluşturulamadı: %v", err)
+	}
+	return db
+}
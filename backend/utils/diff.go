@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"scraper/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PostChange: Karşılaştırmada bulunan yeni, düzenlenmiş veya silinmiş ileti
+type PostChange struct {
+	ThreadTitle string       `json:"thread_title"`
+	ThreadLink  string       `json:"thread_link"`
+	Post        models.Post  `json:"post"`
+	Previous    *models.Post `json:"previous,omitempty"` // Düzenlenen iletinin önceki hali
+}
+
+// ScanDiff: Bir taramanın başka bir taramaya göre farkları
+type ScanDiff struct {
+	StatsID      uint            `json:"stats_id"`
+	AgainstID    uint            `json:"against_id"`
+	Changed      bool            `json:"changed"`
+	NewThreads   []models.Thread `json:"new_threads"`
+	NewPosts     []PostChange    `json:"new_posts"`
+	EditedPosts  []PostChange    `json:"edited_posts"`
+	DeletedPosts []PostChange    `json:"deleted_posts"`
+}
+
+// hashParts: Parçaları ayraçla birleştirip SHA-256 özetini döndürür
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeText: Boşluk farklılıklarının değişiklik sayılmaması için metni sadeleştirir
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ThreadKey: Konunun taramalar arasında değişmeyen kimliği (site + bağlantı, yoksa forum numarası veya başlık)
+func ThreadKey(siteID uint, remoteID, link, title string) string {
+	switch {
+	case link != "":
+		return hashParts(fmt.Sprint(siteID), "link", link)
+	case remoteID != "":
+		return hashParts(fmt.Sprint(siteID), "id", remoteID)
+	default:
+		return hashParts(fmt.Sprint(siteID), "title", normalizeText(title))
+	}
+}
+
+// PostKey: İletinin konu içindeki kimliği (forum numarası, yoksa konudaki sırası).
+// Tarih kimliğe katılmaz; sayfada tarih bulunamayan iletiler her taramada yeni sayılmasın.
+func PostKey(threadKey, remoteID string, order int) string {
+	if remoteID != "" {
+		return hashParts(threadKey, "id", remoteID)
+	}
+	return hashParts(threadKey, "pos", fmt.Sprint(order))
+}
+
+// ThreadHash: Konunun başlık ve yazar özeti
+func ThreadHash(title, author string) string {
+	return hashParts(normalizeText(title), normalizeText(author))
+}
+
+// PostHash: İleti içeriğinin özeti
+func PostHash(content string) string {
+	return hashParts(normalizeText(content))
+}
+
+// threadIdentity: Eski kayıtlarda Key boşsa alanlardan hesaplanır
+func threadIdentity(t models.Thread) string {
+	if t.Key != "" {
+		return t.Key
+	}
+	return ThreadKey(t.SiteID, t.RemoteID, t.Link, t.Title)
+}
+
+func postIdentity(threadKey string, p models.Post) string {
+	if p.Key != "" {
+		return p.Key
+	}
+	return PostKey(threadKey, p.RemoteID, p.Order)
+}
+
+func postHash(p models.Post) string {
+	if p.Hash != "" {
+		return p.Hash
+	}
+	return PostHash(p.Content)
+}
+
+// DiffScans: statsID taramasını againstID taramasıyla karşılaştırır.
+// Silinen iletiler sadece yeni taramada da iletileri alınmış konular için raporlanır;
+// bu taramada ziyaret edilmeyen konunun iletileri silinmiş sayılmaz.
+func DiffScans(db *gorm.DB, statsID, againstID uint) (*ScanDiff, error) {
+	current, err := LoadScanThreads(db, statsID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := LoadScanThreads(db, againstID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ScanDiff{
+		StatsID:      statsID,
+		AgainstID:    againstID,
+		NewThreads:   []models.Thread{},
+		NewPosts:     []PostChange{},
+		EditedPosts:  []PostChange{},
+		DeletedPosts: []PostChange{},
+	}
+
+	oldThreads := make(map[string]models.Thread, len(previous))
+	for _, t := range previous {
+		oldThreads[threadIdentity(t)] = t
+	}
+
+	for _, t := range current {
+		key := threadIdentity(t)
+		old, ok := oldThreads[key]
+		if !ok {
+			diff.NewThreads = append(diff.NewThreads, t)
+			continue
+		}
+
+		oldPosts := make(map[string]models.Post, len(old.Posts))
+		for _, p := range old.Posts {
+			oldPosts[postIdentity(key, p)] = p
+		}
+
+		seen := make(map[string]bool, len(t.Posts))
+		for _, p := range t.Posts {
+			pk := postIdentity(key, p)
+			seen[pk] = true
+
+			prev, existed := oldPosts[pk]
+			switch {
+			case !existed:
+				diff.NewPosts = append(diff.NewPosts, PostChange{ThreadTitle: t.Title, ThreadLink: t.Link, Post: p})
+			case postHash(p) != postHash(prev) || (p.LastEdited != "" && p.LastEdited != prev.LastEdited):
+				prevCopy := prev
+				diff.EditedPosts = append(diff.EditedPosts, PostChange{ThreadTitle: t.Title, ThreadLink: t.Link, Post: p, Previous: &prevCopy})
+			}
+		}
+
+		if len(t.Posts) == 0 {
+			continue
+		}
+		for _, p := range old.Posts {
+			if !seen[postIdentity(key, p)] {
+				diff.DeletedPosts = append(diff.DeletedPosts, PostChange{ThreadTitle: old.Title, ThreadLink: old.Link, Post: p})
+			}
+		}
+	}
+
+	diff.Changed = len(diff.NewThreads) > 0 || len(diff.NewPosts) > 0 ||
+		len(diff.EditedPosts) > 0 || len(diff.DeletedPosts) > 0
+	return diff, nil
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"scraper/models"
+	"scraper/scraper"
+	"strings"
+	"testing"
+)
+
+// Tarih ve ileti numarası olmayan genel forum sayfası
+const datelessThread = `<html><head><title>Tarihsiz konu</title></head><body>
+<h1>Tarihsiz konu</h1>
+<div class="post"><span class="author">ayse</span><div class="content">İlk ileti, sayfada tarih yok.</div></div>
+<div class="post"><span class="author">mehmet</span><div class="content">İkinci ileti de tarihsiz.</div></div>
+<div class="post"><span class="author">ayse</span><div class="content">Üçüncü ileti.</div></div>
+</body></html>`
+
+// offlineScan: Sayfayı ağa çıkmadan tarar
+func offlineScan(t *testing.T, url, body string) *scraper.ScrapeResult {
+	t.Helper()
+	pages := []scraper.SnapshotPage{{URL: url, ContentType: "text/html; charset=utf-8", Body: []byte(body)}}
+	result, err := scraper.AnalyzeSite(context.Background(), url, scraper.ScanOptions{
+		Engine:    scraper.EngineOptions{Retry: scraper.RetryPolicy{MaxAttempts: 1}},
+		Transport: scraper.NewOfflineTransport(pages),
+	})
+	if err != nil {
+		t.Fatalf("tarama başarısız: %v", err)
+	}
+	return result
+}
+
+func TestRescanWithoutDatesHasNoChanges(t *testing.T) {
+	db := newTestDB(t)
+	const url = "http://forum.test/konu/1"
+
+	result := offlineScan(t, url, datelessThread)
+	for _, th := range result.Threads {
+		for _, p := range th.Posts {
+			// Tarama zamanı tarih olarak yazılırsa kimlik her taramada değişir
+			if p.Date != "" {
+				t.Errorf("sayfada olmayan tarih dolduruldu: %q", p.Date)
+			}
+		}
+	}
+	first := saveScan(t, db, result)
+	if first.TotalPosts != 3 {
+		t.Fatalf("ilk taramada 3 ileti bekleniyordu, %d bulundu", first.TotalPosts)
+	}
+	second := saveScan(t, db, offlineScan(t, url, datelessThread))
+
+	diff, err := DiffScans(db, second.ID, first.ID)
+	if err != nil {
+		t.Fatalf("karşılaştırma başarısız: %v", err)
+	}
+	if len(diff.NewThreads) != 0 || len(diff.NewPosts) != 0 || len(diff.EditedPosts) != 0 || len(diff.DeletedPosts) != 0 {
+		t.Errorf("değişiklik beklenmiyordu: %d yeni konu, %d yeni, %d düzenlenmiş, %d silinmiş ileti",
+			len(diff.NewThreads), len(diff.NewPosts), len(diff.EditedPosts), len(diff.DeletedPosts))
+	}
+	if diff.Changed || second.Changed {
+		t.Error("tekrar tarama değişmiş olarak işaretlendi")
+	}
+
+	var posts int64
+	db.Model(&models.Post{}).Count(&posts)
+	if posts != 3 {
+		t.Errorf("tekil ileti sayısı 3 olmalıydı, %d", posts)
+	}
+}
+
+func TestEditNoteWithoutContentChangeIsReported(t *testing.T) {
+	db := newTestDB(t)
+	const url = "http://forum.test/konu/2"
+
+	first := saveScan(t, db, offlineScan(t, url, datelessThread))
+	edited := strings.Replace(datelessThread,
+		`<div class="content">İkinci ileti de tarihsiz.</div>`,
+		`<div class="content">İkinci ileti de tarihsiz.</div><div class="post-edit">Son düzenleme: mehmet</div>`, 1)
+	second := saveScan(t, db, offlineScan(t, url, edited))
+
+	diff, err := DiffScans(db, second.ID, first.ID)
+	if err != nil {
+		t.Fatalf("karşılaştırma başarısız: %v", err)
+	}
+	if len(diff.EditedPosts) != 1 || len(diff.NewPosts) != 0 || len(diff.DeletedPosts) != 0 {
+		t.Fatalf("1 düzenlenmiş ileti bekleniyordu: %d düzenlenmiş, %d yeni, %d silinmiş",
+			len(diff.EditedPosts), len(diff.NewPosts), len(diff.DeletedPosts))
+	}
+	change := diff.EditedPosts[0]
+	if change.Post.LastEdited != "Son düzenleme: mehmet" || change.Previous.LastEdited != "" {
+		t.Errorf("düzenleme bilgisi taramaya göre okunmadı: şimdi %q, önce %q", change.Post.LastEdited, change.Previous.LastEdited)
+	}
+
+	// Eski tarama kendi değerini korur
+	again, err := DiffScans(db, first.ID, second.ID)
+	if err != nil {
+		t.Fatalf("karşılaştırma başarısız: %v", err)
+	}
+	if len(again.EditedPosts) != 0 {
+		t.Errorf("düzenleme notu olmayan tarama düzenlenmiş sayıldı")
+	}
+}
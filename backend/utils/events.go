@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Canlı akış olay türleri
+const (
+	EventStatus = "status" // İşin durumu/ilerlemesi değişti (veri: models.ScanJob veya models.RetagJob)
+	EventScan   = "scan"   // Tarama motorundan gelen ilerleme (veri: scraper.ScanEvent)
+	EventLog    = "log"    // Yeni SystemLog kaydı (veri: models.SystemLog)
+)
+
+// LogTopic: Yeni sistem loglarının yayınlandığı kanal
+const LogTopic = "logs"
+
+// subscriberBuffer: Yavaş istemciler bu sınırı aşarsa olaylar atlanır, yayıncı beklemez
+const subscriberBuffer = 64
+
+// Event: Canlı akış üzerinden istemcilere iletilen mesaj
+type Event struct {
+	Type  string      `json:"type"`
+	JobID uint        `json:"job_id,omitempty"`
+	Data  interface{} `json:"data"`
+	Time  time.Time   `json:"time"`
+}
+
+// EventHub: Konu bazlı basit yayın/abone merkezi
+type EventHub struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan Event]struct{}
+}
+
+// Events: Uygulama genelinde kullanılan olay merkezi
+var Events = NewEventHub()
+
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[string]map[chan Event]struct{})}
+}
+
+// JobTopic: Belirli bir tarama işinin olay kanalı
+func JobTopic(jobID uint) string {
+	return fmt.Sprintf("job:%d", jobID)
+}
+
+// RetagTopic: Belirli bir yeniden etiketleme işinin olay kanalı
+func RetagTopic(jobID uint) string {
+	return fmt.Sprintf("retag:%d", jobID)
+}
+
+// Subscribe: Konuya abone olur. Dönen fonksiyon aboneliği kapatır.
+func (h *EventHub) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[chan Event]struct{})
+	}
+	h.subs[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[topic], ch)
+			if len(h.subs[topic]) == 0 {
+				delete(h.subs, topic)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Publish: Olayı konunun tüm abonelerine iletir; dolu kanallar beklenmez
+func (h *EventHub) Publish(topic string, ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[topic] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
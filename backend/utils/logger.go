@@ -8,25 +8,32 @@ import (
 )
 
 func LogInfo(db *gorm.DB, source, message string) {
-	if err := db.Create(&models.SystemLog{Level: "INFO", Source: source, Message: message}).Error; err != nil {
-		log.Printf("LogInfo Failed: %v", err)
-	}
+	writeLog(db, "INFO", source, "", message)
 }
 
 func LogSuccess(db *gorm.DB, source, message string) {
-	if err := db.Create(&models.SystemLog{Level: "SUCCESS", Source: source, Message: message}).Error; err != nil {
-		log.Printf("LogSuccess Failed: %v", err)
-	}
+	writeLog(db, "SUCCESS", source, "", message)
 }
 
 func LogError(db *gorm.DB, source, message string) {
-	if err := db.Create(&models.SystemLog{Level: "ERROR", Source: source, Message: message}).Error; err != nil {
-		log.Printf("LogError Failed: %v", err)
-	}
+	writeLog(db, "ERROR", source, "", message)
 }
 
 func LogWarn(db *gorm.DB, source, message string) {
-	if err := db.Create(&models.SystemLog{Level: "WARN", Source: source, Message: message}).Error; err != nil {
-		log.Printf("LogWarn Failed: %v", err)
+	writeLog(db, "WARN", source, "", message)
+}
+
+// LogErrorCode: Tarama hatasını koduyla birlikte yazar (loglar koda göre filtrelenebilir)
+func LogErrorCode(db *gorm.DB, source, code, message string) {
+	writeLog(db, "ERROR", source, code, message)
+}
+
+// writeLog: Kaydı veritabanına yazar ve canlı log akışına yayınlar
+func writeLog(db *gorm.DB, level, source, code, message string) {
+	entry := models.SystemLog{Level: level, Source: source, Code: code, Message: message}
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Log%s Failed: %v", level, err)
+		return
 	}
+	Events.Publish(LogTopic, Event{Type: EventLog, Data: entry})
 }
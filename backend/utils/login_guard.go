@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"scraper/models"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kaba kuvvet koruması sınırları
+const (
+	loginFreeAttempts   = 3                // Bekleme olmadan izin verilen hatalı deneme
+	loginBackoffBase    = 1 * time.Second  // 4. hatadan sonra 1s, 2s, 4s... beklenir
+	loginBackoffMax     = 5 * time.Minute  // Geri çekilme üst sınırı
+	loginUserLockAfter  = 10               // Kullanıcı adı bu kadar hatada kilitlenir
+	loginIPLockAfter    = 30               // IP bu kadar hatada kilitlenir
+	loginLockDuration   = 30 * time.Minute // Kilit süresi
+	loginFailureWindow  = 1 * time.Hour    // Son hatadan bu kadar sonra sayaç sıfırlanır
+	loginLockoutsPeriod = 24 * time.Hour   // Panodaki "son kilitlenmeler" aralığı
+)
+
+// Sayaç güncellemeleri oku-yaz olduğu için sıralanır
+var loginGuardMu sync.Mutex
+
+func normalizeLoginName(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// LoginBlocked: IP veya kullanıcı adı için bekleme süresi dolmadıysa kalan süreyi döndürür.
+// locked, geri çekilme değil kilit olduğunu belirtir.
+func LoginBlocked(db *gorm.DB, ip, username string) (retryAfter time.Duration, locked bool) {
+	var throttles []models.LoginThrottle
+	db.Where("(kind = ? AND key = ?) OR (kind = ? AND key = ?)",
+		models.ThrottleIP, ip, models.ThrottleUser, normalizeLoginName(username)).Find(&throttles)
+
+	now := time.Now()
+	for _, t := range throttles {
+		if t.BlockedUntil == nil || !t.BlockedUntil.After(now) {
+			continue
+		}
+		if wait := t.BlockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+		locked = locked || t.Locked
+	}
+	return retryAfter, locked
+}
+
+// RecordLoginFailure: Hatalı denemeyi IP ve kullanıcı adı sayaçlarına işler,
+// gerekirse üssel geri çekilme veya kilit uygular
+func RecordLoginFailure(db *gorm.DB, ip, username string) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+
+	registerFailure(db, models.ThrottleIP, ip, loginIPLockAfter)
+	registerFailure(db, models.ThrottleUser, normalizeLoginName(username), loginUserLockAfter)
+}
+
+func registerFailure(db *gorm.DB, kind, key string, lockAfter int) {
+	if key == "" {
+		return
+	}
+	var t models.LoginThrottle
+	db.Where(models.LoginThrottle{Kind: kind, Key: key}).FirstOrInit(&t)
+
+	now := time.Now()
+	if now.Sub(t.LastFailure) > loginFailureWindow {
+		t.Failures = 0
+		t.Locked = false
+	}
+	t.Failures++
+	t.LastFailure = now
+
+	switch {
+	case t.Failures >= lockAfter:
+		until := now.Add(loginLockDuration)
+		t.BlockedUntil = &until
+		t.Locked = true
+		t.LockedAt = &now
+		t.Lockouts++
+		LogWarn(db, "AUTH", fmt.Sprintf("%s kilitlendi: %s (%d hatalı deneme, %.0f dk)", throttleLabel(kind), key, t.Failures, loginLockDuration.Minutes()))
+	case t.Failures > loginFreeAttempts:
+		backoff := time.Duration(float64(loginBackoffBase) * math.Pow(2, float64(t.Failures-loginFreeAttempts-1)))
+		if backoff > loginBackoffMax {
+			backoff = loginBackoffMax
+		}
+		until := now.Add(backoff)
+		t.BlockedUntil = &until
+	}
+
+	if err := db.Save(&t).Error; err != nil {
+		LogError(db, "AUTH", "Giriş sayacı kaydedilemedi: "+err.Error())
+	}
+}
+
+func throttleLabel(kind string) string {
+	if kind == models.ThrottleIP {
+		return "IP"
+	}
+	return "Hesap"
+}
+
+// RecordLoginSuccess: Başarılı girişte kullanıcı adı sayacını sıfırlar.
+// IP sayacı korunur; aynı IP'den başka hesaplara yapılan denemeler sayılmaya devam eder.
+func RecordLoginSuccess(db *gorm.DB, username string) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+	db.Where("kind = ? AND key = ?", models.ThrottleUser, normalizeLoginName(username)).Delete(&models.LoginThrottle{})
+}
+
+// UnlockLogin: Sayacı siler; kilit ve geri çekilme hemen kalkar
+func UnlockLogin(db *gorm.DB, kind, key string) (bool, error) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+	if kind == models.ThrottleUser {
+		key = normalizeLoginName(key)
+	}
+	res := db.Where("kind = ? AND key = ?", kind, key).Delete(&models.LoginThrottle{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// LockoutStats: Şu an kilitli IP/hesap sayısı ve son 24 saatteki kilitlenmeler
+func LockoutStats(db *gorm.DB) (active, recent int64) {
+	now := time.Now()
+	db.Model(&models.LoginThrottle{}).Where("locked = ? AND blocked_until > ?", true, now).Count(&active)
+	db.Model(&models.LoginThrottle{}).Where("locked_at > ?", now.Add(-loginLockoutsPeriod)).Count(&recent)
+	return active, recent
+}
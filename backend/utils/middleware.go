@@ -1,18 +1,51 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
+	"scraper/models"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-// AuthMiddleware: JWT doğrulama ara katmanı
-func AuthMiddleware() gin.HandlerFunc {
+// Gin bağlamında kimliği doğrulanmış kullanıcı bilgilerinin anahtarları
+const (
+	ContextUserID   = "userID"
+	ContextUsername = "username"
+	ContextRole     = "role"
+	ContextTokenID  = "tokenID"  // Erişim token'ının JWT ID'si (jti)
+	ContextAPIKeyID = "apiKeyID" // İstek API anahtarıyla yapıldıysa anahtarın ID'si
+)
+
+// Şifre değiştirme zorunluyken erişilebilen rotalar
+var passwordChangeRoutes = map[string]bool{
+	"/api/account":          true,
+	"/api/account/password": true,
+	"/api/logout":           true,
+}
+
+// AuthMiddleware: JWT veya API anahtarı doğrulama ara katmanı.
+// Geçerli token'ın kullanıcı ID, kullanıcı adı ve rolünü gin bağlamına yazar.
+// Çıkış veya oturum iptaliyle iptal listesine eklenen token'lar (jti) reddedilir.
+// X-API-Key başlığı varsa Authorization yerine anahtar kullanılır; rol, anahtar kapsamıyla sınırlanır.
+func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			key, user, err := authenticateAPIKey(db, apiKey, c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.Set(ContextAPIKeyID, key.ID)
+			c.Set(ContextUserID, user.ID)
+			c.Set(ContextUsername, user.Username)
+			c.Set(ContextRole, models.ScopeRole(key.Scope, user.Role))
+			c.Next()
+			return
+		}
 
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -29,24 +62,44 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
+		claims, err := parseJWT(parts[1])
+		if errors.Is(err, errMissingSecret) {
 			// Güvenlik: Secret yoksa her şeyi reddet
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Sunucu güvenlik yapılandırması hatası"})
 			c.Abort()
 			return
 		}
+		// İki adımlı girişin ara token'ı sadece /login/2fa içindir
+		if typ, _ := claims["typ"].(string); err != nil || typ == preAuthTokenType {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz veya süresi dolmuş token"})
+			c.Abort()
+			return
+		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("beklenmeyen imza yöntemi: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		})
+		// Rol veya JWT ID bilgisi olmayan eski token'lar kabul edilmez
+		sub, _ := claims["sub"].(float64)
+		role, _ := claims["role"].(string)
+		jti, _ := claims["jti"].(string)
+		if sub <= 0 || !models.ValidRole(role) || jti == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz token içeriği, lütfen tekrar giriş yapın"})
+			c.Abort()
+			return
+		}
+		if TokenRevoked(db, jti) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Oturum sonlandırılmış, lütfen tekrar giriş yapın"})
+			c.Abort()
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz veya süresi dolmuş token"})
+		username, _ := claims["name"].(string)
+		c.Set(ContextTokenID, jti)
+		c.Set(ContextUserID, uint(sub))
+		c.Set(ContextUsername, username)
+		c.Set(ContextRole, role)
+
+		// Varsayılan şifreyle giriş yapan kullanıcı önce şifresini değiştirmeli
+		if mustChange, _ := claims["pwc"].(bool); mustChange && !passwordChangeRoutes[c.FullPath()] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Devam etmeden önce şifrenizi değiştirmelisiniz", "password_change_required": true})
 			c.Abort()
 			return
 		}
@@ -55,3 +108,15 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole: En az verilen role sahip kullanıcılara izin verir (AuthMiddleware'den sonra kullanılır)
+func RequireRole(required string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.RoleAllows(c.GetString(ContextRole), required) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Bu işlem için yetkiniz yok"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
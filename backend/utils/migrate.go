@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"scraper/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrateCanonicalStore: Her tarama için ayrı kopyalanmış eski Thread/Post kayıtlarını
+// tekil kayıtlara ve görülme tablolarına dönüştürür. AutoMigrate'ten önce çağrılmalıdır,
+// aksi halde tekrar eden kayıtlar yüzünden benzersiz indeksler oluşturulamaz.
+// Görülme tabloları zaten varsa veya threads tablosu yoksa hiçbir şey yapmaz.
+func MigrateCanonicalStore(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&models.Thread{}) || m.HasTable(&models.ThreadSighting{}) {
+		return nil
+	}
+
+	// Eski şemada olmayan kolonlar (indeksler AutoMigrate ile sonradan oluşturulur)
+	for _, model := range []interface{}{&models.Thread{}, &models.Post{}} {
+		if err := addMissingColumns(db, model); err != nil {
+			return err
+		}
+	}
+	if err := m.CreateTable(&models.ThreadSighting{}, &models.PostSighting{}); err != nil {
+		return err
+	}
+
+	var merged, removedPosts int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var threads []models.Thread
+		if err := tx.Order("id").Preload("Posts").Find(&threads).Error; err != nil {
+			return err
+		}
+
+		canonicalThreads := make(map[string]uint) // site + konu kimliği -> tekil konu
+		canonicalPosts := make(map[string]uint)   // tekil konu + ileti kimliği + özet -> tekil ileti
+
+		for _, t := range threads {
+			key := threadIdentity(t)
+			threadRef := fmt.Sprintf("%d/%s", t.SiteID, key)
+
+			canonicalID, exists := canonicalThreads[threadRef]
+			if !exists {
+				canonicalID = t.ID
+				canonicalThreads[threadRef] = t.ID
+				err := tx.Model(&models.Thread{}).Where("id = ?", t.ID).Updates(map[string]interface{}{
+					"key":        key,
+					"hash":       ThreadHash(t.Title, t.Author),
+					"first_seen": t.CreatedAt,
+					"last_seen":  t.CreatedAt,
+				}).Error
+				if err != nil {
+					return err
+				}
+			} else {
+				// Sonraki kopya: Tekil kayıt en güncel bilgileri alır
+				err := tx.Model(&models.Thread{}).Where("id = ?", canonicalID).Updates(map[string]interface{}{
+					"title":     t.Title,
+					"category":  t.Category,
+					"replies":   t.Replies,
+					"views":     t.Views,
+					"last_seen": t.CreatedAt,
+				}).Error
+				if err != nil {
+					return err
+				}
+				merged++
+			}
+
+			sighting := models.ThreadSighting{ThreadID: canonicalID, StatsID: t.StatsID}
+			if err := tx.Where(sighting).Attrs(models.ThreadSighting{Replies: t.Replies, Views: t.Views, CreatedAt: t.CreatedAt}).FirstOrCreate(&sighting).Error; err != nil {
+				return err
+			}
+
+			for _, p := range t.Posts {
+				postKey := postIdentity(key, p)
+				hash := postHash(p)
+				postRef := fmt.Sprintf("%d/%s/%s", canonicalID, postKey, hash)
+
+				postID, seen := canonicalPosts[postRef]
+				if !seen {
+					postID = p.ID
+					canonicalPosts[postRef] = p.ID
+					err := tx.Model(&models.Post{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+						"thread_id":  canonicalID,
+						"key":        postKey,
+						"hash":       hash,
+						"first_seen": p.CreatedAt,
+						"last_seen":  p.CreatedAt,
+					}).Error
+					if err != nil {
+						return err
+					}
+				} else {
+					if err := tx.Model(&models.Post{}).Where("id = ?", postID).Update("last_seen", p.CreatedAt).Error; err != nil {
+						return err
+					}
+					if err := tx.Delete(&models.Post{}, p.ID).Error; err != nil {
+						return err
+					}
+					removedPosts++
+				}
+
+				postSighting := models.PostSighting{PostID: postID, StatsID: t.StatsID}
+				if err := tx.Where(postSighting).Attrs(models.PostSighting{Order: p.Order, LastEdited: p.LastEdited, CreatedAt: p.CreatedAt}).FirstOrCreate(&postSighting).Error; err != nil {
+					return err
+				}
+			}
+
+			if exists {
+				if err := tx.Delete(&models.Thread{}, t.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Yarım kalan dönüşüm bir sonraki açılışta tekrar denensin
+		m.DropTable(&models.PostSighting{}, &models.ThreadSighting{})
+		return err
+	}
+
+	log.Printf("Tekil kayıt dönüşümü tamamlandı: %d konu kopyası birleştirildi, %d ileti kopyası silindi", merged, removedPosts)
+	return nil
+}
+
+// MigratePostSightingEdits: Görülme kayıtlarına eklenen düzenleme bilgisi kolonunu iletilerdeki son değerle doldurur.
+// Doldurulmazsa güncellemeden sonraki ilk tarama düzenleme notu olan her iletiyi düzenlenmiş sayar.
+// AutoMigrate'ten önce çağrılmalıdır; kolon zaten varsa hiçbir şey yapmaz.
+func MigratePostSightingEdits(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&models.PostSighting{}) {
+		return nil
+	}
+	columnTypes, err := m.ColumnTypes(&models.PostSighting{})
+	if err != nil {
+		return err
+	}
+	for _, ct := range columnTypes {
+		if ct.Name() == "last_edited" {
+			return nil
+		}
+	}
+
+	if err := m.AddColumn(&models.PostSighting{}, "LastEdited"); err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE post_sightings SET last_edited = COALESCE((SELECT posts.last_edited FROM posts WHERE posts.id = post_sightings.post_id), '')`).Error
+}
+
+// addMissingColumns: Modelde olup tabloda olmayan kolonları ekler.
+// SQLite'ta HasColumn tablo tanımında metin araması yaptığından ("PRIMARY KEY" içindeki "key" gibi)
+// kolon listesi doğrudan okunur.
+func addMissingColumns(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+
+	m := db.Migrator()
+	columnTypes, err := m.ColumnTypes(model)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(columnTypes))
+	for _, ct := range columnTypes {
+		existing[ct.Name()] = true
+	}
+
+	for _, column := range stmt.Schema.DBNames {
+		if existing[column] {
+			continue
+		}
+		if err := m.AddColumn(model, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RekeyPositionalPosts: Forum numarası olmayan iletilerin kimliğini güncel PostKey biçimine çevirir.
+// Eski kimlik yazar ve tarihi de içerdiğinden, tarihi sayfadan okunamayan iletiler her taramada yeni kayıt
+// oluşturmuştu; aynı konu + sıra + içeriğe düşen kopyalar görülme kayıtları taşınarak birleştirilir.
+// Kimliği zaten güncel olan iletiler değişmez, bu yüzden her açılışta çağrılabilir.
+func RekeyPositionalPosts(db *gorm.DB) error {
+	type positionalPost struct {
+		ID        uint
+		ThreadID  uint
+		Key       string
+		Hash      string
+		Order     int
+		ThreadKey string
+		LastSeen  time.Time
+	}
+	var rows []positionalPost
+	err := db.Table("posts").
+		Select(`posts.id, posts.thread_id, posts.key, posts.hash, posts."order", posts.last_seen, threads.key AS thread_key`).
+		Joins("JOIN threads ON threads.id = posts.thread_id").
+		Where("posts.remote_id = '' OR posts.remote_id IS NULL").
+		Order("posts.id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	var rekeyed, merged int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, p := range rows {
+			key := PostKey(p.ThreadKey, "", p.Order)
+			if p.Key == key {
+				continue
+			}
+
+			var target models.Post
+			err := tx.Where(models.Post{ThreadID: p.ThreadID, Key: key, Hash: p.Hash}).Limit(1).Find(&target).Error
+			if err != nil {
+				return err
+			}
+			if target.ID == 0 {
+				if err := tx.Model(&models.Post{}).Where("id = ?", p.ID).Update("key", key).Error; err != nil {
+					return err
+				}
+				rekeyed++
+				continue
+			}
+
+			// Kopya: Görülme kayıtları tekil iletiye taşınır (aynı taramada zaten görülmüşse silinir)
+			if err := tx.Where("post_id = ? AND stats_id IN (?)", p.ID,
+				tx.Model(&models.PostSighting{}).Select("stats_id").Where("post_id = ?", target.ID)).
+				Delete(&models.PostSighting{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.PostSighting{}).Where("post_id = ?", p.ID).Update("post_id", target.ID).Error; err != nil {
+				return err
+			}
+			if p.LastSeen.After(target.LastSeen) {
+				if err := tx.Model(&models.Post{}).Where("id = ?", target.ID).Update("last_seen", p.LastSeen).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("post_id = ?", p.ID).Delete(&models.ContentTag{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM search_index WHERE rowid = ?", p.ID*2+1).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.Post{}, p.ID).Error; err != nil {
+				return err
+			}
+			merged++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rekeyed > 0 || merged > 0 {
+		log.Printf("İleti kimlikleri güncellendi: %d ileti yeniden anahtarlandı, %d kopya birleştirildi", rekeyed, merged)
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"scraper/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bildirim gönderim ayarları
+const (
+	notifyAttempts     = 3
+	notifyTimeout      = 15 * time.Second
+	alertPollInterval  = 1 * time.Second // Bekleyen gönderimlerin kontrol sıklığı
+	alertDeliveryBatch = 50
+)
+
+// notifyRetryDelay: İlk yeniden denemeden önceki bekleme, her denemede katlanarak artar
+var notifyRetryDelay = 2 * time.Second
+
+// alertWake: Yeni gönderim sıraya alındığında arka plan göndericisini uyandırır
+var alertWake = make(chan struct{}, 1)
+
+// AlertHit: Kuralla eşleşen tek bir konu veya ileti
+type AlertHit struct {
+	ThreadTitle string `json:"thread_title"`
+	ThreadLink  string `json:"thread_link"`
+	Author      string `json:"author"`
+	Date        string `json:"date"`
+	Excerpt     string `json:"excerpt"`
+}
+
+// AlertMessage: Kanallara gönderilen alarm içeriği (webhook gövdesi olarak aynen gönderilir)
+type AlertMessage struct {
+	Rule     string     `json:"rule"`
+	Severity string     `json:"severity"`
+	Keyword  string     `json:"keyword"`
+	Category string     `json:"category"`
+	SiteURL  string     `json:"site_url"`
+	StatsID  uint       `json:"stats_id,omitempty"`
+	Hits     []AlertHit `json:"hits"`
+	Time     time.Time  `json:"time"`
+}
+
+// Subject: E-posta konusu ve bot mesajının ilk satırı
+func (m AlertMessage) Subject() string {
+	return fmt.Sprintf("[%s] %s: \"%s\" - %s", strings.ToUpper(m.Severity), m.Rule, m.Keyword, m.SiteURL)
+}
+
+// Text: Düz metin mesaj gövdesi
+func (m AlertMessage) Text() string {
+	var b strings.Builder
+	b.WriteString(m.Subject() + "\n\n")
+	for _, h := range m.Hits {
+		fmt.Fprintf(&b, "- %s (%s, %s)\n  %s\n", h.ThreadTitle, h.Author, h.Date, h.ThreadLink)
+		if h.Excerpt != "" {
+			fmt.Fprintf(&b, "  %s\n", h.Excerpt)
+		}
+	}
+	return b.String()
+}
+
+// Notifier: Bir bildirim kanalına mesaj gönderen alt sistem
+type Notifier interface {
+	Send(ctx context.Context, msg AlertMessage) error
+}
+
+// NewNotifier: Kanal türüne uygun gönderici oluşturur
+func NewNotifier(ch models.AlertChannel) (Notifier, error) {
+	if err := ValidateChannel(ch); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: notifyTimeout}
+	switch ch.Type {
+	case models.ChannelWebhook:
+		return &webhookNotifier{url: ch.URL, secret: ch.Secret, client: client}, nil
+	case models.ChannelBot:
+		return &botNotifier{url: ch.URL, chatID: ch.ChatID, client: client}, nil
+	default:
+		return &smtpNotifier{channel: ch}, nil
+	}
+}
+
+// ValidateChannel: Kanal türü için zorunlu alanları kontrol eder
+func ValidateChannel(ch models.AlertChannel) error {
+	switch ch.Type {
+	case models.ChannelWebhook:
+		if ch.URL == "" {
+			return fmt.Errorf("Webhook adresi gerekli")
+		}
+	case models.ChannelBot:
+		if ch.URL == "" || ch.ChatID == "" {
+			return fmt.Errorf("Bot adresi ve chat_id gerekli")
+		}
+	case models.ChannelSMTP:
+		if ch.SMTPHost == "" || ch.SMTPFrom == "" || ch.SMTPTo == "" {
+			return fmt.Errorf("SMTP sunucusu, gönderen ve alıcı gerekli")
+		}
+	default:
+		return fmt.Errorf("Geçersiz kanal türü: %s (webhook, smtp, bot)", ch.Type)
+	}
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, msg AlertMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		headers["X-Alert-Signature"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return postJSON(ctx, n.client, n.url, body, headers)
+}
+
+type botNotifier struct {
+	url    string
+	chatID string
+	client *http.Client
+}
+
+func (n *botNotifier) Send(ctx context.Context, msg AlertMessage) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": n.chatID,
+		"text":    msg.Text(),
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.url, body, nil)
+}
+
+// postJSON: JSON gövdeyi gönderir, 2xx dışındaki yanıtları hata sayar
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return &deliveryStatusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(snippet))}
+	}
+	return nil
+}
+
+// deliveryStatusError: Kanalın 2xx dışı HTTP yanıtı
+type deliveryStatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *deliveryStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Body)
+}
+
+// retryableDelivery: Ağ hataları, zaman aşımı, 5xx/429 ve SMTP 4xx yanıtları geçicidir.
+// Diğer 4xx yanıtlar (yanlış adres, yetki, geçersiz chat_id) tekrar denense de değişmez.
+func retryableDelivery(err error) bool {
+	var statusErr *deliveryStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status >= 500 || statusErr.Status == http.StatusTooManyRequests
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code < 500
+	}
+	return true
+}
+
+type smtpNotifier struct {
+	channel models.AlertChannel
+}
+
+// Send: Sunucu destekliyorsa STARTTLS kullanır; kullanıcı adı tanımlıysa PLAIN ile kimlik doğrular
+func (n *smtpNotifier) Send(ctx context.Context, msg AlertMessage) error {
+	ch := n.channel
+	port := ch.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(ch.SMTPHost, strconv.Itoa(port))
+
+	dialer := &net.Dialer{Timeout: notifyTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(notifyTimeout))
+
+	client, err := smtp.NewClient(conn, ch.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: ch.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if ch.SMTPUsername != "" {
+		if err := client.Auth(smtp.PlainAuth("", ch.SMTPUsername, ch.SMTPPassword, ch.SMTPHost)); err != nil {
+			return err
+		}
+	}
+
+	recipients := splitRecipients(ch.SMTPTo)
+	if err := client.Mail(ch.SMTPFrom); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	headers := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n",
+		ch.SMTPFrom, strings.Join(recipients, ", "), mime.QEncoding.Encode("UTF-8", msg.Subject()), time.Now().Format(time.RFC1123Z))
+	body := strings.ReplaceAll(msg.Text(), "\n", "\r\n")
+	if _, err := io.WriteString(w, headers+body); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func splitRecipients(list string) []string {
+	var out []string
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// newDelivery: Mesaj için gönderim kaydı hazırlar
+func newDelivery(ch models.AlertChannel, msg AlertMessage, ruleID, statsID *uint) models.AlertDelivery {
+	payload, _ := json.Marshal(msg)
+	return models.AlertDelivery{
+		RuleID:    ruleID,
+		ChannelID: ch.ID,
+		StatsID:   statsID,
+		Severity:  msg.Severity,
+		Status:    models.DeliveryPending,
+		Payload:   string(payload),
+	}
+}
+
+// QueueAlert: Gönderimi bekleyen olarak kaydeder. Gönderim ve yeniden denemeler arka planda yapılır;
+// yavaş veya erişilemeyen bir kanal tarama işçisini bekletmez.
+func QueueAlert(db *gorm.DB, ch models.AlertChannel, msg AlertMessage, ruleID, statsID *uint) {
+	delivery := newDelivery(ch, msg, ruleID, statsID)
+	if err := db.Create(&delivery).Error; err != nil {
+		LogError(db, "ALERT", fmt.Sprintf("Bildirim sıraya alınamadı: %s (%s) - %v", ch.Name, ch.Type, err))
+		return
+	}
+	select {
+	case alertWake <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverAlert: Mesajı kanala hemen gönderir, geçici hatalarda artan aralıklarla tekrar dener
+// ve sonucu alert_deliveries tablosuna yazar. Sonucu beklenen kanal testi içindir.
+func DeliverAlert(db *gorm.DB, ch models.AlertChannel, msg AlertMessage, ruleID, statsID *uint) models.AlertDelivery {
+	delivery := newDelivery(ch, msg, ruleID, statsID)
+	for {
+		attemptDelivery(db, &delivery, ch)
+		if delivery.Status != models.DeliveryPending {
+			break
+		}
+		time.Sleep(time.Until(*delivery.NextAttemptAt))
+	}
+	// Arka plan göndericisi almasın diye kayıt sonuçlandıktan sonra yazılır
+	db.Create(&delivery)
+	return delivery
+}
+
+// StartAlertDelivery: Bekleyen bildirim gönderimlerini arka planda işler.
+// Kayıtlar veritabanında tutulduğundan yeniden başlatmada yarım kalan gönderimler kaldığı yerden devam eder.
+func StartAlertDelivery(db *gorm.DB) {
+	go func() {
+		for {
+			deliverDue(db)
+			select {
+			case <-alertWake:
+			case <-time.After(alertPollInterval):
+			}
+		}
+	}()
+}
+
+// deliverDue: Sırası gelmiş bekleyen gönderimleri eskiden yeniye dener
+func deliverDue(db *gorm.DB) {
+	for {
+		var due []models.AlertDelivery
+		err := db.Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", models.DeliveryPending, time.Now()).
+			Order("id").Limit(alertDeliveryBatch).Find(&due).Error
+		if err != nil || len(due) == 0 {
+			return
+		}
+
+		for i := range due {
+			delivery := &due[i]
+			var ch models.AlertChannel
+			if err := db.First(&ch, delivery.ChannelID).Error; err != nil {
+				// Kanal gönderim beklerken silinmiş
+				delivery.Attempts++
+				delivery.Status = models.DeliveryFailed
+				delivery.Error = "Bildirim kanalı bulunamadı"
+				delivery.NextAttemptAt = nil
+			} else {
+				attemptDelivery(db, delivery, ch)
+			}
+			db.Save(delivery)
+		}
+
+		if len(due) < alertDeliveryBatch {
+			return
+		}
+	}
+}
+
+// attemptDelivery: Kaydın mesajını kanala bir kez göndermeyi dener ve sonucu kayda işler (kaydetmez).
+// Geçici hatada deneme hakkı kaldıysa kayıt beklemede kalır, sıradaki deneme zamanı katlanarak ileri alınır.
+func attemptDelivery(db *gorm.DB, delivery *models.AlertDelivery, ch models.AlertChannel) {
+	var msg AlertMessage
+	err := json.Unmarshal([]byte(delivery.Payload), &msg)
+	retryable := false
+	if err == nil {
+		var notifier Notifier
+		if notifier, err = NewNotifier(ch); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			err = notifier.Send(ctx, msg)
+			cancel()
+			retryable = err != nil && retryableDelivery(err)
+		}
+	}
+
+	delivery.Attempts++
+	delivery.NextAttemptAt = nil
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = models.DeliverySent
+		delivery.Error = ""
+		delivery.SentAt = &now
+	case retryable && delivery.Attempts < notifyAttempts:
+		next := time.Now().Add(notifyRetryDelay * time.Duration(1<<(delivery.Attempts-1)))
+		delivery.Status = models.DeliveryPending
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = models.DeliveryFailed
+		delivery.Error = err.Error()
+		LogError(db, "ALERT", fmt.Sprintf("Bildirim gönderilemedi: %s (%s) - %v", ch.Name, ch.Type, err))
+	}
+}
@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"reflect"
+	"scraper/models"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testAlert: Testlerde gönderilen örnek alarm
+func testAlert() AlertMessage {
+	return AlertMessage{
+		Rule:     "Sızıntı",
+		Severity: models.SeverityHigh,
+		Keyword:  "leak",
+		SiteURL:  "http://forum.test",
+		Hits:     []AlertHit{{ThreadTitle: "Yeni leak", ThreadLink: "http://forum.test/t/1", Author: "x", Excerpt: "yeni leak paylaşıldı"}},
+		Time:     time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestQueuedAlertIsRetriedInBackground(t *testing.T) {
+	db := newTestDB(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "geçici hata", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ch := models.AlertChannel{Name: "hook", Type: models.ChannelWebhook, URL: srv.URL, IsActive: true}
+	db.Create(&ch)
+	QueueAlert(db, ch, testAlert(), nil, nil)
+
+	// Sıraya alma kanalı çağırmaz
+	var delivery models.AlertDelivery
+	db.First(&delivery)
+	if calls.Load() != 0 || delivery.Status != models.DeliveryPending {
+		t.Fatalf("gönderim sıraya alınmalıydı: %d çağrı, durum %s", calls.Load(), delivery.Status)
+	}
+
+	deliverDue(db)
+	db.First(&delivery, delivery.ID)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 || delivery.NextAttemptAt == nil || delivery.Error == "" {
+		t.Fatalf("502 sonrası yeniden deneme planlanmalıydı: %+v", delivery)
+	}
+
+	// Zamanı gelmemiş deneme yapılmaz
+	deliverDue(db)
+	if calls.Load() != 1 {
+		t.Fatalf("erken yeniden deneme yapıldı (%d çağrı)", calls.Load())
+	}
+
+	db.Model(&delivery).Update("next_attempt_at", time.Now().Add(-time.Second))
+	deliverDue(db)
+	db.First(&delivery, delivery.ID)
+	if delivery.Status != models.DeliverySent || delivery.Attempts != 2 || delivery.SentAt == nil || delivery.Error != "" {
+		t.Errorf("ikinci denemede gönderilmeliydi: %+v", delivery)
+	}
+}
+
+// fastRetries: Yeniden deneme beklemelerini test süresince kısaltır
+func fastRetries(t *testing.T) {
+	t.Helper()
+	prev := notifyRetryDelay
+	notifyRetryDelay = time.Millisecond
+	t.Cleanup(func() { notifyRetryDelay = prev })
+}
+
+// capturedRequest: Sahte sunucuya gelen istek
+type capturedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// recordingServer: Sırayla verilen durum kodlarıyla yanıt veren ve istekleri kaydeden HTTP sunucusu.
+// Kodlar bitince son kod tekrarlanır.
+func recordingServer(t *testing.T, statuses ...int) (*httptest.Server, *[]capturedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []capturedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, capturedRequest{Header: r.Header.Clone(), Body: body})
+		status := statuses[len(statuses)-1]
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestWebhookPayloadAndSignature(t *testing.T) {
+	db := newTestDB(t)
+	srv, requests := recordingServer(t, http.StatusOK)
+	ch := models.AlertChannel{Name: "hook", Type: models.ChannelWebhook, URL: srv.URL, Secret: "gizli"}
+
+	delivery := DeliverAlert(db, ch, testAlert(), nil, nil)
+	if delivery.Status != models.DeliverySent || delivery.Attempts != 1 {
+		t.Fatalf("gönderim başarılı olmalıydı: %+v", delivery)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("%d istek gönderildi, beklenen 1", len(*requests))
+	}
+	req := (*requests)[0]
+
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	mac := hmac.New(sha256.New, []byte("gizli"))
+	mac.Write(req.Body)
+	if sig := req.Header.Get("X-Alert-Signature"); sig != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("imza gövdeyle eşleşmiyor: %q", sig)
+	}
+
+	var got AlertMessage
+	if err := json.Unmarshal(req.Body, &got); err != nil {
+		t.Fatalf("gövde JSON değil: %v", err)
+	}
+	if !reflect.DeepEqual(got, testAlert()) {
+		t.Errorf("gövde alarm mesajıyla aynı olmalı\n got  %+v\n want %+v", got, testAlert())
+	}
+}
+
+func TestBotPayload(t *testing.T) {
+	db := newTestDB(t)
+	srv, requests := recordingServer(t, http.StatusOK)
+	ch := models.AlertChannel{Name: "bot", Type: models.ChannelBot, URL: srv.URL + "/bot123/sendMessage", ChatID: "-10042"}
+
+	if delivery := DeliverAlert(db, ch, testAlert(), nil, nil); delivery.Status != models.DeliverySent {
+		t.Fatalf("gönderim başarılı olmalıydı: %+v", delivery)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal((*requests)[0].Body, &got); err != nil {
+		t.Fatalf("gövde JSON değil: %v", err)
+	}
+	if len(got) != 2 || got["chat_id"] != "-10042" || got["text"] != testAlert().Text() {
+		t.Errorf("bot gövdesi {chat_id, text} olmalı: %+v", got)
+	}
+	if !strings.HasPrefix(got["text"], "[HIGH] Sızıntı") {
+		t.Errorf("mesaj konu satırıyla başlamalı: %q", got["text"])
+	}
+}
+
+func TestDeliveryRetryPolicy(t *testing.T) {
+	fastRetries(t)
+	tests := []struct {
+		name     string
+		channel  string
+		statuses []int
+		status   string
+		attempts int
+	}{
+		{"webhook 5xx sonrası başarılı", models.ChannelWebhook, []int{500, 503, 200}, models.DeliverySent, 3},
+		{"webhook 5xx denemeleri tüketir", models.ChannelWebhook, []int{500}, models.DeliveryFailed, notifyAttempts},
+		{"webhook 429 yeniden denenir", models.ChannelWebhook, []int{429, 200}, models.DeliverySent, 2},
+		{"webhook 4xx yeniden denenmez", models.ChannelWebhook, []int{404}, models.DeliveryFailed, 1},
+		{"bot 5xx yeniden denenir", models.ChannelBot, []int{502, 200}, models.DeliverySent, 2},
+		{"bot 4xx yeniden denenmez", models.ChannelBot, []int{400}, models.DeliveryFailed, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			srv, requests := recordingServer(t, tt.statuses...)
+			ch := models.AlertChannel{Name: tt.name, Type: tt.channel, URL: srv.URL, ChatID: "1"}
+
+			delivery := DeliverAlert(db, ch, testAlert(), nil, nil)
+			if delivery.Status != tt.status || delivery.Attempts != tt.attempts || len(*requests) != tt.attempts {
+				t.Errorf("durum %s, %d deneme, %d istek; beklenen %s, %d", delivery.Status, delivery.Attempts, len(*requests), tt.status, tt.attempts)
+			}
+			if tt.status == models.DeliveryFailed && !strings.HasPrefix(delivery.Error, fmt.Sprintf("HTTP %d", tt.statuses[len(tt.statuses)-1])) {
+				t.Errorf("hata son yanıtı göstermeli: %q", delivery.Error)
+			}
+
+			var stored models.AlertDelivery
+			if err := db.First(&stored, delivery.ID).Error; err != nil || stored.Status != tt.status || stored.Attempts != tt.attempts {
+				t.Errorf("kayıt sonuçla aynı olmalı: %+v (%v)", stored, err)
+			}
+		})
+	}
+}
+
+// smtpSession: Sahte SMTP sunucusunun aldığı zarf ve mesaj
+type smtpSession struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTP: Tek bağlantıyı kabul eden basit SMTP sunucusu. rcptReply boş değilse RCPT komutuna bu yanıt verilir.
+// STARTTLS ve AUTH sunulmaz; istemcinin bunları atlaması beklenir.
+func fakeSMTP(t *testing.T, rcptReply string) (string, int, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("dinlenemedi: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		tp.PrintfLine("220 fake.test ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				sessions <- s
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				tp.PrintfLine("250-fake.test\r\n250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				s.From = smtpPath(line)
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				if rcptReply != "" {
+					tp.PrintfLine("%s", rcptReply)
+					continue
+				}
+				s.To = append(s.To, smtpPath(line))
+				tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				tp.PrintfLine("354 Veri bekleniyor")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					sessions <- s
+					return
+				}
+				s.Data = string(data)
+				tp.PrintfLine("250 Kuyruğa alındı")
+			case cmd == "QUIT":
+				tp.PrintfLine("221 Güle güle")
+				sessions <- s
+				return
+			default:
+				tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, sessions
+}
+
+// smtpPath: "MAIL FROM:<a@b> BODY=8BITMIME" komutundan adresi çıkarır
+func smtpPath(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func TestSMTPDelivery(t *testing.T) {
+	db := newTestDB(t)
+	host, port, sessions := fakeSMTP(t, "")
+	ch := models.AlertChannel{
+		Name: "mail", Type: models.ChannelSMTP, SMTPHost: host, SMTPPort: port,
+		SMTPFrom: "alarm@galileoff.test", SMTPTo: "soc@galileoff.test, analist@galileoff.test",
+	}
+
+	delivery := DeliverAlert(db, ch, testAlert(), nil, nil)
+	if delivery.Status != models.DeliverySent {
+		t.Fatalf("e-posta gönderilmeliydi: %+v", delivery)
+	}
+
+	var s smtpSession
+	select {
+	case s = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP oturumu tamamlanmadı")
+	}
+	if s.From != "alarm@galileoff.test" {
+		t.Errorf("gönderen %q", s.From)
+	}
+	if !reflect.DeepEqual(s.To, []string{"soc@galileoff.test", "analist@galileoff.test"}) {
+		t.Errorf("alıcılar %q", s.To)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(s.Data))
+	if err != nil {
+		t.Fatalf("mesaj ayrıştırılamadı: %v\n%s", err, s.Data)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != testAlert().Subject() {
+		t.Errorf("konu %q (%v), beklenen %q", subject, err, testAlert().Subject())
+	}
+	if ct := msg.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "Yeni leak") || !strings.Contains(string(body), "http://forum.test/t/1") {
+		t.Errorf("gövde eşleşmeleri içermeli:\n%s", body)
+	}
+}
+
+func TestSMTPPermanentRejectIsNotRetried(t *testing.T) {
+	fastRetries(t)
+	db := newTestDB(t)
+	host, port, _ := fakeSMTP(t, "550 5.1.1 Alıcı yok")
+	ch := models.AlertChannel{Name: "mail", Type: models.ChannelSMTP, SMTPHost: host, SMTPPort: port, SMTPFrom: "a@b.test", SMTPTo: "yok@b.test"}
+
+	delivery := DeliverAlert(db, ch, testAlert(), nil, nil)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 1 || !strings.Contains(delivery.Error, "550") {
+		t.Errorf("550 tek denemede başarısız olmalı: %+v", delivery)
+	}
+}
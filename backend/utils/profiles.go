@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"scraper/models"
+	"scraper/scraper"
+
+	"gorm.io/gorm"
+)
+
+// FindExtractionProfile: Hedef URL için tanımlı aktif seçici profilini bulur (yoksa nil)
+func FindExtractionProfile(db *gorm.DB, targetURL string) *models.ExtractionProfile {
+	var profiles []models.ExtractionProfile
+	if err := db.Where("is_active = ?", true).Find(&profiles).Error; err != nil || len(profiles) == 0 {
+		return nil
+	}
+
+	var site models.Site
+	db.Where("url = ?", targetURL).Limit(1).Find(&site)
+
+	return scraper.MatchProfile(profiles, site.ID, targetURL)
+}
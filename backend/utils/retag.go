@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"scraper/models"
+	"scraper/scraper"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Yeniden etiketleme tetikleyicileri
+const (
+	RetagManual  = "manual"  // API üzerinden elle başlatıldı
+	RetagKeyword = "keyword" // Keyword eklendi, güncellendi veya silindi
+)
+
+// Her transaction'da işlenen konu sayısı (ilerleme de bu aralıkla yayınlanır)
+const retagBatchSize = 200
+
+// Retagger: Kayıtlı konu ve iletileri güncel keyword listesiyle yeniden sınıflandıran tek işçili kuyruk.
+// Sırada bekleyen iş varken gelen yeni istekler o işe eklenir; art arda keyword değişiklikleri tek işte toplanır.
+type Retagger struct {
+	db   *gorm.DB
+	wake chan struct{}
+	mu   sync.Mutex
+}
+
+// StartRetagger: İşçiyi başlatır. Önceki çalışmadan kalan yarım işler başarısız sayılır.
+func StartRetagger(db *gorm.DB) *Retagger {
+	now := time.Now()
+	db.Model(&models.RetagJob{}).
+		Where("status IN ?", []string{models.JobQueued, models.JobRunning}).
+		Updates(map[string]interface{}{"status": models.JobFailed, "error": "Sunucu yeniden başlatıldı", "finished_at": &now})
+
+	r := &Retagger{db: db, wake: make(chan struct{}, 1)}
+	go r.worker()
+	return r
+}
+
+// Enqueue: Yeniden etiketleme işi oluşturur. Sırada bekleyen bir iş varsa onu döndürür.
+func (r *Retagger) Enqueue(trigger string) (*models.RetagJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var job models.RetagJob
+	r.db.Where("status = ?", models.JobQueued).Order("id").Limit(1).Find(&job)
+	if job.ID == 0 {
+		job = models.RetagJob{Trigger: trigger, Status: models.JobQueued}
+		if err := r.db.Create(&job).Error; err != nil {
+			return nil, err
+		}
+		publishRetag(&job)
+	}
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return &job, nil
+}
+
+func (r *Retagger) worker() {
+	for range r.wake {
+		for {
+			var job models.RetagJob
+			r.db.Where("status = ?", models.JobQueued).Order("id").Limit(1).Find(&job)
+			if job.ID == 0 {
+				break
+			}
+			r.run(&job)
+		}
+	}
+}
+
+func (r *Retagger) run(job *models.RetagJob) {
+	startTime := time.Now()
+	res := r.db.Model(&models.RetagJob{}).
+		Where("id = ? AND status = ?", job.ID, models.JobQueued).
+		Updates(map[string]interface{}{"status": models.JobRunning, "started_at": &startTime})
+	if res.RowsAffected == 0 {
+		return
+	}
+	job.Status = models.JobRunning
+	job.StartedAt = &startTime
+	job.Transitions = map[string]int{}
+
+	// Keyword'ler iş başladıktan sonra okunur; sıradayken yapılan değişiklikler de dahil olur
+	var keywords []models.Keyword
+	r.db.Find(&keywords)
+	matcher := scraper.NewKeywordMatcher(keywords)
+
+	var total int64
+	r.db.Model(&models.Thread{}).Count(&total)
+	job.TotalThreads = int(total)
+	publishRetag(job)
+	LogInfo(r.db, "TAGGER", fmt.Sprintf("Yeniden etiketleme başlatıldı: %d konu, %d keyword (İş #%d)", total, len(keywords), job.ID))
+
+	var lastID uint
+	for {
+		var threads []models.Thread
+		if err := r.db.Where("id > ?", lastID).Order("id").Limit(retagBatchSize).Find(&threads).Error; err != nil {
+			r.finish(job, startTime, err)
+			return
+		}
+		if len(threads) == 0 {
+			break
+		}
+		lastID = threads[len(threads)-1].ID
+
+		if err := r.db.Transaction(func(tx *gorm.DB) error {
+			return retagBatch(tx, matcher, threads, job)
+		}); err != nil {
+			r.finish(job, startTime, err)
+			return
+		}
+
+		job.ProcessedThreads += len(threads)
+		r.db.Model(job).Select("total_threads", "processed_threads", "processed_posts", "category_changed", "tags_changed", "transitions").Updates(job)
+		publishRetag(job)
+	}
+
+	r.finish(job, startTime, nil)
+}
+
+// retagBatch: Konu grubunu ve iletilerini etiketler, değişen etiketleri ve kategorileri yazar
+func retagBatch(tx *gorm.DB, matcher *scraper.KeywordMatcher, threads []models.Thread, job *models.RetagJob) error {
+	threadIDs := make([]uint, len(threads))
+	for i, t := range threads {
+		threadIDs[i] = t.ID
+	}
+
+	var posts []models.Post
+	if err := tx.Where("thread_id IN ?", threadIDs).Order("thread_id, \"order\", id").Find(&posts).Error; err != nil {
+		return err
+	}
+	postsByThread := make(map[uint][]models.Post)
+	for _, p := range posts {
+		postsByThread[p.ThreadID] = append(postsByThread[p.ThreadID], p)
+	}
+
+	var existing []models.ContentTag
+	if err := tx.Where("thread_id IN ?", threadIDs).Find(&existing).Error; err != nil {
+		return err
+	}
+	current := make(map[[2]uint][]models.ContentTag)
+	for _, tag := range existing {
+		key := [2]uint{tag.ThreadID, tag.PostID}
+		current[key] = append(current[key], tag)
+	}
+
+	// Etiketler değiştiyse yazar
+	apply := func(threadID, postID uint, tags []models.ContentTag) error {
+		if tagSignature(current[[2]uint{threadID, postID}]) == tagSignature(tags) {
+			return nil
+		}
+		job.TagsChanged++
+		return replaceTags(tx, threadID, postID, tags)
+	}
+
+	for _, t := range threads {
+		titleTags := matcher.Match(t.Title)
+		if err := apply(t.ID, 0, titleTags); err != nil {
+			return err
+		}
+
+		var firstPostTags []models.ContentTag
+		for i, p := range postsByThread[t.ID] {
+			tags := matcher.Match(p.Content)
+			if i == 0 {
+				firstPostTags = tags
+			}
+			if err := apply(t.ID, p.ID, tags); err != nil {
+				return err
+			}
+			job.ProcessedPosts++
+		}
+
+		// Kategori taramadaki gibi başlık + ilk ileti etiketlerinden belirlenir
+		category := scraper.BestCategory(titleTags, firstPostTags)
+		if category != t.Category {
+			if err := tx.Model(&models.Thread{}).Where("id = ?", t.ID).Update("category", category).Error; err != nil {
+				return err
+			}
+			job.CategoryChanged++
+			job.Transitions[fmt.Sprintf("%s -> %s", t.Category, category)]++
+		}
+	}
+	return nil
+}
+
+// tagSignature: Etiket listesini sıradan bağımsız karşılaştırmak için metne çevirir
+func tagSignature(tags []models.ContentTag) string {
+	type sig struct {
+		KeywordID uint
+		Word      string
+		Category  string
+		Score     float64
+		Matches   []models.MatchSpan
+	}
+	list := make([]sig, len(tags))
+	for i, t := range tags {
+		list[i] = sig{t.KeywordID, t.Word, t.Category, t.Score, t.Matches}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].KeywordID < list[j].KeywordID })
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func (r *Retagger) finish(job *models.RetagJob, startTime time.Time, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Duration = time.Since(startTime).Seconds()
+	if err != nil {
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+		LogError(r.db, "TAGGER", fmt.Sprintf("Yeniden etiketleme başarısız (İş #%d): %v", job.ID, err))
+	} else {
+		job.Status = models.JobSucceeded
+		LogSuccess(r.db, "TAGGER", fmt.Sprintf("Yeniden etiketleme tamamlandı: %d konu, %d ileti işlendi, %d konunun kategorisi, %d içeriğin etiketleri değişti (Süre: %.2fs)",
+			job.ProcessedThreads, job.ProcessedPosts, job.CategoryChanged, job.TagsChanged, job.Duration))
+	}
+	r.db.Model(job).Select("status", "error", "total_threads", "processed_threads", "processed_posts", "category_changed", "tags_changed", "transitions", "duration", "finished_at").Updates(job)
+	publishRetag(job)
+}
+
+// publishRetag: İşin güncel durumunu ve ilerlemesini canlı akışa gönderir
+func publishRetag(job *models.RetagJob) {
+	snapshot := *job
+	// İşçi haritayı güncellemeye devam ettiği için abonelere kopyası gönderilir
+	snapshot.Transitions = make(map[string]int, len(job.Transitions))
+	for k, v := range job.Transitions {
+		snapshot.Transitions[k] = v
+	}
+	Events.Publish(RetagTopic(job.ID), Event{Type: EventStatus, JobID: job.ID, Data: snapshot, Time: time.Now()})
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"scraper/config"
+	"scraper/models"
+	"scraper/scraper"
+	"strings"
+	"time"
+)
+
+// RetryPolicyFor: Genel yeniden deneme ayarlarına watchlist öğesinin farklı değerlerini uygular ve sonucu doğrular
+func RetryPolicyFor(o models.RetryOverride) (scraper.RetryPolicy, error) {
+	p := config.Get().Scan.RetryPolicy()
+	if o.MaxAttempts > 0 {
+		p.MaxAttempts = o.MaxAttempts
+	}
+	if o.BaseBackoff > 0 {
+		p.BaseBackoff = time.Duration(o.BaseBackoff) * time.Second
+	}
+	if o.MaxBackoff > 0 {
+		p.MaxBackoff = time.Duration(o.MaxBackoff) * time.Second
+	}
+	if o.Jitter != nil {
+		p.Jitter = *o.Jitter
+	}
+	if codes := splitCodes(o.RetryOn); len(codes) > 0 {
+		p.RetryOn = codes
+	}
+	if o.SwitchCircuit != nil {
+		p.SwitchCircuit = *o.SwitchCircuit
+	}
+	// Öğe sadece ilk beklemeyi büyüttüyse genel üst sınır ona uyarlanır
+	if o.MaxBackoff == 0 && p.MaxBackoff < p.BaseBackoff {
+		p.MaxBackoff = p.BaseBackoff
+	}
+	return p, p.Validate()
+}
+
+func splitCodes(s string) []string {
+	var codes []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
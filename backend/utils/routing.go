@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"scraper/config"
+	"scraper/scraper"
+)
+
+var router *scraper.Router
+
+// StartRouting: Ayarlardaki kurallar ve adlandırılmış proxy'lerle hedef yönlendiricisini kurar
+func StartRouting() {
+	cfg := config.Get().Routing
+
+	proxies := make(map[string]string, len(cfg.Proxies)+1)
+	for name, p := range cfg.Proxies {
+		proxies[name] = p
+	}
+	if cfg.I2PProxy != "" {
+		proxies[config.RouteI2P] = cfg.I2PProxy
+	}
+	rules := make([]scraper.RouteRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, scraper.RouteRule{Suffix: r.Suffix, Route: r.Route})
+	}
+
+	router = scraper.NewRouter(cfg.Default, rules, proxies)
+	log.Printf("Yönlendirme başlatıldı - varsayılan: %s, %d kural", cfg.Default, len(rules))
+}
+
+func currentRouter() *scraper.Router {
+	if router == nil {
+		return scraper.NewRouter(config.RouteTor, nil, nil)
+	}
+	return router
+}
+
+// ResolveRoute: Hedefin yolunu seçer (override boşsa host kuralları ve varsayılan yol)
+func ResolveRoute(targetURL, override string) (scraper.Route, error) {
+	return currentRouter().Resolve(targetURL, override)
+}
+
+// ValidateRouteName: Watchlist veya tarama isteğinde seçilen yolun tanımlı olduğunu kontrol eder (boş = otomatik)
+func ValidateRouteName(name string) error {
+	if name == "" || config.Get().Routing.HasRoute(name) {
+		return nil
+	}
+	return fmt.Errorf("Tanımsız bağlantı yolu: %s", name)
+}
+
+// NormalizeTarget: URL'i tamamlar. Uzantısız host'lara .onion sadece Tor yolunda (seçilen veya varsayılan) ve
+// host hiçbir kurala (örn. .i2p, pastebin.com) uymuyorsa eklenir.
+func NormalizeTarget(raw, override string) string {
+	routing := config.Get().Routing
+	name := override
+	if name == "" {
+		name = routing.Default
+	}
+	complete := routing.CompleteOnion && name == config.RouteTor
+	if complete {
+		if u, err := url.Parse(scraper.NormalizeURL(raw, false)); err == nil {
+			if _, ok := currentRouter().Match(u.Hostname()); ok {
+				complete = false
+			}
+		}
+	}
+	return scraper.NormalizeURL(raw, complete)
+}
+
+// ApplyRoute: Tarama seçeneklerine yolun proxy ayarını yazar.
+// Tor yolunda her deneme havuzdan ayrı devre alır; havuz yoksa Tor'suz bağlantı kurulmaz.
+func ApplyRoute(opts *scraper.ScanOptions, route scraper.Route, isolationKey string) error {
+	opts.Route = route.Name
+	if route.Name != scraper.RouteTor {
+		opts.Proxy = route.Proxy
+		return nil
+	}
+	proxies := TorProxies()
+	if proxies == nil {
+		return scraper.ErrNoHealthyProxy
+	}
+	opts.Proxies = proxies
+	opts.IsolationKey = isolationKey
+	return nil
+}
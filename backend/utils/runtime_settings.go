@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"scraper/config"
+	"scraper/models"
+	"scraper/scraper"
+
+	"gorm.io/gorm"
+)
+
+// LoadRuntimeSettings: Veritabanında saklanan çalışma zamanı ayarlarını dosya/ortam değerlerinin üzerine uygular.
+// Saklanan değerler artık geçersizse (örn. sınırlar değiştiyse) yok sayılır ve uyarı loglanır.
+func LoadRuntimeSettings(db *gorm.DB) {
+	var rows []models.RuntimeSetting
+	if err := db.Find(&rows).Error; err != nil || len(rows) == 0 {
+		return
+	}
+
+	tunables, err := mergeTunables(config.Base().Tunables(), rows)
+	if err == nil {
+		err = config.SetTunables(tunables)
+	}
+	if err != nil {
+		LogWarn(db, "CONFIG", "Kayıtlı çalışma zamanı ayarları uygulanamadı, dosya/ortam değerleri kullanılıyor: "+err.Error())
+		return
+	}
+	LogInfo(db, "CONFIG", fmt.Sprintf("%d çalışma zamanı ayarı veritabanından yüklendi", len(rows)))
+}
+
+// RuntimeOverrides: Veritabanında değiştirilmiş ayarlar (dosya/ortam değerini ezenler)
+func RuntimeOverrides(db *gorm.DB) []models.RuntimeSetting {
+	rows := []models.RuntimeSetting{}
+	db.Order("key").Find(&rows)
+	return rows
+}
+
+// SaveTunables: Yeni değerleri doğrular, uygular ve dosya/ortam değerinden farklı olanları saklar.
+// Dosya/ortam değerine geri çekilen ayarların satırı silinir.
+func SaveTunables(db *gorm.DB, tunables config.Tunables, updatedBy string) error {
+	if err := tunables.Validate(); err != nil {
+		return err
+	}
+
+	baseValues, err := tunableValues(config.Base().Tunables())
+	if err != nil {
+		return err
+	}
+	values, err := tunableValues(tunables)
+	if err != nil {
+		return err
+	}
+
+	stored := map[string]string{}
+	for _, row := range RuntimeOverrides(db) {
+		stored[row.Key] = row.Value
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for key, value := range values {
+			if current, ok := stored[key]; ok && current == value {
+				continue // Değişmeyen ayarın kim tarafından ne zaman yazıldığı korunur
+			}
+			if value == baseValues[key] {
+				if err := tx.Delete(&models.RuntimeSetting{}, "key = ?", key).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			row := models.RuntimeSetting{Key: key, Value: value, UpdatedBy: updatedBy}
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return config.SetTunables(tunables)
+}
+
+// ResetTunables: Saklanan tüm değişiklikleri siler ve dosya/ortam değerlerine döner
+func ResetTunables(db *gorm.DB) error {
+	if err := db.Where("1 = 1").Delete(&models.RuntimeSetting{}).Error; err != nil {
+		return err
+	}
+	return config.SetTunables(config.Base().Tunables())
+}
+
+// EngineOptions: Güncel ayarlardan tarama motoru seçeneklerini kurar
+func EngineOptions() scraper.EngineOptions {
+	scan := config.Get().Scan
+	return scraper.EngineOptions{
+		RequestTimeout: scan.Timeout.Std(),
+		Retry:          scan.RetryPolicy(),
+		ContentLimit:   scan.ContentLimit,
+	}
+}
+
+// tunableValues: Her alanı JSON adı -> JSON değer olarak döndürür
+func tunableValues(t config.Tunables) (map[string]string, error) {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(fields))
+	for k, v := range fields {
+		values[k] = string(v)
+	}
+	return values, nil
+}
+
+// mergeTunables: Saklanan satırları temel değerlerin üzerine yazar. Tanınmayan anahtarlar atlanır.
+func mergeTunables(base config.Tunables, rows []models.RuntimeSetting) (config.Tunables, error) {
+	known, err := tunableValues(base)
+	if err != nil {
+		return base, err
+	}
+	fields := map[string]json.RawMessage{}
+	for _, row := range rows {
+		if _, ok := known[row.Key]; ok {
+			fields[row.Key] = json.RawMessage(row.Value)
+		}
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return base, err
+	}
+	if err := json.Unmarshal(raw, &base); err != nil {
+		return base, err
+	}
+	return base, nil
+}
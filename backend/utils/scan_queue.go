@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"scraper/models"
+	"scraper/scraper"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Varsayılan kuyruk boyutları
+const (
+	DefaultScanWorkers = 3
+	scanQueueSize      = 100
+)
+
+// ScanTask: Kuyruktaki bir işin çalıştırma parametreleri
+type ScanTask struct {
+	URL         string
+	Source      string // manual veya watchlist
+	RandomUA    bool
+	Crawl       scraper.CrawlOptions
+	Route       string               // Seçilen bağlantı yolu (boşsa host kuralları)
+	Retry       models.RetryOverride // Watchlist öğesine özel yeniden deneme ayarları
+	WatchlistID *uint
+}
+
+// ScanQueue: Manuel taramalar ve watchlist tarafından paylaşılan sınırlı işçi havuzu
+type ScanQueue struct {
+	db    *gorm.DB
+	tasks chan queuedTask
+
+	mu      sync.Mutex
+	cancels map[uint]context.CancelFunc // Çalışan işlerin iptal fonksiyonları
+}
+
+type queuedTask struct {
+	jobID uint
+	task  ScanTask
+}
+
+// StartScanQueue: İşçileri başlatır. Önceki çalışmadan kalan yarım işler başarısız sayılır.
+func StartScanQueue(db *gorm.DB, workers int) *ScanQueue {
+	if workers <= 0 {
+		workers = DefaultScanWorkers
+	}
+
+	q := &ScanQueue{
+		db:      db,
+		tasks:   make(chan queuedTask, scanQueueSize),
+		cancels: make(map[uint]context.CancelFunc),
+	}
+
+	// Sunucu kapanırken kuyrukta veya çalışır durumda kalan işler
+	now := time.Now()
+	db.Model(&models.ScanJob{}).
+		Where("status IN ?", []string{models.JobQueued, models.JobRunning}).
+		Updates(map[string]interface{}{"status": models.JobFailed, "error": "Sunucu yeniden başlatıldı", "finished_at": &now})
+
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+
+	log.Printf("Tarama kuyruğu başlatıldı - %d işçi", workers)
+	return q
+}
+
+// Enqueue: İşi veritabanına "queued" olarak yazar ve kuyruğa ekler
+func (q *ScanQueue) Enqueue(task ScanTask) (*models.ScanJob, error) {
+	if task.Source == "" {
+		task.Source = "manual"
+	}
+
+	// Yol kuyruğa alınırken seçilir, iş kaydında hangi yoldan taranacağı görünür
+	route, err := ResolveRoute(task.URL, task.Route)
+	if err != nil {
+		return nil, err
+	}
+	task.Route = route.Name
+
+	job := &models.ScanJob{
+		URL:         task.URL,
+		Source:      task.Source,
+		WatchlistID: task.WatchlistID,
+		Route:       route.Name,
+		Status:      models.JobQueued,
+	}
+	if err := q.db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.tasks <- queuedTask{jobID: job.ID, task: task}:
+		return job, nil
+	default:
+		q.finish(job, models.JobFailed, "Tarama kuyruğu dolu")
+		return nil, fmt.Errorf("Tarama kuyruğu dolu, lütfen daha sonra tekrar deneyin")
+	}
+}
+
+// Cancel: Kuyruktaki işi iptal eder veya çalışan işin bağlamını keser
+func (q *ScanQueue) Cancel(jobID uint) (*models.ScanJob, error) {
+	var job models.ScanJob
+	if err := q.db.First(&job, jobID).Error; err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return &job, fmt.Errorf("İş zaten tamamlanmış (%s)", job.Status)
+	}
+
+	// Henüz başlamamış: İşçi sırası geldiğinde atlar
+	now := time.Now()
+	res := q.db.Model(&models.ScanJob{}).
+		Where("id = ? AND status = ?", jobID, models.JobQueued).
+		Updates(map[string]interface{}{"status": models.JobCancelled, "finished_at": &now})
+	if res.RowsAffected > 0 {
+		job.Status = models.JobCancelled
+		job.FinishedAt = &now
+		publishStatus(&job)
+		return &job, nil
+	}
+
+	// Çalışıyor: Durum işçi tarafından "cancelled" olarak yazılır
+	q.mu.Lock()
+	cancel, running := q.cancels[jobID]
+	q.mu.Unlock()
+	if running {
+		cancel()
+	}
+	return &job, nil
+}
+
+func (q *ScanQueue) worker() {
+	for qt := range q.tasks {
+		q.run(qt)
+	}
+}
+
+func (q *ScanQueue) run(qt queuedTask) {
+	var job models.ScanJob
+	if err := q.db.First(&job, qt.jobID).Error; err != nil {
+		return // Silinmiş
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.mu.Lock()
+	q.cancels[job.ID] = cancel
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.cancels, job.ID)
+		q.mu.Unlock()
+		cancel()
+	}()
+
+	// Sadece hâlâ kuyruktaysa başlat (kuyruktayken iptal edilmiş olabilir)
+	startTime := time.Now()
+	res := q.db.Model(&models.ScanJob{}).
+		Where("id = ? AND status = ?", job.ID, models.JobQueued).
+		Updates(map[string]interface{}{"status": models.JobRunning, "started_at": &startTime})
+	if res.RowsAffected == 0 {
+		return
+	}
+	job.Status = models.JobRunning
+	job.StartedAt = &startTime
+	publishStatus(&job)
+
+	task := qt.task
+	logSource := "SCANNER"
+	if task.Source == "watchlist" {
+		logSource = "WATCHLIST"
+	}
+	LogInfo(q.db, logSource, fmt.Sprintf("Tarama başlatıldı: %s (İş #%d)", task.URL, job.ID))
+
+	var attempts []scraper.AttemptRecord
+	result, err := q.scan(ctx, job.ID, task, func(a scraper.AttemptRecord) { attempts = append(attempts, a) })
+	job.Duration = time.Since(startTime).Seconds()
+	job.Attempts, _ = json.Marshal(attempts)
+
+	status, errMsg := models.JobSucceeded, ""
+	switch {
+	case ctx.Err() != nil:
+		LogWarn(q.db, logSource, fmt.Sprintf("Tarama iptal edildi: %s (İş #%d)", task.URL, job.ID))
+		job.ErrorCode = scraper.CodeCancelled
+		status = models.JobCancelled
+
+	case err != nil:
+		se := scraper.AsScanError(err)
+		job.ErrorCode = se.Code
+		LogErrorCode(q.db, logSource, se.Code, fmt.Sprintf("Site tarama hatası: %s - %s", task.URL, se.Detail()))
+		status, errMsg = models.JobFailed, "Site Taranamadı: "+err.Error()
+
+	case !result.IsForum:
+		// Sayfa alındı ama kaydedilmez; iş not_forum koduyla başarısız sayılır, sonuç inceleme için saklanır
+		job.ErrorCode = scraper.CodeNotForum
+		LogErrorCode(q.db, logSource, scraper.CodeNotForum, fmt.Sprintf("Hedef forum yapısına uymuyor: %s (Süre: %.2fs)", result.URL, job.Duration))
+		status, errMsg = models.JobFailed, "Site Taranamadı: "+scraper.ErrNotForum.Message
+		job.Result, _ = json.Marshal(result)
+
+	default:
+		job.Result, _ = json.Marshal(result)
+		stats, err := SaveScanResult(q.db, result, task.Source)
+		if err != nil {
+			// Kayıt geri alındı; sonuç iş kaydında kalır
+			job.ErrorCode = scraper.CodeUnknown
+			LogErrorCode(q.db, logSource, scraper.CodeUnknown, fmt.Sprintf("Tarama sonucu kaydedilemedi: %s - %v", task.URL, err))
+			status, errMsg = models.JobFailed, "Tarama sonucu kaydedilemedi: "+err.Error()
+			break
+		}
+		EvaluateAlerts(q.db, stats, task.WatchlistID)
+		job.Saved = true
+		job.StatsID = &stats.ID
+		change := "değişiklik var"
+		if !stats.Changed {
+			change = "değişiklik yok"
+		}
+		LogSuccess(q.db, logSource, fmt.Sprintf("Tarama tamamlandı: %s (%d thread, %d post, %s, Süre: %.2fs)", result.URL, result.ThreadCount, result.PostCount, change, job.Duration))
+	}
+
+	// HTTP üst verisi ve ham sayfalar başarısız (403, CAPTCHA) ve forum olmayan taramalarda da işe bağlanarak saklanır.
+	// İş bitmiş görünmeden önce yazılır; böylece bitişi bekleyen istemci arşivi eksiksiz bulur.
+	if result != nil && ctx.Err() == nil {
+		SaveResponseMetadata(q.db, job.ID, job.StatsID, result.Responses)
+		SaveSnapshots(q.db, job.ID, job.StatsID, result.Responses)
+	}
+	q.finish(&job, status, errMsg)
+
+	if task.WatchlistID != nil {
+		q.recordWatchlistCheck(*task.WatchlistID, &job)
+	}
+}
+
+// recordWatchlistCheck: Son kontrol zamanını ve art arda hata sayacını iş durumuna göre günceller. İptal edilen taramalar sayılmaz.
+func (q *ScanQueue) recordWatchlistCheck(watchlistID uint, job *models.ScanJob) {
+	now := time.Now()
+	updates := map[string]interface{}{"last_checked": &now}
+	switch job.Status {
+	case models.JobCancelled:
+	case models.JobFailed:
+		updates["failures"] = gorm.Expr("failures + 1")
+		updates["last_error_code"] = job.ErrorCode
+		updates["last_error"] = job.Error
+	default:
+		updates["failures"] = 0
+		updates["last_error_code"] = ""
+		updates["last_error"] = ""
+	}
+	q.db.Model(&models.Watchlist{}).Where("id = ?", watchlistID).Updates(updates)
+}
+
+// scan: Keyword, UA ve profil hazırlığını yapıp taramayı seçilen yol üzerinden çalıştırır.
+// Denemeler genel (varsa watchlist öğesinin) yeniden deneme politikasına göre tekrarlanır.
+func (q *ScanQueue) scan(ctx context.Context, jobID uint, task ScanTask, onAttempt func(scraper.AttemptRecord)) (*scraper.ScrapeResult, error) {
+	route, err := ResolveRoute(task.URL, task.Route)
+	if err != nil {
+		return nil, err
+	}
+	engine := EngineOptions()
+	if engine.Retry, err = RetryPolicyFor(task.Retry); err != nil {
+		return nil, err
+	}
+
+	var keywords []models.Keyword
+	q.db.Find(&keywords)
+
+	var userAgents []string
+	if task.RandomUA {
+		var uaList []models.UserAgent
+		q.db.Find(&uaList)
+		for _, ua := range uaList {
+			userAgents = append(userAgents, ua.UserAgent)
+		}
+	}
+
+	opts := scraper.ScanOptions{
+		Engine:     engine,
+		Keywords:   keywords,
+		UserAgents: userAgents,
+		Crawl:      task.Crawl,
+		Profile:    FindExtractionProfile(q.db, task.URL),
+		OnEvent: func(ev scraper.ScanEvent) {
+			Events.Publish(JobTopic(jobID), Event{Type: EventScan, JobID: jobID, Data: ev, Time: ev.Time})
+		},
+		OnAttempt: onAttempt,
+	}
+	if err := ApplyRoute(&opts, route, fmt.Sprintf("job%d", jobID)); err != nil {
+		return nil, err
+	}
+	return scraper.AnalyzeSite(ctx, task.URL, opts)
+}
+
+func (q *ScanQueue) finish(job *models.ScanJob, status, errMsg string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = errMsg
+	job.FinishedAt = &now
+	q.db.Model(job).Select("status", "error", "error_code", "saved", "stats_id", "result", "attempts", "duration", "finished_at").Updates(job)
+	publishStatus(job)
+}
+
+// publishStatus: İşin güncel durumunu canlı akışa gönderir
+func publishStatus(job *models.ScanJob) {
+	Events.Publish(JobTopic(job.ID), Event{Type: EventStatus, JobID: job.ID, Data: *job})
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"scraper/models"
+	"scraper/scraper"
+	"testing"
+)
+
+func TestRecordWatchlistCheckFollowsJobStatus(t *testing.T) {
+	db := newTestDB(t)
+	q := &ScanQueue{db: db}
+	item := models.Watchlist{URL: "http://forum.test/"}
+	db.Create(&item)
+
+	steps := []struct {
+		name     string
+		job      models.ScanJob
+		failures int
+		code     string
+	}{
+		{"başarısız", models.ScanJob{Status: models.JobFailed, ErrorCode: scraper.CodeHTTPStatus, Error: "Site Taranamadı: HTTP 503"}, 1, scraper.CodeHTTPStatus},
+		{"tekrar başarısız", models.ScanJob{Status: models.JobFailed, ErrorCode: scraper.CodeTimeout, Error: "Site Taranamadı: zaman aşımı"}, 2, scraper.CodeTimeout},
+		{"iptal sayılmaz", models.ScanJob{Status: models.JobCancelled, ErrorCode: scraper.CodeCancelled}, 2, scraper.CodeTimeout},
+		{"forum değil", models.ScanJob{Status: models.JobFailed, ErrorCode: scraper.CodeNotForum, Error: "Site Taranamadı: " + scraper.ErrNotForum.Message}, 3, scraper.CodeNotForum},
+		{"başarılı", models.ScanJob{Status: models.JobSucceeded, Saved: true}, 0, ""},
+	}
+	for _, step := range steps {
+		q.recordWatchlistCheck(item.ID, &step.job)
+
+		var got models.Watchlist
+		db.First(&got, item.ID)
+		if got.Failures != step.failures || got.LastErrorCode != step.code {
+			t.Errorf("%s: %d hata (%q), beklenen %d (%q)", step.name, got.Failures, got.LastErrorCode, step.failures, step.code)
+		}
+		if got.LastChecked == nil {
+			t.Errorf("%s: kontrol zamanı yazılmadı", step.name)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"scraper/models"
+	"scraper/scraper"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SaveScanResult: Tarama sonucunu Site/Stats kayıtlarına ve tekil Thread/Post tablolarına yazar, Stats kaydını döndürür.
+// Daha önce görülmüş konu ve iletiler tekrar eklenmez, sadece bu taramadaki görülmeleri kaydedilir.
+// source "manual" veya "watchlist" olabilir. Sitenin önceki taramasıyla karşılaştırılıp Changed işaretlenir.
+// Kayıt tek işlemde yapılır; hata olursa görülmesi eksik bir Stats kaydı kalmaz ve hata döner.
+func SaveScanResult(db *gorm.DB, result *scraper.ScrapeResult, source string) (models.Stats, error) {
+	now := time.Now()
+	var stats models.Stats
+	err := db.Transaction(func(tx *gorm.DB) error {
+		site := models.Site{
+			URL:      result.URL,
+			LastScan: now,
+		}
+		err := tx.Where(models.Site{URL: result.URL}).Assign(models.Site{
+			LastScan: now,
+		}).FirstOrCreate(&site).Error
+		if err != nil {
+			return err
+		}
+
+		stats = models.Stats{
+			SiteID:       site.ID,
+			Source:       source,
+			Engine:       result.Engine,
+			TotalThreads: result.ThreadCount,
+			TotalPosts:   result.PostCount,
+			ScanDate:     now,
+		}
+		if err := tx.Create(&stats).Error; err != nil {
+			return err
+		}
+
+		for _, t := range result.Threads {
+			if err := saveThread(tx, site.ID, stats.ID, t, now); err != nil {
+				return err
+			}
+		}
+		return markChanges(tx, &stats)
+	})
+	if err != nil {
+		return models.Stats{}, err
+	}
+	return stats, nil
+}
+
+// SaveResponseMetadata: Taramada alınan sayfaların HTTP üst verisini işe ve (kaydedildiyse) Stats kaydına bağlar
+func SaveResponseMetadata(db *gorm.DB, jobID uint, statsID *uint, responses []scraper.ResponseMeta) {
+	if len(responses) == 0 {
+		return
+	}
+	rows := make([]models.ScanMetadata, 0, len(responses))
+	for _, r := range responses {
+		row := models.ScanMetadata{
+			JobID:       jobID,
+			URL:         r.URL,
+			FinalURL:    r.FinalURL,
+			Depth:       r.Depth,
+			StatusCode:  r.StatusCode,
+			ContentType: r.ContentType,
+			Server:      r.Server,
+			BodySize:    r.BodySize,
+			LatencyMs:   r.LatencyMs,
+		}
+		row.Headers, _ = json.Marshal(r.Headers)
+		if len(r.Redirects) > 0 {
+			row.Redirects, _ = json.Marshal(r.Redirects)
+		}
+		if statsID != nil {
+			row.StatsID = *statsID
+		}
+		rows = append(rows, row)
+	}
+	if err := db.CreateInBatches(rows, 100).Error; err != nil {
+		log.Printf("Tarama üst verisi kaydedilemedi: %v", err)
+	}
+}
+
+// LoadScanMetadata: Taramanın HTTP üst verisi, hedef sayfa önce gelecek şekilde
+func LoadScanMetadata(db *gorm.DB, statsID uint) ([]models.ScanMetadata, error) {
+	rows := []models.ScanMetadata{}
+	err := db.Where("stats_id = ?", statsID).Order("depth, id").Find(&rows).Error
+	return rows, err
+}
+
+// LoadJobMetadata: Tarama işinin HTTP üst verisi (kaydedilmemiş taramalar dahil), hedef sayfa önce gelecek şekilde
+func LoadJobMetadata(db *gorm.DB, jobID uint) ([]models.ScanMetadata, error) {
+	rows := []models.ScanMetadata{}
+	err := db.Where("job_id = ?", jobID).Order("depth, id").Find(&rows).Error
+	return rows, err
+}
+
+// saveThread: Konuyu site + bağlantı kimliğiyle bulur veya oluşturur, iletileriyle birlikte görülme kaydı ekler
+func saveThread(tx *gorm.DB, siteID, statsID uint, t scraper.ThreadData, now time.Time) error {
+	threadKey := ThreadKey(siteID, t.ThreadID, t.Link, t.Title)
+
+	var thread models.Thread
+	err := tx.Where(models.Thread{SiteID: siteID, Key: threadKey}).
+		Attrs(models.Thread{StatsID: statsID, FirstSeen: now}).
+		Assign(map[string]interface{}{
+			"remote_id": t.ThreadID,
+			"hash":      ThreadHash(t.Title, t.Author),
+			"title":     t.Title,
+			"link":      t.Link,
+			"author":    t.Author,
+			"date":      t.Date,
+			"category":  t.Category,
+			"replies":   t.Replies,
+			"views":     t.Views,
+			"last_seen": now,
+		}).
+		FirstOrCreate(&thread).Error
+	if err != nil {
+		return err
+	}
+
+	if err := indexThread(tx, thread); err != nil {
+		return err
+	}
+	// Başlık ve keyword listesi değişmiş olabileceğinden konu etiketleri her taramada yenilenir
+	if err := replaceTags(tx, thread.ID, 0, t.Tags); err != nil {
+		return err
+	}
+
+	sighting := models.ThreadSighting{ThreadID: thread.ID, StatsID: statsID}
+	err = tx.Where(sighting).
+		Assign(models.ThreadSighting{Replies: t.Replies, Views: t.Views}).
+		FirstOrCreate(&sighting).Error
+	if err != nil {
+		return err
+	}
+
+	for i, p := range t.Posts {
+		order := i + 1
+		var post models.Post
+		err := tx.Where(models.Post{ThreadID: thread.ID, Key: PostKey(threadKey, p.PostID, order), Hash: PostHash(p.Content)}).
+			Attrs(models.Post{
+				RemoteID:  p.PostID,
+				Author:    p.Author,
+				Content:   p.Content,
+				Date:      p.Date,
+				Order:     order,
+				Quotes:    p.ModelQuotes(),
+				FirstSeen: now,
+			}).
+			Assign(map[string]interface{}{"last_edited": p.LastEdited, "last_seen": now}).
+			FirstOrCreate(&post).Error
+		if err != nil {
+			return err
+		}
+
+		// Sadece ilk kez görülen ileti dizine eklenir ve etiketlenir
+		if post.FirstSeen.Equal(now) {
+			if err := indexPost(tx, thread, post); err != nil {
+				return err
+			}
+			if err := replaceTags(tx, thread.ID, post.ID, p.Tags); err != nil {
+				return err
+			}
+		}
+
+		postSighting := models.PostSighting{PostID: post.ID, StatsID: statsID}
+		err = tx.Where(postSighting).
+			Assign(models.PostSighting{Order: order, LastEdited: p.LastEdited}).
+			FirstOrCreate(&postSighting).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTags: Konu başlığının (postID 0) veya iletinin etiketlerini verilen listeyle değiştirir
+func replaceTags(tx *gorm.DB, threadID, postID uint, tags []models.ContentTag) error {
+	if err := tx.Where("thread_id = ? AND post_id = ?", threadID, postID).Delete(&models.ContentTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]models.ContentTag, len(tags))
+	for i, t := range tags {
+		t.ID = 0
+		t.ThreadID = threadID
+		t.PostID = postID
+		rows[i] = t
+	}
+	return tx.Create(&rows).Error
+}
+
+// LoadScanThreads: Bir taramada görülen konuları, o taramada görülen iletileriyle döndürür.
+// StatsID, yanıt/görüntülenme sayıları ve ileti sırası o taramanın değerleridir.
+func LoadScanThreads(db *gorm.DB, statsID uint) ([]models.Thread, error) {
+	var threadSightings []models.ThreadSighting
+	if err := db.Where("stats_id = ?", statsID).Order("id").Find(&threadSightings).Error; err != nil {
+		return nil, err
+	}
+	if len(threadSightings) == 0 {
+		return []models.Thread{}, nil
+	}
+
+	threadIDs := make([]uint, 0, len(threadSightings))
+	for _, s := range threadSightings {
+		threadIDs = append(threadIDs, s.ThreadID)
+	}
+
+	var threadRows []models.Thread
+	if err := db.Where("id IN ?", threadIDs).Find(&threadRows).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Thread, len(threadRows))
+	for _, t := range threadRows {
+		byID[t.ID] = t
+	}
+
+	// Bu taramada görülen iletiler
+	var posts []models.Post
+	err := db.Table("posts").
+		Select("posts.*, post_sightings.\"order\" AS \"order\", post_sightings.last_edited AS last_edited").
+		Joins("JOIN post_sightings ON post_sightings.post_id = posts.id").
+		Where("post_sightings.stats_id = ? AND posts.thread_id IN ?", statsID, threadIDs).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Etiketler: post_id 0 olanlar konu başlığına aittir
+	var tags []models.ContentTag
+	if err := db.Where("thread_id IN ?", threadIDs).Order("score desc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	threadTags := make(map[uint][]models.ContentTag)
+	postTags := make(map[uint][]models.ContentTag)
+	for _, tag := range tags {
+		if tag.PostID == 0 {
+			threadTags[tag.ThreadID] = append(threadTags[tag.ThreadID], tag)
+		} else {
+			postTags[tag.PostID] = append(postTags[tag.PostID], tag)
+		}
+	}
+
+	postsByThread := make(map[uint][]models.Post)
+	for _, p := range posts {
+		p.Tags = postTags[p.ID]
+		if p.Tags == nil {
+			p.Tags = []models.ContentTag{}
+		}
+		postsByThread[p.ThreadID] = append(postsByThread[p.ThreadID], p)
+	}
+
+	threads := make([]models.Thread, 0, len(threadSightings))
+	for _, s := range threadSightings {
+		t, ok := byID[s.ThreadID]
+		if !ok {
+			continue
+		}
+		t.StatsID = statsID
+		t.Replies = s.Replies
+		t.Views = s.Views
+		t.Posts = postsByThread[t.ID]
+		if t.Posts == nil {
+			t.Posts = []models.Post{}
+		}
+		t.Tags = threadTags[t.ID]
+		if t.Tags == nil {
+			t.Tags = []models.ContentTag{}
+		}
+		sort.Slice(t.Posts, func(i, j int) bool { return t.Posts[i].Order < t.Posts[j].Order })
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+// markChanges: Taramayı aynı sitenin bir önceki taramasıyla karşılaştırır.
+// İlk tarama değişmiş sayılır.
+func markChanges(db *gorm.DB, stats *models.Stats) error {
+	stats.Changed = true
+
+	var previous models.Stats
+	db.Where("site_id = ? AND id < ?", stats.SiteID, stats.ID).Order("id desc").Limit(1).Find(&previous)
+	if previous.ID != 0 {
+		stats.PreviousID = &previous.ID
+		if diff, err := DiffScans(db, stats.ID, previous.ID); err == nil {
+			stats.Changed = diff.Changed
+		}
+	}
+
+	return db.Model(stats).Select("previous_id", "changed").Updates(stats).Error
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"scraper/models"
+	"scraper/scraper"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// saveScan: Sonucu kaydeder, hata olursa testi durdurur
+func saveScan(t *testing.T, db *gorm.DB, result *scraper.ScrapeResult) models.Stats {
+	t.Helper()
+	stats, err := SaveScanResult(db, result, "manual")
+	if err != nil {
+		t.Fatalf("tarama sonucu kaydedilemedi: %v", err)
+	}
+	return stats
+}
+
+// Konu satırlarında bağlantı olmayan liste sayfası
+const linklessListing = `<html><head><title>Duyurular</title></head><body>
+<div class="thread"><h3>Kurallar güncellendi</h3><span>Başlatan: admin</span></div>
+<div class="thread"><h3>Yeni bölüm açıldı</h3><span>Başlatan: moderator</span></div>
+</body></html>`
+
+func TestLinklessRowsStaySeparateThreads(t *testing.T) {
+	db := newTestDB(t)
+	const url = "http://forum.test/forums/duyurular"
+
+	result := offlineScan(t, url, linklessListing)
+	if len(result.Threads) != 2 {
+		t.Fatalf("2 konu satırı bekleniyordu, %d", len(result.Threads))
+	}
+	for _, th := range result.Threads {
+		if th.Link != "" {
+			t.Errorf("bağlantısız satıra sayfa adresi verildi: %q", th.Link)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		stats := saveScan(t, db, offlineScan(t, url, linklessListing))
+		if stats.TotalThreads != 2 {
+			t.Errorf("tarama %d: %d konu sayıldı, beklenen 2", i+1, stats.TotalThreads)
+		}
+		var sightings int64
+		db.Model(&models.ThreadSighting{}).Where("stats_id = ?", stats.ID).Count(&sightings)
+		if sightings != int64(stats.TotalThreads) {
+			t.Errorf("tarama %d: %d konu görülmesi, %d konu", i+1, sightings, stats.TotalThreads)
+		}
+	}
+
+	var titles []string
+	db.Model(&models.Thread{}).Order("title").Pluck("title", &titles)
+	if len(titles) != 2 || titles[0] != "Kurallar güncellendi" || titles[1] != "Yeni bölüm açıldı" {
+		t.Errorf("konular birleşti veya üzerine yazıldı: %q", titles)
+	}
+}
+
+func TestSaveScanResultRollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+	result := offlineScan(t, "http://forum.test/konu/3", datelessThread)
+
+	// İleti yazımı başarısız olursa Site, Stats ve görülmeler de geri alınmalı
+	db.Callback().Create().Before("gorm:create").Register("test:fail_posts", func(tx *gorm.DB) {
+		if tx.Statement.Table == "posts" {
+			tx.AddError(errors.New("disk dolu"))
+		}
+	})
+	if _, err := SaveScanResult(db, result, "manual"); err == nil {
+		t.Fatal("kayıt hatası dönmeliydi")
+	}
+
+	for _, model := range []interface{}{&models.Site{}, &models.Stats{}, &models.Thread{}, &models.ThreadSighting{}, &models.PostSighting{}} {
+		var n int64
+		db.Model(model).Count(&n)
+		if n != 0 {
+			t.Errorf("%T için %d kayıt kaldı", model, n)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"log"
+	"scraper/models"
+
+	"gorm.io/gorm"
+)
+
+// Arama dizinindeki kayıt türleri
+const (
+	SearchKindThread = "thread"
+	SearchKindPost   = "post"
+)
+
+// EnsureSearchIndex: FTS5 arama tablosunu oluşturur ve boşsa mevcut konu/iletilerle doldurur.
+// Konu başlıkları, ileti içerikleri ve yazar adları dizinlenir; diğer kolonlar süzme içindir.
+// rowid konular için 2*id, iletiler için 2*id+1'dir; böylece kayıt silme/güncelleme tüm dizini taramaz.
+func EnsureSearchIndex(db *gorm.DB) error {
+	err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		title, content, author,
+		kind UNINDEXED, ref_id UNINDEXED, thread_id UNINDEXED, site_id UNINDEXED,
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`).Error
+	if err != nil {
+		return err
+	}
+
+	var indexed, threads int64
+	db.Raw("SELECT COUNT(*) FROM search_index").Scan(&indexed)
+	db.Model(&models.Thread{}).Count(&threads)
+	if indexed == 0 && threads > 0 {
+		return RebuildSearchIndex(db)
+	}
+	return nil
+}
+
+// RebuildSearchIndex: Dizini tamamen silip threads/posts tablolarından yeniden oluşturur
+func RebuildSearchIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_index").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO search_index (rowid, title, content, author, kind, ref_id, thread_id, site_id)
+			SELECT id * 2, title, '', author, ?, id, id, site_id FROM threads`, SearchKindThread).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO search_index (rowid, title, content, author, kind, ref_id, thread_id, site_id)
+			SELECT posts.id * 2 + 1, '', posts.content, posts.author, ?, posts.id, threads.id, threads.site_id
+			FROM posts JOIN threads ON threads.id = posts.thread_id`, SearchKindPost).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		tx.Raw("SELECT COUNT(*) FROM search_index").Scan(&count)
+		log.Printf("Arama dizini oluşturuldu: %d kayıt", count)
+		return nil
+	})
+}
+
+// indexThread: Konu başlığını dizine yazar (başlık değişmiş olabileceği için kayıt yenilenir)
+func indexThread(db *gorm.DB, t models.Thread) error {
+	return db.Exec(`INSERT OR REPLACE INTO search_index (rowid, title, content, author, kind, ref_id, thread_id, site_id) VALUES (?, ?, '', ?, ?, ?, ?, ?)`,
+		t.ID*2, t.Title, t.Author, SearchKindThread, t.ID, t.ID, t.SiteID).Error
+}
+
+// indexPost: İletiyi dizine yazar. İçerik değişen ileti yeni kayıt olduğundan eski sürüm de aranabilir kalır.
+func indexPost(db *gorm.DB, t models.Thread, p models.Post) error {
+	return db.Exec(`INSERT OR REPLACE INTO search_index (rowid, title, content, author, kind, ref_id, thread_id, site_id) VALUES (?, '', ?, ?, ?, ?, ?, ?)`,
+		p.ID*2+1, p.Content, p.Author, SearchKindPost, p.ID, t.ID, t.SiteID).Error
+}
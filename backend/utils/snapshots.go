@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"scraper/config"
+	"scraper/models"
+	"scraper/scraper"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Arşiv temizliği sıklığı ve boyut sınırı aşıldığında tek seferde silinen kayıt sayısı
+const (
+	snapshotPruneInterval = 1 * time.Hour
+	snapshotPruneBatch    = 100
+)
+
+var (
+	snapshotDir string     // Boşsa arşiv kapalıdır
+	snapshotMu  sync.Mutex // Kayıt ve temizlik aynı anda çalışmaz (yeni bağlanan blob silinmesin)
+)
+
+// StartSnapshots: Ham sayfa arşivi dizinini hazırlar ve saklama politikasını saatte bir uygular
+func StartSnapshots(db *gorm.DB) {
+	cfg := config.Get().Snapshots
+	if !cfg.Enabled {
+		log.Println("Sayfa arşivi kapalı")
+		return
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		log.Printf("Sayfa arşivi dizini oluşturulamadı (%s): %v", cfg.Dir, err)
+		return
+	}
+	snapshotDir = cfg.Dir
+	log.Printf("Sayfa arşivi başlatıldı - dizin: %s, saklama: %s, sınır: %d MB", cfg.Dir, cfg.MaxAge, cfg.MaxSizeMB)
+
+	go func() {
+		ticker := time.NewTicker(snapshotPruneInterval)
+		defer ticker.Stop()
+		for {
+			PruneSnapshots(db)
+			<-ticker.C
+		}
+	}()
+}
+
+// SnapshotsEnabled: Arşiv açık ve dizini hazır mı
+func SnapshotsEnabled() bool {
+	return snapshotDir != ""
+}
+
+// SaveSnapshots: Taramada alınan sayfaların ham gövdelerini arşive yazar ve işe bağlar
+func SaveSnapshots(db *gorm.DB, jobID uint, statsID *uint, responses []scraper.ResponseMeta) {
+	if !SnapshotsEnabled() {
+		return
+	}
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	for _, r := range responses {
+		if len(r.Body) == 0 {
+			continue
+		}
+		hash, err := storeBlob(db, r.Body)
+		if err != nil {
+			log.Printf("Sayfa arşive yazılamadı (%s): %v", r.URL, err)
+			continue
+		}
+		db.Create(&models.Snapshot{
+			JobID:       jobID,
+			StatsID:     statsID,
+			URL:         r.URL,
+			FinalURL:    r.FinalURL,
+			Depth:       r.Depth,
+			StatusCode:  r.StatusCode,
+			ContentType: r.ContentType,
+			Hash:        hash,
+			Size:        int64(len(r.Body)),
+		})
+	}
+}
+
+// storeBlob: Gövdeyi SHA-256 özetiyle adlandırılmış gzip dosyasına yazar. Aynı içerik zaten varsa tekrar yazılmaz.
+func storeBlob(db *gorm.DB, body []byte) (string, error) {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	path := blobPath(hash)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return "", err
+		}
+		// Yarım dosya bırakmamak için geçici dosyaya yazılıp taşınır
+		tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+		if err != nil {
+			return "", err
+		}
+		zw := gzip.NewWriter(tmp)
+		_, err = zw.Write(body)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return "", err
+		}
+		if info, err = os.Stat(path); err != nil {
+			return "", err
+		}
+	}
+
+	blob := models.SnapshotBlob{Hash: hash, Size: int64(len(body)), StoredSize: info.Size()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&blob).Error; err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func blobPath(hash string) string {
+	return filepath.Join(snapshotDir, hash[:2], hash+".gz")
+}
+
+// ReadSnapshot: Arşivdeki ham gövdeyi açar ve özetini doğrular (delil bütünlüğü)
+func ReadSnapshot(snap models.Snapshot) ([]byte, error) {
+	if !SnapshotsEnabled() {
+		return nil, fmt.Errorf("Sayfa arşivi kapalı")
+	}
+	if len(snap.Hash) != sha256.Size*2 {
+		return nil, fmt.Errorf("Geçersiz arşiv özeti")
+	}
+	f, err := os.Open(blobPath(snap.Hash))
+	if err != nil {
+		return nil, fmt.Errorf("Arşiv dosyası bulunamadı: %s", snap.Hash)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("Arşiv dosyası bozuk: %v", err)
+	}
+	defer zr.Close()
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("Arşiv dosyası bozuk: %v", err)
+	}
+	if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != snap.Hash {
+		return nil, fmt.Errorf("Arşiv dosyası özetle eşleşmiyor: %s", snap.Hash)
+	}
+	return body, nil
+}
+
+// SnapshotPages: İşin arşivlenmiş sayfalarını çevrimdışı ayrıştırma için yükler (hedef sayfa önce)
+func SnapshotPages(db *gorm.DB, jobID uint) ([]scraper.SnapshotPage, []models.Snapshot, error) {
+	var snaps []models.Snapshot
+	if err := db.Where("job_id = ?", jobID).Order("depth, id").Find(&snaps).Error; err != nil {
+		return nil, nil, err
+	}
+	pages := make([]scraper.SnapshotPage, 0, len(snaps))
+	for _, s := range snaps {
+		body, err := ReadSnapshot(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		pages = append(pages, scraper.SnapshotPage{URL: s.URL, FinalURL: s.FinalURL, StatusCode: s.StatusCode, ContentType: s.ContentType, Body: body})
+	}
+	return pages, snaps, nil
+}
+
+// PruneSnapshots: Saklama süresini aşan kayıtları, toplam boyut sınırı aşılıyorsa en eskilerden başlayarak
+// diğer kayıtları siler. Hiçbir kayda bağlı olmayan blob dosyaları da silinir.
+func PruneSnapshots(db *gorm.DB) {
+	if !SnapshotsEnabled() {
+		return
+	}
+	cfg := config.Get().Snapshots
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	var removed int64
+	if cfg.MaxAge > 0 {
+		res := db.Where("created_at < ?", time.Now().Add(-cfg.MaxAge.Std())).Delete(&models.Snapshot{})
+		removed += res.RowsAffected
+	}
+	freed := pruneOrphanBlobs(db)
+
+	if limit := cfg.MaxBytes(); limit > 0 {
+		for archiveSize(db) > limit {
+			var ids []uint
+			db.Model(&models.Snapshot{}).Order("created_at, id").Limit(snapshotPruneBatch).Pluck("id", &ids)
+			if len(ids) == 0 {
+				break
+			}
+			res := db.Delete(&models.Snapshot{}, ids)
+			removed += res.RowsAffected
+			freed += pruneOrphanBlobs(db)
+		}
+	}
+
+	if removed > 0 || freed > 0 {
+		LogInfo(db, "SNAPSHOT", fmt.Sprintf("Sayfa arşivi temizlendi: %d kayıt, %d dosya silindi", removed, freed))
+	}
+}
+
+// PruneOrphanSnapshots: Kaydı kalmayan blob dosyalarını saatlik temizliği beklemeden siler, silinen dosya sayısını döner
+func PruneOrphanSnapshots(db *gorm.DB) int {
+	if !SnapshotsEnabled() {
+		return 0
+	}
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+	return pruneOrphanBlobs(db)
+}
+
+// pruneOrphanBlobs: Hiçbir arşiv kaydının göstermediği blob dosyalarını ve satırlarını siler
+func pruneOrphanBlobs(db *gorm.DB) int {
+	var hashes []string
+	db.Model(&models.SnapshotBlob{}).
+		Where("hash NOT IN (?)", db.Model(&models.Snapshot{}).Distinct("hash")).
+		Pluck("hash", &hashes)
+
+	freed := 0
+	for _, hash := range hashes {
+		if err := os.Remove(blobPath(hash)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Arşiv dosyası silinemedi (%s): %v", hash, err)
+			continue
+		}
+		db.Delete(&models.SnapshotBlob{}, "hash = ?", hash)
+		freed++
+	}
+	return freed
+}
+
+// archiveSize: Arşivin diskteki toplam (sıkıştırılmış) boyutu
+func archiveSize(db *gorm.DB) int64 {
+	var total int64
+	db.Model(&models.SnapshotBlob{}).Select("COALESCE(SUM(stored_size), 0)").Scan(&total)
+	return total
+}
+
+// SnapshotUsage: Arşivdeki kayıt ve dosya sayısı ile diskteki boyut
+func SnapshotUsage(db *gorm.DB) (snapshots, blobs, bytes int64) {
+	db.Model(&models.Snapshot{}).Count(&snapshots)
+	db.Model(&models.SnapshotBlob{}).Count(&blobs)
+	return snapshots, blobs, archiveSize(db)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"scraper/models"
+	"testing"
+)
+
+func TestPruneOrphanSnapshotsRemovesUnlinkedBlobs(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&models.Snapshot{}, &models.SnapshotBlob{}); err != nil {
+		t.Fatalf("taşıma başarısız: %v", err)
+	}
+	snapshotDir = t.TempDir()
+	t.Cleanup(func() { snapshotDir = "" })
+
+	kept, err := storeBlob(db, []byte("<html>kalan</html>"))
+	if err != nil {
+		t.Fatalf("blob yazılamadı: %v", err)
+	}
+	orphan, err := storeBlob(db, []byte("<html>sahipsiz</html>"))
+	if err != nil {
+		t.Fatalf("blob yazılamadı: %v", err)
+	}
+	db.Create(&models.Snapshot{JobID: 1, URL: "https://forum.example", Hash: kept})
+
+	if freed := PruneOrphanSnapshots(db); freed != 1 {
+		t.Fatalf("1 dosya silinmeliydi, %d", freed)
+	}
+	if _, err := os.Stat(blobPath(orphan)); !os.IsNotExist(err) {
+		t.Errorf("sahipsiz blob dosyası diskte kaldı: %v", err)
+	}
+	if _, err := os.Stat(blobPath(kept)); err != nil {
+		t.Errorf("kayda bağlı blob silindi: %v", err)
+	}
+	var rows []string
+	db.Model(&models.SnapshotBlob{}).Pluck("hash", &rows)
+	if len(rows) != 1 || rows[0] != kept {
+		t.Errorf("kalan blob satırları %v", rows)
+	}
+}
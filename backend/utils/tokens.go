@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"scraper/config"
+	"scraper/models"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Token süreleri
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// Yenileme hataları
+var (
+	ErrInvalidRefreshToken = errors.New("Geçersiz veya süresi dolmuş oturum")
+	ErrRefreshTokenReused  = errors.New("Oturum token'ı tekrar kullanıldı, tüm oturumlar kapatıldı")
+)
+
+// TokenPair: Girişte ve yenilemede istemciye verilen token'lar
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Erişim token'ının ömrü (saniye)
+}
+
+// HashToken: Yenileme token'ının veritabanında saklanan özeti
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+var errMissingSecret = errors.New("JWT_SECRET tanımlı değil")
+
+func jwtSecret() ([]byte, error) {
+	secret := config.Get().Auth.JWTSecret
+	if secret == "" {
+		return nil, errMissingSecret
+	}
+	return []byte(secret), nil
+}
+
+// parseJWT: HMAC imzalı token'ı doğrular ve içeriğini döndürür
+func parseJWT(tokenString string) (jwt.MapClaims, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("beklenmeyen imza yöntemi: %v", token.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("geçersiz token")
+	}
+	return claims, nil
+}
+
+// signAccessToken: Kullanıcı ID, ad ve rolünü taşıyan kısa ömürlü token üretir.
+// Şifre değiştirmesi gereken kullanıcının token'ı sadece şifre değiştirme rotalarında geçerlidir (pwc).
+func signAccessToken(user models.User, jti string, expiresAt time.Time) (string, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti":  jti,
+		"sub":  user.ID,
+		"name": user.Username,
+		"role": user.Role,
+		"pwc":  user.MustChangePassword,
+		"exp":  expiresAt.Unix(),
+	})
+	return token.SignedString(secret)
+}
+
+// newSession: Oturum kaydını ve token çiftini hazırlar (kayıt çağıran tarafından yazılır)
+func newSession(user models.User, ip, userAgent string) (*models.Session, TokenPair, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, TokenPair{}, err
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return nil, TokenPair{}, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:          user.ID,
+		TokenHash:       HashToken(refresh),
+		AccessJTI:       jti,
+		AccessExpiresAt: now.Add(AccessTokenTTL),
+		ExpiresAt:       now.Add(RefreshTokenTTL),
+		IP:              ip,
+		UserAgent:       userAgent,
+	}
+	access, err := signAccessToken(user, jti, session.AccessExpiresAt)
+	if err != nil {
+		return nil, TokenPair{}, err
+	}
+	return session, TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: int(AccessTokenTTL.Seconds())}, nil
+}
+
+// CreateSession: Giriş sonrası yeni oturum açar
+func CreateSession(db *gorm.DB, user models.User, ip, userAgent string) (TokenPair, error) {
+	session, pair, err := newSession(user, ip, userAgent)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := db.Create(session).Error; err != nil {
+		return TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// RotateSession: Yenileme token'ını tek kullanımlık olarak harcar ve yerine yeni oturum açar.
+// İptal edilmiş (daha önce harcanmış) bir token tekrar gelirse çalınmış sayılır; kullanıcının tüm oturumları kapatılır.
+func RotateSession(db *gorm.DB, refreshToken, ip, userAgent string) (models.User, TokenPair, error) {
+	var user models.User
+	var pair TokenPair
+	var reused *models.Session
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var old models.Session
+		if err := tx.Where("token_hash = ?", HashToken(refreshToken)).First(&old).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if old.RevokedAt != nil {
+			if old.ReplacedByID != nil {
+				reused = &old
+				return ErrRefreshTokenReused
+			}
+			return ErrInvalidRefreshToken
+		}
+		if time.Now().After(old.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+		if err := tx.First(&user, old.UserID).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		session, newPair, err := newSession(user, ip, userAgent)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		res := tx.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{"revoked_at": &now, "replaced_by_id": session.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// Aynı token eşzamanlı olarak başka bir istekte harcandı
+			return ErrInvalidRefreshToken
+		}
+		if err := revokeAccessToken(tx, old); err != nil {
+			return err
+		}
+		pair = newPair
+		return nil
+	})
+
+	if reused != nil {
+		count, _ := RevokeUserSessions(db, reused.UserID)
+		LogWarn(db, "AUTH", fmt.Sprintf("Kullanılmış yenileme token'ı tekrar denendi (Kullanıcı #%d, IP: %s), %d oturum kapatıldı", reused.UserID, ip, count))
+	}
+	return user, pair, err
+}
+
+// revokeAccessToken: Oturumun süresi dolmamış erişim token'ını iptal listesine ekler
+func revokeAccessToken(tx *gorm.DB, session models.Session) error {
+	if session.AccessJTI == "" || time.Now().After(session.AccessExpiresAt) {
+		return nil
+	}
+	entry := models.RevokedToken{JTI: session.AccessJTI, UserID: session.UserID, ExpiresAt: session.AccessExpiresAt}
+	return tx.Where(models.RevokedToken{JTI: entry.JTI}).FirstOrCreate(&entry).Error
+}
+
+// RevokeSessionByJTI: Erişim token'ının ait olduğu oturumu kapatır (çıkış)
+func RevokeSessionByJTI(db *gorm.DB, jti string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var session models.Session
+		if err := tx.Where("access_jti = ?", jti).First(&session).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&session).Where("revoked_at IS NULL").Update("revoked_at", &now).Error; err != nil {
+			return err
+		}
+		return revokeAccessToken(tx, session)
+	})
+}
+
+// RevokeUserSessions: Kullanıcının açık tüm oturumlarını ve erişim token'larını iptal eder.
+// Rotasyonla kapanmış oturumların hâlâ geçerli olabilecek erişim token'ları da listeye eklenir.
+func RevokeUserSessions(db *gorm.DB, userID uint) (int64, error) {
+	var revoked int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var sessions []models.Session
+		if err := tx.Where("user_id = ? AND access_expires_at > ?", userID, time.Now()).Find(&sessions).Error; err != nil {
+			return err
+		}
+		for _, s := range sessions {
+			if err := revokeAccessToken(tx, s); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		res := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", &now)
+		revoked = res.RowsAffected
+		return res.Error
+	})
+	return revoked, err
+}
+
+// TokenRevoked: JWT ID iptal listesinde mi
+func TokenRevoked(db *gorm.DB, jti string) bool {
+	var count int64
+	if err := db.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		// Liste okunamıyorsa token kabul edilmez
+		log.Printf("Token iptal listesi okunamadı: %v", err)
+		return true
+	}
+	return count > 0
+}
+
+// StartTokenCleanup: Süresi dolmuş oturumları ve iptal kayıtlarını saatte bir siler
+func StartTokenCleanup(db *gorm.DB) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		db.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+		db.Where("expires_at < ?", now).Delete(&models.Session{})
+		<-ticker.C
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"scraper/config"
+	"scraper/scraper"
+
+	"gorm.io/gorm"
+)
+
+var torPool *scraper.TorPool
+
+// StartTorPool: Ayarlardaki (yoksa yerel varsayılan) uç noktalarla Tor havuzunu kurar ve sağlık kontrollerini başlatır.
+// Uç noktaların sağlık durumu değiştikçe sistem loguna yazılır.
+func StartTorPool(db *gorm.DB) (*scraper.TorPool, error) {
+	cfg := config.Get().Tor
+
+	var endpoints []scraper.TorEndpoint
+	if proxies := cfg.EndpointProxies(); len(proxies) > 0 {
+		for i, p := range proxies {
+			endpoints = append(endpoints, scraper.TorEndpoint{Proxy: p, Control: cfg.ControlFor(i), ControlPassword: cfg.ControlPassword})
+		}
+	} else {
+		endpoints = scraper.DefaultTorEndpoints()
+		for i := range endpoints {
+			endpoints[i].ControlPassword = cfg.ControlPassword
+		}
+	}
+
+	pool, err := scraper.NewTorPool(endpoints, cfg.Isolation)
+	if err != nil {
+		return nil, err
+	}
+	pool.OnHealthChange = func(s scraper.ProxyStats) {
+		if s.Healthy {
+			LogSuccess(db, "TOR", fmt.Sprintf("Tor proxy erişilebilir: %s (%.0fms)", s.Proxy, s.LatencyMs))
+		} else {
+			LogWarn(db, "TOR", fmt.Sprintf("Tor proxy erişilemiyor: %s - %s", s.Proxy, s.LastError))
+		}
+	}
+	pool.StartHealthChecks(cfg.HealthInterval.Std())
+
+	log.Printf("Tor havuzu başlatıldı - %d uç nokta, izolasyon: %v", len(endpoints), cfg.Isolation)
+	torPool = pool
+	return pool, nil
+}
+
+// TorProxies: Taramaların kullandığı Tor havuzu.
+// Havuz kurulmadıysa nil arayüz döner (nil *TorPool arayüze konursa nil sayılmaz).
+func TorProxies() scraper.ProxySource {
+	if torPool == nil {
+		return nil
+	}
+	return torPool
+}
+
+// TorPoolStats: Paneldeki uç nokta durumları
+func TorPoolStats() []scraper.ProxyStats {
+	if torPool == nil {
+		return []scraper.ProxyStats{}
+	}
+	return torPool.Stats()
+}
+
+// NewTorCircuit: Verilen uç noktaya (boşsa tümüne) NEWNYM gönderir
+func NewTorCircuit(proxy string) error {
+	if torPool == nil {
+		return fmt.Errorf("Tor havuzu çalışmıyor")
+	}
+	return torPool.NewCircuit(proxy)
+}
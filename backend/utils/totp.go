@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"scraper/models"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// RFC 6238 parametreleri (Google Authenticator ve benzerleriyle uyumlu varsayılanlar)
+const (
+	totpIssuer = "Galileoff"
+	totpPeriod = 30 // Saniye
+	totpDigits = 6
+	totpSkew   = 1 // Saat kaymasına karşı önceki/sonraki adım da kabul edilir
+)
+
+// İki adımlı giriş
+const (
+	preAuthTokenType  = "preauth"
+	PreAuthTokenTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var ErrInvalidPreAuthToken = errors.New("Doğrulama süresi doldu, lütfen tekrar giriş yapın")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret: 160 bitlik rastgele paylaşılan anahtar (base32)
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI: Doğrulayıcı uygulamaların QR koduyla okuduğu otpauth:// adresi
+func TOTPURI(account, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", totpIssuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(totpDigits))
+	v.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
+
+// totpCode: Verilen zaman adımı için HOTP değeri (RFC 4226)
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// ValidateTOTP: Kodu şimdiki ve komşu zaman adımlarında dener.
+// Eşleşen adım döndürülür; aynı adımın ikinci kez kullanılmaması çağıranın sorumluluğundadır.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes: Kullanıcının eski kodlarını silip yeni tek kullanımlık kodlar üretir.
+// Düz metin kodlar sadece bir kez gösterilir; veritabanında özetleri tutulur.
+func GenerateRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b)) // 8 karakter
+		codes[i] = raw[:4] + "-" + raw[4:]
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: HashToken(raw)}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode: Kullanılmamış kodu harcar. Tire ve büyük/küçük harf fark etmez.
+func UseRecoveryCode(db *gorm.DB, userID uint, code string) bool {
+	raw := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	now := time.Now()
+	res := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, HashToken(raw)).
+		Update("used_at", &now)
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// DisableTwoFactor: Anahtarı ve kurtarma kodlarını siler
+func DisableTwoFactor(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_enabled":        false,
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_last_step":      0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// SignPreAuthToken: Şifresi doğrulanan ama ikinci adımı bekleyen kullanıcı için kısa ömürlü token.
+// Bu token korumalı rotalarda geçmez, sadece /login/2fa tarafından kabul edilir.
+func SignPreAuthToken(user models.User) (string, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", err
+	}
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ": preAuthTokenType,
+		"jti": jti,
+		"sub": user.ID,
+		"exp": time.Now().Add(PreAuthTokenTTL).Unix(),
+	})
+	return token.SignedString(secret)
+}
+
+// PreAuth: Doğrulanmış ara token'ın içeriği
+type PreAuth struct {
+	UserID    uint
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// ParsePreAuthToken: Ara token'ı doğrular. Token, ikinci adım başarılı olana kadar tekrar denenebilir.
+func ParsePreAuthToken(db *gorm.DB, tokenString string) (PreAuth, error) {
+	claims, err := parseJWT(tokenString)
+	if err != nil {
+		return PreAuth{}, ErrInvalidPreAuthToken
+	}
+	typ, _ := claims["typ"].(string)
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(float64)
+	exp, _ := claims["exp"].(float64)
+	if typ != preAuthTokenType || jti == "" || sub <= 0 || TokenRevoked(db, jti) {
+		return PreAuth{}, ErrInvalidPreAuthToken
+	}
+	return PreAuth{UserID: uint(sub), JTI: jti, ExpiresAt: time.Unix(int64(exp), 0)}, nil
+}
+
+// ConsumePreAuth: Başarılı ikinci adımdan sonra ara token'ı iptal listesine ekler.
+// Aynı token eşzamanlı iki istekte kullanıldıysa ikincisi hata alır.
+func ConsumePreAuth(db *gorm.DB, p PreAuth) error {
+	entry := models.RevokedToken{JTI: p.JTI, UserID: p.UserID, ExpiresAt: p.ExpiresAt}
+	if err := db.Create(&entry).Error; err != nil {
+		return ErrInvalidPreAuthToken
+	}
+	return nil
+}
@@ -1,9 +1,8 @@
 package utils
 
 import (
-	"fmt"
 	"log"
-	"os"
+	"scraper/config"
 	"scraper/models"
 	"scraper/scraper"
 	"time"
@@ -11,14 +10,27 @@ import (
 	"gorm.io/gorm"
 )
 
-// WatchlistScheduler: Watchlist'teki siteleri düzenli aralıklarla kontrol eder ve tarar
-func StartWatchlistScheduler(db *gorm.DB) {
-	ticker := time.NewTicker(1 * time.Minute) // Her dakika kontrol et
+// WatchlistScheduler: Watchlist'teki siteleri düzenli aralıklarla tarama kuyruğuna ekler.
+// Kontrol aralığı ayarlardan okunur; Ayarlar sayfasından değiştirilirse hemen uygulanır.
+func StartWatchlistScheduler(db *gorm.DB, queue *ScanQueue) {
+	tick := config.Get().Scheduler.Tick.Std()
+	ticker := time.NewTicker(tick)
 	defer ticker.Stop()
 
-	log.Println("Watchlist scheduler başlatıldı - her dakika kontrol edilecek")
+	log.Printf("Watchlist scheduler başlatıldı - her %s kontrol edilecek", tick)
+
+	for {
+		select {
+		case <-config.TunablesChanged():
+			if next := config.Get().Scheduler.Tick.Std(); next != tick {
+				tick = next
+				ticker.Reset(tick)
+				log.Printf("Watchlist kontrol aralığı güncellendi: %s", tick)
+			}
+			continue
+		case <-ticker.C:
+		}
 
-	for range ticker.C {
 		// Watchlist aktif mi kontrol et
 		var watchlist []models.Watchlist
 		now := time.Now()
@@ -32,118 +44,34 @@ func StartWatchlistScheduler(db *gorm.DB) {
 
 		log.Printf("Watchlist: %d site taranacak", len(watchlist))
 
-		for _, item := range watchlist {
-			go processWatchlistItem(db, &item)
+		for i := range watchlist {
+			enqueueWatchlistItem(db, queue, &watchlist[i])
 		}
 	}
 }
 
-func processWatchlistItem(db *gorm.DB, item *models.Watchlist) {
-	log.Printf("Watchlist taraması başlatılıyor: %s", item.URL)
-
-	// Tor Proxy Hazırlığı
-	torProxy := os.Getenv("TOR_PROXY")
-	if torProxy == "" {
-		activeProxy, err := scraper.GetActiveTorProxy()
-		if err != nil {
-			LogError(db, "WATCHLIST", fmt.Sprintf("Watchlist taraması başarısız (Tor proxy bulunamadı): %s", item.URL))
-			return
-		}
-		torProxy = activeProxy
-	}
-
-	// Keywords getir
-	var keywords []models.Keyword
-	db.Find(&keywords)
-
-	// User Agents getir
-	var userAgents []string
-	var uaList []models.UserAgent
-	db.Find(&uaList)
-	for _, ua := range uaList {
-		userAgents = append(userAgents, ua.UserAgent)
-	}
-
-	// URL'i normalize et
-	normalizedURL := scraper.NormalizeURL(item.URL)
-
-	// Siteyi tara
-	result, err := scraper.AnalyzeSite(normalizedURL, torProxy, keywords, userAgents)
-	if err != nil {
-		LogError(db, "WATCHLIST", fmt.Sprintf("Watchlist tarama hatası: %s - %v", item.URL, err))
-		updateNextCheck(db, item)
-		return
-	}
-
-	if result.ErrorMessage != "" {
-		LogError(db, "WATCHLIST", fmt.Sprintf("Watchlist erişim hatası: %s - %s", item.URL, result.ErrorMessage))
-		updateNextCheck(db, item)
-		return
-	}
-
-	// Sadece forum ise kaydet
-	if result.IsForum {
-		saveWatchlistResult(db, result, item)
-		LogSuccess(db, "WATCHLIST", fmt.Sprintf("Watchlist taraması tamamlandı: %s (%d thread, %d post)", item.URL, result.ThreadCount, result.PostCount))
-	} else {
-		LogWarn(db, "WATCHLIST", fmt.Sprintf("Watchlist sitesi forum değil: %s", item.URL))
-	}
-
-	// Bir sonraki kontrol zamanını güncelle
+func enqueueWatchlistItem(db *gorm.DB, queue *ScanQueue, item *models.Watchlist) {
+	// Tarama sürerken aynı öğe tekrar kuyruğa girmesin
 	updateNextCheck(db, item)
-}
-
-func saveWatchlistResult(db *gorm.DB, result *scraper.ScrapeResult, watchlistItem *models.Watchlist) {
-	// Site kaydı oluştur veya güncelle
-	site := models.Site{
-		URL:      result.URL,
-		LastScan: time.Now(),
-	}
-	db.Where(models.Site{URL: result.URL}).Assign(models.Site{
-		LastScan: time.Now(),
-	}).FirstOrCreate(&site)
-
-	// Stats kaydı - watchlist olarak işaretle
-	stats := models.Stats{
-		SiteID:       site.ID,
-		Source:       "watchlist", // Watchlistden geldiğini işaretle
-		TotalThreads: result.ThreadCount,
-		TotalPosts:   result.PostCount,
-		ScanDate:     time.Now(),
-	}
-	db.Create(&stats)
 
-	// Thread ve Post kayıtları (goroutine)
-	go func(siteID, statsID uint, threads []scraper.ThreadData) {
-		for _, t := range threads {
-			thread := models.Thread{
-				SiteID:   siteID,
-				StatsID:  statsID,
-				Title:    t.Title,
-				Link:     t.Link,
-				Author:   t.Author,
-				Date:     t.Date,
-				Category: t.Category,
-			}
-			db.Create(&thread)
-
-			for i, p := range t.Posts {
-				db.Create(&models.Post{
-					ThreadID: thread.ID,
-					Author:   p.Author,
-					Content:  p.Content,
-					Date:     p.Date,
-					Order:    i + 1,
-				})
-			}
-		}
-	}(site.ID, stats.ID, result.Threads)
-
-	// Watchlist itemin last_checkedinı güncelle
-	now := time.Now()
-	db.Model(watchlistItem).Updates(map[string]interface{}{
-		"last_checked": &now,
+	watchlistID := item.ID
+	_, err := queue.Enqueue(ScanTask{
+		URL:      NormalizeTarget(item.URL, item.Route),
+		Source:   "watchlist",
+		RandomUA: true,
+		Crawl: scraper.CrawlOptions{
+			Enabled:       item.CrawlEnabled,
+			MaxDepth:      item.CrawlMaxDepth,
+			MaxPages:      item.CrawlMaxPages,
+			AllowExternal: item.CrawlExternal,
+		},
+		Route:       item.Route,
+		Retry:       item.Retry,
+		WatchlistID: &watchlistID,
 	})
+	if err != nil {
+		LogError(db, "WATCHLIST", "Watchlist taraması kuyruğa alınamadı: "+item.URL+" - "+err.Error())
+	}
 }
 
 func updateNextCheck(db *gorm.DB, item *models.Watchlist) {
@@ -4,6 +4,8 @@ import { useState, useEffect } from 'react';
 import dynamic from 'next/dynamic';
 import Login from '../components/Login';
 import Sidebar from '../components/Sidebar';
+import PasswordChange from '../components/PasswordChange';
+import api from '../utils/api';
 
 // Dinamik importlar (SSR kapalı)
 const Scanner = dynamic(() => import('../components/Scanner'), { ssr: false });
@@ -17,6 +19,8 @@ export default function Home() {
   const [isLoading, setIsLoading] = useState(true);
   const [activeTab, setActiveTab] = useState('dashboard');
   const [scanResult, setScanResult] = useState(null);
+  const [role, setRole] = useState('viewer');
+  const [mustChangePassword, setMustChangePassword] = useState(false);
 
   useEffect(() => {
     // Sayfa yenilendiğinde oturumu koru
@@ -24,6 +28,8 @@ export default function Home() {
       const token = sessionStorage.getItem('authToken');
       if (token) {
         setIsAuthenticated(true);
+        setRole(sessionStorage.getItem('userRole') || 'viewer');
+        setMustChangePassword(sessionStorage.getItem('mustChangePassword') === 'true');
         const savedTab = sessionStorage.getItem('activeTab');
         if (savedTab) {
           setActiveTab(savedTab);
@@ -40,15 +46,37 @@ export default function Home() {
     }
   }, [activeTab, isAuthenticated]);
 
-  const handleLoginSuccess = (token) => {
-    sessionStorage.setItem('authToken', token);
+  const handleLoginSuccess = (data) => {
+    sessionStorage.setItem('authToken', data.token);
+    sessionStorage.setItem('refreshToken', data.refresh_token);
+    sessionStorage.setItem('userRole', data.role);
+    sessionStorage.setItem('mustChangePassword', data.must_change_password ? 'true' : 'false');
     sessionStorage.setItem('activeTab', 'dashboard');
+    setRole(data.role);
+    setMustChangePassword(!!data.must_change_password);
     setActiveTab('dashboard');
     setIsAuthenticated(true);
   };
 
-  const handleLogout = () => {
+  // Şifre değiştirildiğinde sunucu kısıtsız yeni token döndürür
+  const handlePasswordChanged = (data) => {
+    sessionStorage.setItem('authToken', data.token);
+    sessionStorage.setItem('refreshToken', data.refresh_token);
+    sessionStorage.setItem('mustChangePassword', 'false');
+    setMustChangePassword(false);
+  };
+
+  // Sunucu tarafında oturum kapatılır; istek başarısız olsa da yerel oturum temizlenir
+  const handleLogout = async () => {
+    try {
+      await api.post('/logout');
+    } catch (error) {
+      console.error("Çıkış isteği başarısız", error);
+    }
     sessionStorage.removeItem('authToken');
+    sessionStorage.removeItem('refreshToken');
+    sessionStorage.removeItem('userRole');
+    sessionStorage.removeItem('mustChangePassword');
     sessionStorage.removeItem('activeTab');
     setIsAuthenticated(false);
     setScanResult(null);
@@ -74,6 +102,10 @@ export default function Home() {
     return <Login onLoginSuccess={handleLoginSuccess} />;
   }
 
+  if (mustChangePassword) {
+    return <PasswordChange onChanged={handlePasswordChanged} onLogout={handleLogout} />;
+  }
+
   return (
     <div className="min-h-screen bg-transparent text-white flex overflow-hidden selection:bg-emerald-500/30">
       {/* Yan Menü */}
@@ -81,6 +113,7 @@ export default function Home() {
         activeTab={activeTab}
         setActiveTab={setActiveTab}
         onLogout={handleLogout}
+        role={role}
       />
 
       {/* Ana İçerik Alanı */}
@@ -90,7 +123,7 @@ export default function Home() {
         {activeTab === 'scanner' && <Scanner onScanComplete={handleScanComplete} onChangeTab={setActiveTab} />}
         {activeTab === 'history' && <HistoryPage />}
         {activeTab === 'logs' && <LogsPage />}
-        {activeTab === 'settings' && <SettingsPage />}
+        {activeTab === 'settings' && <SettingsPage role={role} />}
       </main>
     </div>
   );
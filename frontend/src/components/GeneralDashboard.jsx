@@ -333,7 +333,7 @@ export default function GeneralDashboard() {
                                 </div>
                                 <div className="flex items-center gap-1">
                                     <span>GECİKME:</span>
-                                    <span className="text-zinc-300">12ms</span>
+                                    <span className="text-zinc-300">{stats.system_status?.tor_status === 'AKTİF' ? `${stats.system_status?.tor_latency || 0}ms` : '-'}</span>
                                 </div>
                             </div>
                         </div>
@@ -437,8 +437,30 @@ export default function GeneralDashboard() {
                         <div className="p-6 space-y-3">
                             <SystemMetric label="CPU KULLANIMI" value={`${stats.system_status?.cpu || 0}%`} percentage={stats.system_status?.cpu || 0} icon={Cpu} color="text-blue-500" />
                             <SystemMetric label="BELLEK" value={`${stats.system_status?.memory || 0}%`} percentage={stats.system_status?.memory || 0} icon={HardDrive} color="text-purple-500" />
-                            <SystemMetric label="AĞ GECİKMESİ" value="İyi" icon={Wifi} color="text-emerald-500" />
+                            <SystemMetric label="TOR GECİKMESİ" value={stats.system_status?.tor_status === 'AKTİF' ? `${stats.system_status?.tor_latency || 0}ms` : 'YOK'} icon={Wifi} color="text-emerald-500" />
                             <SystemMetric label="BACKEND ZAMANI" value={stats.system_status?.uptime || "0h"} icon={Zap} color="text-amber-500" />
+                            <SystemMetric label="GİRİŞ KİLİTLERİ" value={`${stats.security?.active_lockouts || 0} / ${stats.security?.lockouts_24h || 0}`} icon={Shield} color="text-red-500" />
+
+                            {/* Tor Uç Noktaları */}
+                            <div className="rounded-xl bg-white/5 border border-white/5 p-4">
+                                <div className="flex items-center justify-between mb-3">
+                                    <span className="text-xs font-bold text-zinc-300 tracking-wide">TOR UÇ NOKTALARI</span>
+                                    <span className="text-xs font-bold text-white font-mono">{stats.tor?.healthy || 0} / {stats.tor?.total || 0}</span>
+                                </div>
+                                <div className="space-y-2">
+                                    {(stats.tor?.proxies || []).map((p) => (
+                                        <div key={p.proxy} className="flex items-center justify-between text-[10px] font-mono" title={p.last_error || ''}>
+                                            <div className="flex items-center gap-2 min-w-0">
+                                                <div className={`w-1.5 h-1.5 rounded-full shrink-0 ${p.healthy ? 'bg-emerald-500' : 'bg-red-500'}`}></div>
+                                                <span className="text-zinc-400 truncate">{p.proxy.replace('socks5://', '')}</span>
+                                            </div>
+                                            <span className="text-zinc-500 shrink-0 ml-2">
+                                                {Math.round(p.avg_latency_ms)}ms · {p.failures}/{p.requests} · %{Math.round(p.error_rate * 100)}
+                                            </span>
+                                        </div>
+                                    ))}
+                                </div>
+                            </div>
                         </div>
                     </div>
 
@@ -10,6 +10,7 @@ export default function HistoryPage() {
     const [loading, setLoading] = useState(true);
     const [selectedSite, setSelectedSite] = useState(null);
     const [details, setDetails] = useState(null);
+    const [snapshots, setSnapshots] = useState([]);
     const [detailLoading, setDetailLoading] = useState(false);
     const [selectedTag, setSelectedTag] = useState('ALL');
 
@@ -49,6 +50,8 @@ export default function HistoryPage() {
         try {
             const res = await api.get(`/history/${siteId}`);
             setDetails(res.data);
+            const snap = await api.get('/snapshots', { params: { stats_id: siteId } }).catch(() => null);
+            setSnapshots(snap?.data?.snapshots || []);
         } catch (error) {
             console.error("Detaylar alınamadı", error);
         } finally {
@@ -59,12 +62,57 @@ export default function HistoryPage() {
     const closeDetails = () => {
         setSelectedSite(null);
         setDetails(null);
+        setSnapshots([]);
     };
 
-    // İçerik formatlama fonksiyonu
-    const formatContent = (content) => {
+    // Ham sayfa token gerektirdiği için doğrudan bağlantı yerine blob olarak indirilir
+    const downloadSnapshot = async (snap) => {
+        try {
+            const res = await api.get(`/snapshots/${snap.id}/download`, { responseType: 'blob' });
+            const link = document.createElement('a');
+            link.href = URL.createObjectURL(res.data);
+            link.download = `${snap.hash}.html`;
+            link.click();
+            URL.revokeObjectURL(link.href);
+        } catch (error) {
+            console.error("Arşiv indirilemedi", error);
+        }
+    };
+
+    // İçerik formatlama fonksiyonu: keyword eşleşmeleri vurgulanır
+    // (konumlar karakter cinsindendir, bu yüzden metin Array.from ile bölünür)
+    const formatContent = (content, tags) => {
         if (!content) return <span className="text-zinc-600 italic">İçerik yok.</span>;
-        return <div className="text-zinc-300 whitespace-pre-wrap">{content}</div>;
+        const spans = (tags || []).flatMap(t => t.matches || []).sort((a, b) => a.start - b.start);
+        if (spans.length === 0) {
+            return <div className="text-zinc-300 whitespace-pre-wrap">{content}</div>;
+        }
+
+        const chars = Array.from(content);
+        const parts = [];
+        let pos = 0;
+        spans.forEach((span, i) => {
+            if (span.start < pos) return; // Çakışan eşleşmeler
+            parts.push(chars.slice(pos, span.start).join(''));
+            parts.push(<mark key={i} className="bg-amber-500/30 text-amber-200 rounded px-0.5">{chars.slice(span.start, span.end).join('')}</mark>);
+            pos = span.end;
+        });
+        parts.push(chars.slice(pos).join(''));
+        return <div className="text-zinc-300 whitespace-pre-wrap">{parts}</div>;
+    };
+
+    // Eşleşen keyword etiketleri ve puanları
+    const renderTags = (tags) => {
+        if (!tags || tags.length === 0) return null;
+        return (
+            <div className="flex flex-wrap gap-1">
+                {tags.map(t => (
+                    <span key={t.id} className="text-[10px] px-2 py-0.5 rounded-full border border-amber-500/30 bg-amber-500/10 text-amber-300">
+                        {t.word} · {t.category} · {t.score}
+                    </span>
+                ))}
+            </div>
+        );
     };
 
     return (
@@ -187,6 +235,11 @@ export default function HistoryPage() {
                                                     <Clock size={10} /> WATCHLIST
                                                 </span>
                                             )}
+                                            {item.source === 'watchlist' && !item.changed && (
+                                                <span className="text-[10px] px-2 py-1 rounded border border-zinc-700 bg-zinc-800/50 text-zinc-400 uppercase tracking-wide font-bold" title="Önceki taramaya göre değişiklik yok">
+                                                    DEĞİŞMEDİ
+                                                </span>
+                                            )}
                                             {item.category ? (
                                                 <span
                                                     className="text-[10px] px-2 py-1 rounded border uppercase tracking-wide font-bold transition-colors cursor-pointer hover:opacity-80"
@@ -284,6 +337,44 @@ export default function HistoryPage() {
                                     </div>
                                 ) : details && details.threads ? (
                                     <div className="divide-y divide-zinc-800">
+                                        {details.responses?.length > 0 && (
+                                            <div className="p-6 space-y-2">
+                                                <h3 className="text-[10px] font-bold text-zinc-500 uppercase tracking-widest">HTTP Üst Verisi</h3>
+                                                {details.responses.map((r) => (
+                                                    <details key={r.id} className="text-xs font-mono text-zinc-400">
+                                                        <summary className="cursor-pointer flex flex-wrap gap-x-3 gap-y-1">
+                                                            <span className={r.status_code >= 400 ? 'text-red-400' : 'text-emerald-400'}>{r.status_code}</span>
+                                                            <span className="text-zinc-300 break-all">{r.final_url}</span>
+                                                            {r.server && <span>{r.server}</span>}
+                                                            <span>{r.content_type}</span>
+                                                            <span>{(r.body_size / 1024).toFixed(1)} KB</span>
+                                                            <span>{Math.round(r.latency_ms)} ms</span>
+                                                            {r.redirects?.length > 0 && <span className="text-amber-400">{r.redirects.length} yönlendirme</span>}
+                                                        </summary>
+                                                        <div className="mt-2 ml-4 space-y-1 text-[11px]">
+                                                            {r.redirects?.map((h, i) => (
+                                                                <div key={i} className="text-amber-400/80">{h.status_code} {h.from} → {h.to}</div>
+                                                            ))}
+                                                            {Object.entries(r.headers || {}).map(([name, value]) => (
+                                                                <div key={name} className="break-all"><span className="text-zinc-500">{name}:</span> {value}</div>
+                                                            ))}
+                                                        </div>
+                                                    </details>
+                                                ))}
+                                            </div>
+                                        )}
+                                        {snapshots.length > 0 && (
+                                            <div className="p-6 space-y-2">
+                                                <h3 className="text-[10px] font-bold text-zinc-500 uppercase tracking-widest">Ham Sayfa Arşivi</h3>
+                                                {snapshots.map((s) => (
+                                                    <div key={s.id} className="flex items-center gap-3 text-xs font-mono text-zinc-400">
+                                                        <span className="text-zinc-300 break-all flex-1">{s.final_url}</span>
+                                                        <span title={s.hash}>{s.hash.slice(0, 12)}</span>
+                                                        <button onClick={() => downloadSnapshot(s)} className="text-blue-400 hover:text-white underline">İndir</button>
+                                                    </div>
+                                                ))}
+                                            </div>
+                                        )}
                                         {details.threads.map((thread) => (
                                             <div key={thread.id} className="p-6 hover:bg-zinc-900/50 transition-colors">
                                                 <div className="flex justify-between items-start mb-4">
@@ -300,6 +391,10 @@ export default function HistoryPage() {
                                                     </span>
                                                 </div>
 
+                                                {thread.tags && thread.tags.length > 0 && (
+                                                    <div className="mb-3">{renderTags(thread.tags)}</div>
+                                                )}
+
                                                 <div className="flex items-center gap-2 text-xs text-zinc-400 mb-4 font-mono">
                                                     <User size={12} /> <span className="text-zinc-300">{thread.author || "Bilinmiyor"}</span>
                                                     <span className="w-1 h-1 bg-zinc-700 rounded-full" />
@@ -337,8 +432,9 @@ export default function HistoryPage() {
 
                                                                     {/* Post Content */}
                                                                     <div className="text-zinc-300 text-sm leading-relaxed whitespace-pre-wrap pl-1 font-sans">
-                                                                        {formatContent(post.content)}
+                                                                        {formatContent(post.content, post.tags)}
                                                                     </div>
+                                                                    {renderTags(post.tags)}
                                                                 </div>
                                                             ))}
                                                         </div>
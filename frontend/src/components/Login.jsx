@@ -9,6 +9,8 @@ export default function Login({ onLoginSuccess }) {
     const [status, setStatus] = useState('idle'); // idle | loading | success | error
     const [username, setUsername] = useState('');
     const [password, setPassword] = useState('');
+    const [preAuthToken, setPreAuthToken] = useState(''); // Şifre doğrulandı, TOTP kodu bekleniyor
+    const [otpCode, setOtpCode] = useState('');
     const [statusMessage, setStatusMessage] = useState("AWAITING_CREDENTIALS");
 
     // Daktilo efekti için yardımcı fonksiyon
@@ -32,15 +34,38 @@ export default function Login({ onLoginSuccess }) {
         await new Promise(r => setTimeout(r, 2000));
 
         try {
-            const res = await api.post('/login', { username, password });
+            // İkinci adımda 6 haneli kod veya kurtarma kodu (xxxx-xxxx) gönderilir
+            const res = preAuthToken
+                ? await api.post('/login/2fa', /^\d{6}$/.test(otpCode.trim())
+                    ? { pre_auth_token: preAuthToken, code: otpCode.trim() }
+                    : { pre_auth_token: preAuthToken, recovery_code: otpCode.trim() })
+                : await api.post('/login', { username, password });
+            if (res.data.two_factor_required) {
+                setPreAuthToken(res.data.pre_auth_token);
+                setStatus('idle');
+                updateStatus("2FA_REQUIRED: ENTER_TOTP_CODE");
+                return;
+            }
             if (res.status === 200) {
                 setStatus('success');
                 updateStatus("AUTHENTICATION_SUCCESSFUL");
-                setTimeout(() => onLoginSuccess(res.data.token), 1500);
+                setTimeout(() => onLoginSuccess(res.data), 1500);
             }
         } catch (err) {
             setStatus('error');
-            updateStatus("ERROR: ACCESS_DENIED");
+            // Kaba kuvvet koruması: bekleme veya hesap kilidi
+            if (err.response?.status === 429) {
+                updateStatus(err.response.data.locked
+                    ? "ERROR: ACCOUNT_LOCKED"
+                    : `ERROR: TOO_MANY_ATTEMPTS (RETRY_IN_${err.response.data.retry_after}S)`);
+            } else {
+                updateStatus("ERROR: ACCESS_DENIED");
+            }
+            setOtpCode('');
+            // Ara token'ın süresi dolduysa şifre adımına dönülür
+            if (err.response?.data?.pre_auth_expired) {
+                setPreAuthToken('');
+            }
 
             setTimeout(() => {
                 setStatus('idle');
@@ -118,6 +143,26 @@ export default function Login({ onLoginSuccess }) {
 
                         <form onSubmit={handleLogin} className="space-y-6">
 
+                            {preAuthToken ? (
+                            /* Input: TOTP kodu */
+                            <div className="group space-y-2">
+                                <label className="text-xs uppercase tracking-widest text-zinc-500 group-focus-within:text-white transition-colors font-bold ml-1">DOĞRULAMA KODU</label>
+                                <div className="login-input-wrapper">
+                                    <div className="login-input-icon-box">
+                                        <ShieldCheck size={14} />
+                                    </div>
+                                    <input
+                                        type="text"
+                                        value={otpCode}
+                                        onChange={(e) => setOtpCode(e.target.value)}
+                                        className="login-input"
+                                        placeholder="123456 veya kurtarma kodu"
+                                        autoComplete="one-time-code"
+                                        autoFocus
+                                    />
+                                </div>
+                            </div>
+                            ) : (<>
                             {/* Input: username */}
                             <div className="group space-y-2">
                                 <label className="text-xs uppercase tracking-widest text-zinc-500 group-focus-within:text-white transition-colors font-bold ml-1">KULLANICI ID</label>
@@ -152,6 +197,7 @@ export default function Login({ onLoginSuccess }) {
                                     />
                                 </div>
                             </div>
+                            </>)}
 
                             {/*buton*/}
                             <div className="pt-2">
@@ -1,7 +1,7 @@
 "use client";
 
 import { useState, useEffect } from 'react';
-import api from '../utils/api';
+import api, { streamEvents } from '../utils/api';
 import { FileText, RefreshCw, AlertTriangle, Info, Clock, Search, Filter, CheckCircle, AlertCircle, Download } from 'lucide-react';
 import { motion } from 'framer-motion';
 
@@ -17,6 +17,26 @@ export default function LogsPage() {
         fetchStats();
     }, []);
 
+    // Yeni loglar canlı akıştan gelir
+    useEffect(() => {
+        const controller = new AbortController();
+        const levelKeys = { INFO: 'info', WARN: 'warn', ERROR: 'error', SUCCESS: 'success' };
+
+        streamEvents('/logs/stream', (event) => {
+            if (event.type !== 'log') return;
+            const log = event.data;
+            setLogs((prev) => (prev.some((l) => l.id === log.id) ? prev : [log, ...prev].slice(0, 1000)));
+            setStats((prev) => {
+                const key = levelKeys[log.level];
+                return key ? { ...prev, total: prev.total + 1, [key]: prev[key] + 1 } : { ...prev, total: prev.total + 1 };
+            });
+        }, controller.signal).catch((error) => {
+            if (error.name !== 'AbortError') console.error("Canlı log akışı kesildi", error);
+        });
+
+        return () => controller.abort();
+    }, []);
+
     const fetchStats = async () => {
         try {
             const res = await api.get('/logs/stats');
@@ -0,0 +1,69 @@
+"use client";
+
+import { useState } from 'react';
+import api from '../utils/api';
+import { Lock, LogOut } from 'lucide-react';
+
+// İlk girişte (varsayılan veya yönetici tarafından atanmış şifreyle) şifre değiştirme ekranı
+export default function PasswordChange({ onChanged, onLogout }) {
+    const [form, setForm] = useState({ current_password: '', new_password: '', confirm: '' });
+    const [error, setError] = useState('');
+    const [loading, setLoading] = useState(false);
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+        if (form.new_password !== form.confirm) {
+            setError('Yeni şifreler eşleşmiyor');
+            return;
+        }
+        setLoading(true);
+        try {
+            const res = await api.put('/account/password', {
+                current_password: form.current_password,
+                new_password: form.new_password,
+            });
+            onChanged(res.data);
+        } catch (err) {
+            setError(err.response?.data?.error || 'Şifre değiştirilemedi');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const input = (key, placeholder) => (
+        <input
+            type="password"
+            value={form[key]}
+            onChange={e => setForm({ ...form, [key]: e.target.value })}
+            className="w-full bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500 placeholder-zinc-600"
+            placeholder={placeholder}
+        />
+    );
+
+    return (
+        <div className="min-h-screen flex items-center justify-center font-mono">
+            <form onSubmit={handleSubmit} className="w-full max-w-sm glass-panel p-8 space-y-4 shadow-2xl">
+                <h2 className="text-sm font-bold text-white uppercase tracking-widest flex items-center gap-2">
+                    <Lock size={16} /> ŞİFRE DEĞİŞİKLİĞİ GEREKLİ
+                </h2>
+                <p className="text-xs text-zinc-400">Devam etmeden önce geçici şifrenizi değiştirmelisiniz.</p>
+                {input('current_password', 'Mevcut şifre')}
+                {input('new_password', 'Yeni şifre (en az 8 karakter)')}
+                {input('confirm', 'Yeni şifre (tekrar)')}
+                {error && <div className="text-xs text-red-400">{error}</div>}
+                <div className="flex gap-2">
+                    <button
+                        type="submit"
+                        disabled={loading || !form.current_password || !form.new_password}
+                        className="flex-1 h-[34px] bg-white text-black text-xs font-bold rounded hover:bg-emerald-400 transition-colors disabled:opacity-50"
+                    >
+                        ŞİFREYİ DEĞİŞTİR
+                    </button>
+                    <button type="button" onClick={onLogout} className="h-[34px] px-3 text-zinc-500 hover:text-red-500 text-xs flex items-center gap-1">
+                        <LogOut size={14} /> ÇIKIŞ
+                    </button>
+                </div>
+            </form>
+        </div>
+    );
+}
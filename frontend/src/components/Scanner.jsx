@@ -1,7 +1,7 @@
 "use client";
 
 import { useState, useEffect } from 'react';
-import api from '../utils/api';
+import api, { streamEvents } from '../utils/api';
 import { Loader2, AlertTriangle, Terminal, Shield, Database, ArrowRight, ScanEye } from 'lucide-react';
 import { motion } from 'framer-motion';
 
@@ -11,6 +11,10 @@ export default function Scanner({ onScanComplete, onChangeTab }) {
     const [error, setError] = useState(null);
     const [success, setSuccess] = useState(null);
     const [torStatus, setTorStatus] = useState('KONTROL EDİLİYOR...');
+    const [jobId, setJobId] = useState(null);
+    const [events, setEvents] = useState([]); // Canlı tarama olayları
+    const [routes, setRoutes] = useState([]); // Seçilebilecek bağlantı yolları
+    const [route, setRoute] = useState(''); // Boşsa host kuralları
 
     useEffect(() => {
         const checkTor = async () => {
@@ -26,8 +30,48 @@ export default function Scanner({ onScanComplete, onChangeTab }) {
             }
         };
         checkTor();
+        api.get('/system/routes').then(res => setRoutes(res.data.routes || [])).catch(() => setRoutes([]));
     }, []);
 
+    const isFinished = (status) => ['succeeded', 'failed', 'cancelled'].includes(status);
+
+    // İş olaylarını canlı akıştan izle; akış kesilirse yoklamaya dön
+    const waitForJob = async (id) => {
+        let finalJob = null;
+        try {
+            await streamEvents(`/scan/jobs/${id}/events`, (event) => {
+                if (event.type === 'scan') {
+                    setEvents((prev) => [...prev.slice(-5), event.data]);
+                } else if (event.type === 'status' && isFinished(event.data.status)) {
+                    finalJob = event.data;
+                }
+            });
+        } catch (err) {
+            console.error(err);
+        }
+        return finalJob || pollJob(id);
+    };
+
+    // İş bitene kadar durumu yokla
+    const pollJob = async (id) => {
+        while (true) {
+            const res = await api.get(`/scan/jobs/${id}`);
+            if (isFinished(res.data.status)) {
+                return res.data;
+            }
+            await new Promise((resolve) => setTimeout(resolve, 1500));
+        }
+    };
+
+    const handleCancel = async () => {
+        if (!jobId) return;
+        try {
+            await api.delete(`/scan/jobs/${jobId}`);
+        } catch (err) {
+            console.error(err);
+        }
+    };
+
     const handleScan = async (e) => {
         e.preventDefault();
         if (!url) return;
@@ -35,28 +79,40 @@ export default function Scanner({ onScanComplete, onChangeTab }) {
         setLoading(true);
         setError(null);
         setSuccess(null);
+        setEvents([]);
 
         try {
             const randomUA = localStorage.getItem('settings_randomUA') === 'true';
-            const res = await api.post('/scan', { url, random_ua: randomUA });
+            const res = await api.post('/scan', { url, random_ua: randomUA, route });
+            setJobId(res.data.job_id);
 
-            if (res.data.saved) {
-                const category = res.data.data.threads && res.data.data.threads.length > 0 ? res.data.data.threads[0].category : 'Belirsiz';
+            const job = await waitForJob(res.data.job_id);
+
+            if (job.status === 'succeeded' && job.saved) {
+                const data = job.result;
+                const category = data.threads && data.threads.length > 0 ? data.threads[0].category : 'Belirsiz';
                 let successMsg = `Hedef başarıyla analiz edildi [${category}] ve veritabanına işlendi.`;
-                if (randomUA && res.data.data.user_agent) {
-                    successMsg += ` (UA: ${res.data.data.user_agent})`;
+                if (randomUA && data.user_agent) {
+                    successMsg += ` (UA: ${data.user_agent})`;
                 }
                 setSuccess(successMsg);
-                onScanComplete(res.data.data);
+                onScanComplete(data);
                 setUrl(''); // Inputu temizle
+            } else if (job.error_code === 'not_forum') {
+                setError("Site forum değil. Veri kaydedilmedi.");
+            } else if (job.status === 'cancelled') {
+                setError("Tarama iptal edildi.");
             } else {
-                setError(res.data.message || "İlgili veri bulunamadı.");
+                // Hata kodu (timeout, http_status, captcha...) mesajın başında gösterilir
+                const message = job.error || "İlgili veri bulunamadı.";
+                setError(job.error_code ? `[${job.error_code}] ${message}` : message);
             }
         } catch (err) {
             console.error(err);
             setError(err.response?.data?.error || "Bağlantı reddedildi.");
         } finally {
             setLoading(false);
+            setJobId(null);
         }
     };
 
@@ -115,7 +171,26 @@ export default function Scanner({ onScanComplete, onChangeTab }) {
                             <div className="absolute inset-0 -z-10 bg-emerald-500/5 blur-xl opacity-0 group-focus-within:opacity-100 transition-opacity" />
                         </div>
 
-                        <div className="flex justify-end">
+                        <div className="flex justify-end gap-3">
+                            <select
+                                value={route}
+                                onChange={(e) => setRoute(e.target.value)}
+                                disabled={loading}
+                                className="bg-black/50 border border-white/10 text-zinc-300 text-xs font-mono px-3 uppercase tracking-widest focus:outline-none focus:border-emerald-500/50"
+                                title="Bağlantı yolu"
+                            >
+                                <option value="">OTOMATİK</option>
+                                {routes.map(r => <option key={r} value={r}>{r}</option>)}
+                            </select>
+                            {loading && jobId && (
+                                <button
+                                    type="button"
+                                    onClick={handleCancel}
+                                    className="px-6 py-3 border border-red-500/30 text-red-500 text-xs font-bold uppercase tracking-widest hover:bg-red-500/10 transition-colors"
+                                >
+                                    İPTAL
+                                </button>
+                            )}
                             <button
                                 type="submit"
                                 disabled={loading}
@@ -187,6 +262,15 @@ export default function Scanner({ onScanComplete, onChangeTab }) {
                                 </span></span>
                                 <span className="animate-pulse">Veri Paketleri Bekleniyor...</span>
                             </div>
+                            {events.length > 0 && (
+                                <div className="pt-2 space-y-1 text-[10px] text-zinc-500">
+                                    {events.map((event, i) => (
+                                        <div key={i} className="truncate">
+                                            <span className="text-emerald-500/70">[{event.type.toUpperCase()}]</span> {event.message}
+                                        </div>
+                                    ))}
+                                </div>
+                            )}
                         </div>
                     )}
                 </div>
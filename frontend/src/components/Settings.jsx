@@ -1,22 +1,98 @@
 "use client";
 
 import { useState, useEffect } from 'react';
-import api from '../utils/api';
+import api, { streamEvents } from '../utils/api';
 import { Settings, Server, Database, Eye, Lock, Edit2, Trash2, Plus, X, Check, Tag, RefreshCw, Layers, Clock } from 'lucide-react';
 import { motion, AnimatePresence } from 'framer-motion';
 
+const emptyKeyword = { word: '', category: '', color: '#3b82f6', mode: 'word', case_sensitive: false, turkish_case: true, weight: 1 };
+
+const matchModes = [
+    { id: 'word', label: 'Tam Kelime' },
+    { id: 'contains', label: 'İçerir' },
+    { id: 'regex', label: 'Regex' },
+];
+
+// Eşleştirme modu, harf duyarlılığı ve ağırlık ayarları (ekleme ve düzenleme formunda ortak)
+function MatchOptions({ value, onChange }) {
+    return (
+        <div className="flex gap-2 items-center text-[11px] text-zinc-400">
+            <select
+                value={value.mode}
+                onChange={e => onChange({ ...value, mode: e.target.value })}
+                className="bg-white/5 border border-white/10 rounded px-2 py-1 text-xs text-white"
+            >
+                {matchModes.map(m => <option key={m.id} value={m.id} className="bg-zinc-900">{m.label}</option>)}
+            </select>
+            <label className="flex items-center gap-1 cursor-pointer">
+                <input type="checkbox" checked={value.case_sensitive} onChange={e => onChange({ ...value, case_sensitive: e.target.checked })} />
+                Harf duyarlı
+            </label>
+            <label className="flex items-center gap-1 cursor-pointer">
+                <input type="checkbox" checked={value.turkish_case} onChange={e => onChange({ ...value, turkish_case: e.target.checked })} />
+                Türkçe (İ/ı)
+            </label>
+            <label className="flex items-center gap-1">
+                Ağırlık
+                <input
+                    type="number"
+                    min="0"
+                    step="0.5"
+                    value={value.weight}
+                    onChange={e => onChange({ ...value, weight: parseFloat(e.target.value) || 0 })}
+                    className="bg-white/5 border border-white/10 rounded px-2 py-1 text-xs text-white w-16"
+                />
+            </label>
+        </div>
+    );
+}
+
 function KeywordManager({ refreshTrigger }) {
     const [keywords, setKeywords] = useState([]);
     const [loading, setLoading] = useState(true);
-    const [newKeyword, setNewKeyword] = useState({ word: '', category: '', color: '#3b82f6' });
+    const [newKeyword, setNewKeyword] = useState(emptyKeyword);
     const [editingId, setEditingId] = useState(null);
-    const [editForm, setEditForm] = useState({ word: '', category: '', color: '' });
+    const [editForm, setEditForm] = useState(emptyKeyword);
+    const [error, setError] = useState('');
+    const [retagJob, setRetagJob] = useState(null); // Son yeniden etiketleme işi
 
     // Verileri Çek
     useEffect(() => {
         fetchKeywords();
     }, [refreshTrigger]);
 
+    // Yeniden etiketleme ilerlemesini canlı izle
+    const watchRetag = async (job) => {
+        setRetagJob(job);
+        if (!job || job.status === 'succeeded' || job.status === 'failed') return;
+        try {
+            await streamEvents(`/settings/retag-jobs/${job.id}/events`, (event) => {
+                if (event.type === 'status') setRetagJob(event.data);
+            });
+        } catch (err) {
+            console.error("Yeniden etiketleme akışı", err);
+        }
+    };
+
+    // Keyword değişikliği sunucuda otomatik iş başlatır; son işi getirip izle
+    const watchLatestRetag = async () => {
+        try {
+            const res = await api.get('/settings/retag-jobs');
+            if (res.data.length > 0) watchRetag(res.data[0]);
+        } catch (error) {
+            console.error("Yeniden etiketleme işleri yüklenemedi", error);
+        }
+    };
+
+    const handleRetag = async () => {
+        try {
+            const res = await api.post('/settings/keywords/retag');
+            watchRetag(res.data);
+        } catch (error) {
+            setError(error.response?.data?.error || 'Yeniden etiketleme başlatılamadı');
+        }
+    };
+
     const fetchKeywords = async () => {
         try {
             const res = await api.get('/settings/keywords');
@@ -32,10 +108,13 @@ function KeywordManager({ refreshTrigger }) {
         if (!newKeyword.word || !newKeyword.category) return;
         try {
             await api.post('/settings/keywords', newKeyword);
-            setNewKeyword({ word: '', category: '', color: '#3b82f6' });
+            setNewKeyword(emptyKeyword);
+            setError('');
             fetchKeywords();
+            watchLatestRetag();
         } catch (error) {
             console.error("Ekleme hatası", error);
+            setError(error.response?.data?.error || 'Keyword eklenemedi');
         }
     };
 
@@ -43,6 +122,7 @@ function KeywordManager({ refreshTrigger }) {
         try {
             await api.delete(`/settings/keywords/${id}`);
             fetchKeywords();
+            watchLatestRetag();
         } catch (error) {
             console.error("Silme hatası", error);
         }
@@ -50,21 +130,27 @@ function KeywordManager({ refreshTrigger }) {
 
     const startEdit = (k) => {
         setEditingId(k.id);
-        setEditForm({ word: k.word, category: k.category, color: k.color });
+        setEditForm({
+            word: k.word, category: k.category, color: k.color,
+            mode: k.mode || 'word', case_sensitive: k.case_sensitive, turkish_case: k.turkish_case, weight: k.weight || 1,
+        });
     };
 
     const cancelEdit = () => {
         setEditingId(null);
-        setEditForm({ word: '', category: '', color: '' });
+        setEditForm(emptyKeyword);
     };
 
     const saveEdit = async () => {
         try {
             await api.put(`/settings/keywords/${editingId}`, editForm);
             setEditingId(null);
+            setError('');
             fetchKeywords();
+            watchLatestRetag();
         } catch (error) {
             console.error("Güncelleme hatası", error);
+            setError(error.response?.data?.error || 'Keyword güncellenemedi');
         }
     };
 
@@ -75,9 +161,26 @@ function KeywordManager({ refreshTrigger }) {
             className="mb-8 glass-panel overflow-hidden shadow-2xl"
         >
             <div className="p-6 border-b border-white/5 bg-white/[0.02]">
-                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
-                    <Tag size={16} /> KATEGORİ & KEYWORD YÖNETİMİ
-                </h2>
+                <div className="flex items-center justify-between">
+                    <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                        <Tag size={16} /> KATEGORİ & KEYWORD YÖNETİMİ
+                    </h2>
+                    <button
+                        onClick={handleRetag}
+                        disabled={retagJob && (retagJob.status === 'queued' || retagJob.status === 'running')}
+                        className="text-[11px] font-bold text-zinc-400 hover:text-white flex items-center gap-1 disabled:opacity-50"
+                    >
+                        <RefreshCw size={12} /> YENİDEN ETİKETLE
+                    </button>
+                </div>
+                {retagJob && (
+                    <div className="mt-2 text-[11px] text-zinc-500 font-mono">
+                        {retagJob.status === 'queued' && 'Yeniden etiketleme sırada...'}
+                        {retagJob.status === 'running' && `Yeniden etiketleniyor: ${retagJob.processed_threads}/${retagJob.total_threads} konu`}
+                        {retagJob.status === 'succeeded' && `Son etiketleme: ${retagJob.processed_threads} konu, ${retagJob.processed_posts} ileti işlendi, ${retagJob.category_changed} konunun kategorisi değişti`}
+                        {retagJob.status === 'failed' && <span className="text-red-400">Yeniden etiketleme başarısız: {retagJob.error}</span>}
+                    </div>
+                )}
             </div>
 
             <div className="p-6">
@@ -92,7 +195,7 @@ function KeywordManager({ refreshTrigger }) {
                             <div key={k.id} className="flex items-center justify-between p-3 bg-white/5 rounded-lg border border-white/5 group hover:border-white/20 transition-all hover:bg-white/10">
                                 {editingId === k.id ? (
                                     // Düzenleme Modu
-                                    <div className="flex-1 flex gap-2 items-center">
+                                    <div className="flex-1 flex flex-wrap gap-2 items-center">
                                         <input
                                             value={editForm.word}
                                             onChange={e => setEditForm({ ...editForm, word: e.target.value })}
@@ -111,6 +214,7 @@ function KeywordManager({ refreshTrigger }) {
                                             onChange={e => setEditForm({ ...editForm, color: e.target.value })}
                                             className="bg-transparent w-6 h-6 border-none cursor-pointer"
                                         />
+                                        <MatchOptions value={editForm} onChange={setEditForm} />
                                         <button onClick={saveEdit} className="p-1 hover:text-emerald-500 text-zinc-400"><Check size={14} /></button>
                                         <button onClick={cancelEdit} className="p-1 hover:text-red-500 text-zinc-400"><X size={14} /></button>
                                     </div>
@@ -121,7 +225,13 @@ function KeywordManager({ refreshTrigger }) {
                                             <div className="w-3 h-3 rounded-full" style={{ backgroundColor: k.color }} />
                                             <div>
                                                 <div className="text-sm font-bold text-white tracking-wide">{k.word}</div>
-                                                <div className="text-[11px] text-zinc-400 uppercase tracking-widest font-medium">{k.category}</div>
+                                                <div className="text-[11px] text-zinc-400 uppercase tracking-widest font-medium">
+                                                    {k.category}
+                                                    <span className="ml-2 normal-case tracking-normal text-zinc-500">
+                                                        {matchModes.find(m => m.id === k.mode)?.label || k.mode} · x{k.weight}
+                                                        {k.case_sensitive && ' · Aa'}
+                                                    </span>
+                                                </div>
                                             </div>
                                         </div>
                                         <div className="flex items-center gap-2 opacity-0 group-hover:opacity-100 transition-opacity">
@@ -178,6 +288,10 @@ function KeywordManager({ refreshTrigger }) {
                         <Plus size={14} /> EKLE
                     </button>
                 </div>
+                <div className="mt-2">
+                    <MatchOptions value={newKeyword} onChange={setNewKeyword} />
+                </div>
+                {error && <div className="mt-2 text-xs text-red-400">{error}</div>}
             </div>
         </motion.div>
     );
@@ -324,14 +438,20 @@ function UserAgentManager({ refreshTrigger }) {
 function WatchlistManager({ refreshTrigger }) {
     const [watchlist, setWatchlist] = useState([]);
     const [loading, setLoading] = useState(true);
-    const [newWatch, setNewWatch] = useState({ url: '', interval: '60', description: '' });
+    const [newWatch, setNewWatch] = useState({ url: '', interval: '60', description: '', route: '' });
     const [editingId, setEditingId] = useState(null);
-    const [editForm, setEditForm] = useState({ url: '', interval: '', description: '' });
+    const [editForm, setEditForm] = useState({ url: '', interval: '', description: '', route: '' });
+    const [routes, setRoutes] = useState([]);
 
     useEffect(() => {
         fetchWatchlist();
     }, [refreshTrigger]);
 
+    useEffect(() => {
+        // Seçilebilecek bağlantı yolları (direct, tor, i2p ve tanımlı proxy'ler)
+        api.get('/system/routes').then(res => setRoutes(res.data.routes || [])).catch(() => setRoutes([]));
+    }, []);
+
     const fetchWatchlist = async () => {
         try {
             const res = await api.get('/settings/watchlist');
@@ -349,9 +469,10 @@ function WatchlistManager({ refreshTrigger }) {
             await api.post('/settings/watchlist', {
                 url: newWatch.url,
                 interval_minutes: parseInt(newWatch.interval),
-                description: newWatch.description
+                description: newWatch.description,
+                route: newWatch.route
             });
-            setNewWatch({ url: '', interval: '60', description: '' });
+            setNewWatch({ url: '', interval: '60', description: '', route: '' });
             fetchWatchlist();
         } catch (error) {
             console.error("Ekleme hatası", error);
@@ -369,12 +490,12 @@ function WatchlistManager({ refreshTrigger }) {
 
     const startEdit = (w) => {
         setEditingId(w.id);
-        setEditForm({ url: w.url, interval: w.interval_minutes.toString(), description: w.description || '' });
+        setEditForm({ url: w.url, interval: w.interval_minutes.toString(), description: w.description || '', route: w.route || '', retry: w.retry });
     };
 
     const cancelEdit = () => {
         setEditingId(null);
-        setEditForm({ url: '', interval: '', description: '' });
+        setEditForm({ url: '', interval: '', description: '', route: '' });
     };
 
     const saveEdit = async () => {
@@ -382,7 +503,9 @@ function WatchlistManager({ refreshTrigger }) {
             await api.put(`/settings/watchlist/${editingId}`, {
                 url: editForm.url,
                 interval_minutes: parseInt(editForm.interval),
-                description: editForm.description
+                description: editForm.description,
+                route: editForm.route,
+                retry: editForm.retry // Öğeye özel yeniden deneme ayarları API'den yönetilir, düzenlemede korunur
             });
             setEditingId(null);
             fetchWatchlist();
@@ -443,6 +566,14 @@ function WatchlistManager({ refreshTrigger }) {
                                             className="bg-white/5 border border-zinc-600 rounded px-2 py-1 text-xs text-white flex-1 min-w-[150px]"
                                             placeholder="Açıklama (opsiyonel)"
                                         />
+                                        <select
+                                            value={editForm.route}
+                                            onChange={e => setEditForm({ ...editForm, route: e.target.value })}
+                                            className="bg-white/5 border border-zinc-600 rounded px-2 py-1 text-xs text-white font-mono"
+                                        >
+                                            <option value="">otomatik</option>
+                                            {routes.map(r => <option key={r} value={r}>{r}</option>)}
+                                        </select>
                                         <button onClick={saveEdit} className="p-1 hover:text-emerald-500 text-zinc-400"><Check size={14} /></button>
                                         <button onClick={cancelEdit} className="p-1 hover:text-red-500 text-zinc-400"><X size={14} /></button>
                                     </div>
@@ -455,6 +586,19 @@ function WatchlistManager({ refreshTrigger }) {
                                                 <div className="text-[11px] text-emerald-400 uppercase tracking-widest font-medium flex items-center gap-1">
                                                     <Clock size={10} /> {formatInterval(w.interval_minutes)}
                                                 </div>
+                                                {w.failures > 0 && (
+                                                    <div className="text-[11px] text-red-400 uppercase tracking-widest font-medium" title={w.last_error}>
+                                                        {w.failures} HATA · {w.last_error_code}
+                                                    </div>
+                                                )}
+                                                {w.route && (
+                                                    <div className="text-[11px] text-blue-400 uppercase tracking-widest font-medium">{w.route}</div>
+                                                )}
+                                                {w.retry?.max_attempts > 0 && (
+                                                    <div className="text-[11px] text-amber-400 uppercase tracking-widest font-medium" title="Öğeye özel yeniden deneme politikası">
+                                                        {w.retry.max_attempts} DENEME
+                                                    </div>
+                                                )}
                                                 {w.description && (
                                                     <div className="text-[11px] text-zinc-500 italic">{w.description}</div>
                                                 )}
@@ -508,6 +652,17 @@ function WatchlistManager({ refreshTrigger }) {
                             placeholder="Örn: APT Grup Forumu"
                         />
                     </div>
+                    <div className="w-32 space-y-1">
+                        <label className="text-[11px] text-zinc-300 uppercase font-bold tracking-wider">Bağlantı</label>
+                        <select
+                            value={newWatch.route}
+                            onChange={(e) => setNewWatch({ ...newWatch, route: e.target.value })}
+                            className="w-full bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500 transition-colors font-mono"
+                        >
+                            <option value="">otomatik</option>
+                            {routes.map(r => <option key={r} value={r}>{r}</option>)}
+                        </select>
+                    </div>
                     <button
                         onClick={handleAdd}
                         disabled={!newWatch.url || !newWatch.interval}
@@ -521,7 +676,622 @@ function WatchlistManager({ refreshTrigger }) {
     );
 }
 
-export default function SettingsPage() {
+const roleLabels = { admin: 'ADMIN', analyst: 'ANALİST', viewer: 'İZLEYİCİ' };
+
+// Kullanıcı yönetimi (sadece admin)
+function UserManager({ refreshTrigger }) {
+    const [users, setUsers] = useState([]);
+    const [lockouts, setLockouts] = useState([]);
+    const [newUser, setNewUser] = useState({ username: '', password: '', role: 'viewer' });
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        fetchUsers();
+    }, [refreshTrigger]);
+
+    const fetchUsers = async () => {
+        try {
+            const [usersRes, lockoutsRes] = await Promise.all([api.get('/users'), api.get('/security/lockouts')]);
+            setUsers(usersRes.data);
+            setLockouts(lockoutsRes.data);
+        } catch (error) {
+            console.error("Kullanıcılar yüklenemedi", error);
+        }
+    };
+
+    // Kullanıcı adı sayaçları küçük harfle tutulur
+    const userLock = (u) => lockouts.find((l) => l.kind === 'user' && l.key === u.username.toLowerCase());
+
+    const run = async (request) => {
+        try {
+            await request();
+            setError('');
+            fetchUsers();
+        } catch (error) {
+            setError(error.response?.data?.error || 'İşlem başarısız');
+        }
+    };
+
+    const handleAdd = () => run(async () => {
+        await api.post('/users', newUser);
+        setNewUser({ username: '', password: '', role: 'viewer' });
+    });
+
+    const handleRole = (u, role) => run(() => api.put(`/users/${u.ID}`, { role }));
+
+    // Yeni şifre kullanıcıya iletilir, ilk girişte değiştirmesi istenir
+    const handleResetPassword = (u) => {
+        const password = window.prompt(`${u.username} için geçici şifre (en az 8 karakter):`);
+        if (password) run(() => api.put(`/users/${u.ID}`, { password }));
+    };
+
+    const handleUnlock = (u) => run(() => api.post(`/users/${u.ID}/unlock`));
+
+    const handleDeleteLockout = (l) => run(() => api.delete(`/security/lockouts/${l.id}`));
+
+    const handleResetTwoFactor = (u) => run(() => api.delete(`/users/${u.ID}/2fa`));
+
+    const handleRevokeSessions = (u) => run(() => api.post(`/users/${u.ID}/revoke-sessions`));
+
+    const handleDelete = (u) => run(() => api.delete(`/users/${u.ID}`));
+
+    return (
+        <motion.div
+            initial={{ opacity: 0, y: 20 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-8 glass-panel overflow-hidden shadow-2xl"
+        >
+            <div className="p-6 border-b border-white/5 bg-white/[0.02]">
+                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                    <Lock size={16} /> KULLANICI YÖNETİMİ
+                </h2>
+            </div>
+
+            <div className="p-6">
+                <div className="space-y-2 mb-6">
+                    {users.map((u) => (
+                        <div key={u.ID} className="flex items-center justify-between p-3 bg-white/5 rounded-lg border border-white/5 group hover:border-white/20 transition-all">
+                            <div>
+                                <div className="text-sm font-bold text-white tracking-wide">{u.username}</div>
+                                {u.must_change_password && <div className="text-[11px] text-amber-400">Şifre değişikliği bekleniyor</div>}
+                                {u.totp_enabled && (
+                                    <div className="text-[11px] text-emerald-400">
+                                        2FA açık
+                                        <button onClick={() => handleResetTwoFactor(u)} className="ml-2 underline text-zinc-400 hover:text-white">Sıfırla</button>
+                                    </div>
+                                )}
+                                {userLock(u) && (
+                                    <div className="text-[11px] text-red-400">
+                                        {userLock(u).locked ? 'Kilitli' : 'Beklemede'}: {new Date(userLock(u).blocked_until).toLocaleTimeString('tr-TR')} kadar
+                                        <button onClick={() => handleUnlock(u)} className="ml-2 underline hover:text-white">Kilidi kaldır</button>
+                                    </div>
+                                )}
+                            </div>
+                            <div className="flex items-center gap-2">
+                                <select
+                                    value={u.role}
+                                    onChange={e => handleRole(u, e.target.value)}
+                                    className="bg-white/5 border border-white/10 rounded px-2 py-1 text-xs text-white"
+                                >
+                                    {Object.entries(roleLabels).map(([id, label]) => <option key={id} value={id} className="bg-zinc-900">{label}</option>)}
+                                </select>
+                                <button onClick={() => handleResetPassword(u)} className="p-2 hover:bg-zinc-800 rounded text-zinc-400 hover:text-white transition-colors" title="Şifre sıfırla">
+                                    <Edit2 size={14} />
+                                </button>
+                                <button onClick={() => handleRevokeSessions(u)} className="p-2 hover:bg-zinc-800 rounded text-zinc-400 hover:text-amber-400 transition-colors" title="Tüm oturumları kapat">
+                                    <X size={14} />
+                                </button>
+                                <button onClick={() => handleDelete(u)} className="p-2 hover:bg-red-900/20 rounded text-zinc-600 hover:text-red-500 transition-colors">
+                                    <Trash2 size={14} />
+                                </button>
+                            </div>
+                        </div>
+                    ))}
+                </div>
+
+                {lockouts.some((l) => l.kind === 'ip') && (
+                    <div className="mb-6 space-y-1">
+                        <div className="text-[10px] text-zinc-500 uppercase tracking-widest font-bold">Engellenen IP Adresleri</div>
+                        {lockouts.filter((l) => l.kind === 'ip').map((l) => (
+                            <div key={l.id} className="flex items-center justify-between p-2 bg-red-500/5 rounded border border-red-500/20 text-xs font-mono">
+                                <span className="text-white">{l.key}</span>
+                                <span className="text-zinc-400">{l.failures} hatalı deneme, {new Date(l.blocked_until).toLocaleTimeString('tr-TR')} kadar</span>
+                                <button onClick={() => handleDeleteLockout(l)} className="p-1 hover:bg-zinc-800 rounded text-zinc-400 hover:text-white" title="Engeli kaldır">
+                                    <X size={12} />
+                                </button>
+                            </div>
+                        ))}
+                    </div>
+                )}
+
+                <div className="pt-4 border-t border-white/5 flex gap-2 items-end">
+                    <input
+                        value={newUser.username}
+                        onChange={e => setNewUser({ ...newUser, username: e.target.value })}
+                        className="flex-1 bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500"
+                        placeholder="Kullanıcı adı"
+                    />
+                    <input
+                        type="password"
+                        value={newUser.password}
+                        onChange={e => setNewUser({ ...newUser, password: e.target.value })}
+                        className="flex-1 bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500"
+                        placeholder="Geçici şifre"
+                    />
+                    <select
+                        value={newUser.role}
+                        onChange={e => setNewUser({ ...newUser, role: e.target.value })}
+                        className="bg-white/5 border border-white/10 rounded px-2 py-2 text-xs text-white"
+                    >
+                        {Object.entries(roleLabels).map(([id, label]) => <option key={id} value={id} className="bg-zinc-900">{label}</option>)}
+                    </select>
+                    <button
+                        onClick={handleAdd}
+                        disabled={!newUser.username || !newUser.password}
+                        className="h-[34px] px-4 bg-white text-black text-xs font-bold rounded hover:bg-emerald-400 transition-colors disabled:opacity-50 flex items-center gap-2"
+                    >
+                        <Plus size={14} /> EKLE
+                    </button>
+                </div>
+                {error && <div className="mt-2 text-xs text-red-400">{error}</div>}
+            </div>
+        </motion.div>
+    );
+}
+
+// İki adımlı doğrulama (TOTP) kurulumu ve kurtarma kodları
+function TwoFactorManager({ refreshTrigger }) {
+    const [status, setStatus] = useState({ enabled: false, recovery_codes_remaining: 0 });
+    const [enrollment, setEnrollment] = useState(null); // { secret, otpauth_uri }
+    const [code, setCode] = useState('');
+    const [password, setPassword] = useState('');
+    const [recoveryCodes, setRecoveryCodes] = useState([]);
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        fetchStatus();
+    }, [refreshTrigger]);
+
+    const fetchStatus = async () => {
+        try {
+            const res = await api.get('/account/2fa');
+            setStatus(res.data);
+        } catch (error) {
+            console.error("İki adımlı doğrulama durumu yüklenemedi", error);
+        }
+    };
+
+    const run = async (request) => {
+        try {
+            await request();
+            setError('');
+            setCode('');
+            setPassword('');
+            fetchStatus();
+        } catch (error) {
+            setError(error.response?.data?.error || 'İşlem başarısız');
+        }
+    };
+
+    const handleEnroll = () => run(async () => {
+        const res = await api.post('/account/2fa/enroll');
+        setEnrollment(res.data);
+        setRecoveryCodes([]);
+    });
+
+    const handleConfirm = () => run(async () => {
+        const res = await api.post('/account/2fa/verify', { code });
+        setEnrollment(null);
+        setRecoveryCodes(res.data.recovery_codes);
+    });
+
+    const handleRegenerate = () => run(async () => {
+        const res = await api.post('/account/2fa/recovery-codes', { code });
+        setRecoveryCodes(res.data.recovery_codes);
+    });
+
+    const handleDisable = () => run(async () => {
+        await api.post('/account/2fa/disable', { password, code });
+        setRecoveryCodes([]);
+    });
+
+    const inputClass = "bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500";
+    const buttonClass = "h-[34px] px-4 bg-white text-black text-xs font-bold rounded hover:bg-emerald-400 transition-colors disabled:opacity-50";
+
+    return (
+        <motion.div
+            initial={{ opacity: 0, y: 20 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-8 glass-panel overflow-hidden shadow-2xl"
+        >
+            <div className="p-6 border-b border-white/5 bg-white/[0.02] flex justify-between items-center">
+                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                    <Lock size={16} /> İKİ ADIMLI DOĞRULAMA
+                </h2>
+                <span className={`text-[10px] font-bold ${status.enabled ? 'text-emerald-400' : 'text-zinc-500'}`}>
+                    {status.enabled ? `AÇIK · ${status.recovery_codes_remaining} kurtarma kodu` : 'KAPALI'}
+                </span>
+            </div>
+
+            <div className="p-6 space-y-4">
+                {recoveryCodes.length > 0 && (
+                    <div className="p-3 bg-emerald-500/10 border border-emerald-500/30 rounded text-xs">
+                        <div className="text-emerald-400 font-bold mb-2">Kurtarma kodlarınızı güvenli bir yere kaydedin. Her kod bir kez kullanılabilir ve bir daha gösterilmeyecek.</div>
+                        <div className="grid grid-cols-2 md:grid-cols-5 gap-2 font-mono text-white">
+                            {recoveryCodes.map((c) => <span key={c}>{c}</span>)}
+                        </div>
+                    </div>
+                )}
+
+                {!status.enabled && !enrollment && (
+                    <button onClick={handleEnroll} className={buttonClass}>KURULUMU BAŞLAT</button>
+                )}
+
+                {!status.enabled && enrollment && (
+                    <div className="space-y-3 text-xs">
+                        <div className="text-zinc-400">Doğrulayıcı uygulamanıza aşağıdaki anahtarı veya adresi ekleyin, ardından üretilen kodu girin.</div>
+                        <div className="p-2 bg-black/40 rounded font-mono text-white break-all">{enrollment.secret}</div>
+                        <a href={enrollment.otpauth_uri} className="block text-blue-400 underline break-all">{enrollment.otpauth_uri}</a>
+                        <div className="flex gap-2">
+                            <input value={code} onChange={e => setCode(e.target.value)} className={`flex-1 ${inputClass}`} placeholder="6 haneli kod" />
+                            <button onClick={handleConfirm} disabled={!code} className={buttonClass}>ETKİNLEŞTİR</button>
+                        </div>
+                    </div>
+                )}
+
+                {status.enabled && (
+                    <div className="flex gap-2 items-end">
+                        <input value={code} onChange={e => setCode(e.target.value)} className={`flex-1 ${inputClass}`} placeholder="6 haneli kod" />
+                        <button onClick={handleRegenerate} disabled={!code} className={buttonClass}>KODLARI YENİLE</button>
+                        <input type="password" value={password} onChange={e => setPassword(e.target.value)} className={`flex-1 ${inputClass}`} placeholder="Şifre (kapatmak için)" />
+                        <button onClick={handleDisable} disabled={!code || !password} className="h-[34px] px-4 bg-red-500/20 text-red-400 border border-red-500/30 text-xs font-bold rounded hover:bg-red-500/30 transition-colors disabled:opacity-50">KAPAT</button>
+                    </div>
+                )}
+                {error && <div className="text-xs text-red-400">{error}</div>}
+            </div>
+        </motion.div>
+    );
+}
+
+const apiKeyScopes = [
+    { id: 'read', label: 'Sadece Okuma', role: 'viewer' },
+    { id: 'scan', label: 'Tarama', role: 'analyst' },
+    { id: 'admin', label: 'Yönetici', role: 'admin' },
+];
+
+const roleRank = { viewer: 1, analyst: 2, admin: 3 };
+
+// Kişisel API anahtarları (otomasyon istemcileri için)
+function APIKeyManager({ refreshTrigger, role }) {
+    const [keys, setKeys] = useState([]);
+    const [newKey, setNewKey] = useState({ name: '', scope: 'read', expires_at: '' });
+    const [createdKey, setCreatedKey] = useState('');
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        fetchKeys();
+    }, [refreshTrigger]);
+
+    const fetchKeys = async () => {
+        try {
+            const res = await api.get('/account/api-keys');
+            setKeys(res.data);
+        } catch (error) {
+            console.error("API anahtarları yüklenemedi", error);
+        }
+    };
+
+    const handleCreate = async () => {
+        try {
+            const payload = { name: newKey.name, scope: newKey.scope };
+            if (newKey.expires_at) payload.expires_at = new Date(newKey.expires_at).toISOString();
+            const res = await api.post('/account/api-keys', payload);
+            setCreatedKey(res.data.key);
+            setNewKey({ name: '', scope: 'read', expires_at: '' });
+            setError('');
+            fetchKeys();
+        } catch (error) {
+            setError(error.response?.data?.error || 'Anahtar oluşturulamadı');
+        }
+    };
+
+    const handleRevoke = async (key) => {
+        try {
+            await api.delete(`/account/api-keys/${key.id}`);
+            fetchKeys();
+        } catch (error) {
+            setError(error.response?.data?.error || 'Anahtar iptal edilemedi');
+        }
+    };
+
+    const keyState = (key) => {
+        if (key.revoked_at) return { label: 'İptal edildi', color: 'text-zinc-500' };
+        if (key.expires_at && new Date(key.expires_at) < new Date()) return { label: 'Süresi doldu', color: 'text-zinc-500' };
+        return { label: 'Aktif', color: 'text-emerald-400' };
+    };
+
+    return (
+        <motion.div
+            initial={{ opacity: 0, y: 20 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-8 glass-panel overflow-hidden shadow-2xl"
+        >
+            <div className="p-6 border-b border-white/5 bg-white/[0.02]">
+                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                    <Lock size={16} /> API ANAHTARLARI
+                </h2>
+            </div>
+
+            <div className="p-6">
+                {createdKey && (
+                    <div className="mb-4 p-3 bg-emerald-500/10 border border-emerald-500/30 rounded text-xs">
+                        <div className="text-emerald-400 font-bold mb-1">Anahtar oluşturuldu. Bir daha gösterilmeyecek, şimdi kopyalayın:</div>
+                        <div className="flex items-center gap-2">
+                            <code className="flex-1 font-mono text-white break-all">{createdKey}</code>
+                            <button onClick={() => setCreatedKey('')} className="p-1 hover:bg-zinc-800 rounded text-zinc-400 hover:text-white"><X size={12} /></button>
+                        </div>
+                    </div>
+                )}
+
+                <div className="space-y-2 mb-6">
+                    {keys.map((key) => {
+                        const state = keyState(key);
+                        return (
+                            <div key={key.id} className="flex items-center justify-between p-3 bg-white/5 rounded-lg border border-white/5 text-xs">
+                                <div>
+                                    <div className="text-sm font-bold text-white tracking-wide">{key.name} <span className="font-mono text-zinc-500">{key.prefix}…</span></div>
+                                    <div className="text-zinc-500">
+                                        {apiKeyScopes.find((s) => s.id === key.scope)?.label} · <span className={state.color}>{state.label}</span>
+                                        {key.expires_at && ` · Bitiş: ${new Date(key.expires_at).toLocaleDateString('tr-TR')}`}
+                                        {' · Son kullanım: '}{key.last_used_at ? `${new Date(key.last_used_at).toLocaleString('tr-TR')} (${key.last_used_ip})` : '-'}
+                                    </div>
+                                </div>
+                                {!key.revoked_at && (
+                                    <button onClick={() => handleRevoke(key)} className="p-2 hover:bg-red-900/20 rounded text-zinc-600 hover:text-red-500 transition-colors" title="İptal et">
+                                        <Trash2 size={14} />
+                                    </button>
+                                )}
+                            </div>
+                        );
+                    })}
+                </div>
+
+                <div className="pt-4 border-t border-white/5 flex gap-2 items-end">
+                    <input
+                        value={newKey.name}
+                        onChange={e => setNewKey({ ...newKey, name: e.target.value })}
+                        className="flex-1 bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500"
+                        placeholder="Anahtar adı (örn: CI pipeline)"
+                    />
+                    <select
+                        value={newKey.scope}
+                        onChange={e => setNewKey({ ...newKey, scope: e.target.value })}
+                        className="bg-white/5 border border-white/10 rounded px-2 py-2 text-xs text-white"
+                    >
+                        {apiKeyScopes.filter((s) => roleRank[role] >= roleRank[s.role]).map((s) => <option key={s.id} value={s.id} className="bg-zinc-900">{s.label}</option>)}
+                    </select>
+                    <input
+                        type="date"
+                        value={newKey.expires_at}
+                        onChange={e => setNewKey({ ...newKey, expires_at: e.target.value })}
+                        className="bg-white/5 border border-white/10 rounded px-2 py-2 text-xs text-white"
+                        title="Bitiş tarihi (boş: süresiz)"
+                    />
+                    <button
+                        onClick={handleCreate}
+                        disabled={!newKey.name}
+                        className="h-[34px] px-4 bg-white text-black text-xs font-bold rounded hover:bg-emerald-400 transition-colors disabled:opacity-50 flex items-center gap-2"
+                    >
+                        <Plus size={14} /> OLUŞTUR
+                    </button>
+                </div>
+                {error && <div className="mt-2 text-xs text-red-400">{error}</div>}
+            </div>
+        </motion.div>
+    );
+}
+
+// Denetim kaydı: kim, ne zaman, neyi değiştirdi (sadece admin)
+function AuditTrail({ refreshTrigger }) {
+    const [entries, setEntries] = useState([]);
+    const [total, setTotal] = useState(0);
+    const [filters, setFilters] = useState({ username: '', action: '', entity_type: '' });
+    const [expanded, setExpanded] = useState(null);
+
+    useEffect(() => {
+        fetchAudit();
+    }, [refreshTrigger]);
+
+    const fetchAudit = async () => {
+        try {
+            const params = Object.fromEntries(Object.entries(filters).filter(([, v]) => v));
+            const res = await api.get('/audit', { params: { ...params, limit: 100 } });
+            setEntries(res.data.results);
+            setTotal(res.data.total);
+        } catch (error) {
+            console.error("Denetim kayıtları yüklenemedi", error);
+        }
+    };
+
+    return (
+        <motion.div
+            initial={{ opacity: 0, y: 20 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-8 glass-panel overflow-hidden shadow-2xl"
+        >
+            <div className="p-6 border-b border-white/5 bg-white/[0.02] flex justify-between items-center">
+                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                    <Eye size={16} /> DENETİM KAYDI
+                </h2>
+                <span className="text-[10px] text-zinc-500 font-mono">{total} kayıt</span>
+            </div>
+
+            <div className="p-6">
+                <div className="flex gap-2 mb-4">
+                    {[['username', 'Kullanıcı'], ['action', 'İşlem (örn: keyword.update)'], ['entity_type', 'Nesne türü']].map(([key, label]) => (
+                        <input
+                            key={key}
+                            value={filters[key]}
+                            onChange={e => setFilters({ ...filters, [key]: e.target.value })}
+                            onKeyDown={e => e.key === 'Enter' && fetchAudit()}
+                            className="flex-1 bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500"
+                            placeholder={label}
+                        />
+                    ))}
+                    <button onClick={fetchAudit} className="px-3 bg-zinc-900 hover:bg-zinc-800 border border-zinc-800 rounded text-zinc-400 hover:text-white transition-colors">
+                        <RefreshCw size={14} />
+                    </button>
+                </div>
+
+                <div className="space-y-1 max-h-[400px] overflow-y-auto">
+                    {entries.map((entry) => (
+                        <div key={entry.id} className="p-2 bg-white/5 rounded border border-white/5 text-xs font-mono">
+                            <button onClick={() => setExpanded(expanded === entry.id ? null : entry.id)} className="w-full flex gap-3 text-left">
+                                <span className="text-zinc-500 whitespace-nowrap">{new Date(entry.created_at).toLocaleString('tr-TR')}</span>
+                                <span className="text-white font-bold">{entry.username || '-'}</span>
+                                <span className="text-emerald-400">{entry.action}</span>
+                                <span className="text-zinc-400">{entry.entity_type}{entry.entity_id && ` #${entry.entity_id}`}</span>
+                                <span className="ml-auto text-zinc-600">{entry.ip}</span>
+                            </button>
+                            {expanded === entry.id && (entry.before || entry.after) && (
+                                <div className="grid grid-cols-2 gap-2 mt-2">
+                                    <pre className="p-2 bg-black/40 rounded text-[10px] text-red-300 overflow-x-auto">{entry.before ? JSON.stringify(entry.before, null, 2) : '-'}</pre>
+                                    <pre className="p-2 bg-black/40 rounded text-[10px] text-emerald-300 overflow-x-auto">{entry.after ? JSON.stringify(entry.after, null, 2) : '-'}</pre>
+                                </div>
+                            )}
+                        </div>
+                    ))}
+                    {entries.length === 0 && <div className="text-xs text-zinc-500 italic">Kayıt bulunamadı.</div>}
+                </div>
+            </div>
+        </motion.div>
+    );
+}
+
+// Çalışırken değiştirilebilen tarama motoru ve zamanlayıcı ayarları
+const runtimeFields = [
+    ['scan_timeout', 'Tarama zaman aşımı', 'örn: 15s'],
+    ['scan_max_retries', 'Deneme sayısı', '1-10'],
+    ['scan_retry_delay', 'İlk bekleme (katlanır)', 'örn: 2s'],
+    ['scan_max_backoff', 'En uzun bekleme', 'örn: 30s'],
+    ['scan_retry_jitter', 'Jitter oranı', '0-1'],
+    ['scan_retry_on', 'Yeniden denenecek hata kodları', 'timeout, http_status', 'list'],
+    ['scan_switch_circuit', 'Denemede yeni Tor devresi', '', 'bool'],
+    ['scan_content_limit', 'Ham içerik sınırı (karakter)', '100-100000'],
+    ['scheduler_tick', 'Watchlist kontrol aralığı', 'örn: 1m'],
+];
+
+// Virgülle ayrılmış metni diziye çevirir (değiştirilmemişse dizi olarak kalır)
+const listValue = (v) => Array.isArray(v) ? v : String(v ?? '').split(',').map(s => s.trim()).filter(Boolean);
+
+function SystemConfig({ refreshTrigger }) {
+    const [data, setData] = useState(null);
+    const [form, setForm] = useState({});
+    const [error, setError] = useState('');
+    const [showConfig, setShowConfig] = useState(false);
+
+    useEffect(() => {
+        fetchConfig();
+    }, [refreshTrigger]);
+
+    const fetchConfig = async () => {
+        try {
+            const res = await api.get('/system/config');
+            setData(res.data);
+            setForm(res.data.runtime);
+        } catch (error) {
+            console.error("Sistem ayarları yüklenemedi", error);
+        }
+    };
+
+    const run = async (request) => {
+        try {
+            await request();
+            setError('');
+            fetchConfig();
+        } catch (error) {
+            setError(error.response?.data?.error || 'İşlem başarısız');
+        }
+    };
+
+    const handleSave = () => run(() => api.put('/system/config/runtime', {
+        ...form,
+        scan_max_retries: Number(form.scan_max_retries),
+        scan_retry_jitter: Number(form.scan_retry_jitter),
+        scan_retry_on: listValue(form.scan_retry_on),
+        scan_content_limit: Number(form.scan_content_limit),
+    }));
+
+    const handleReset = () => {
+        if (!confirm('Tüm çalışma zamanı değişiklikleri silinip dosya/ortam değerlerine dönülecek. Emin misiniz?')) return;
+        run(() => api.delete('/system/config/runtime'));
+    };
+
+    if (!data) return null;
+    const overridden = new Set(data.overrides.map((o) => o.key));
+
+    return (
+        <motion.div
+            initial={{ opacity: 0, y: 20 }}
+            animate={{ opacity: 1, y: 0 }}
+            className="mb-8 glass-panel overflow-hidden shadow-2xl"
+        >
+            <div className="p-6 border-b border-white/5 bg-white/[0.02] flex justify-between items-center">
+                <h2 className="text-sm font-bold text-zinc-400 uppercase tracking-widest flex items-center gap-2">
+                    <Server size={16} /> SİSTEM YAPILANDIRMASI
+                </h2>
+                <span className="text-[10px] text-zinc-500 font-mono">{data.config.file || 'config dosyası yok'}</span>
+            </div>
+
+            <div className="p-6 space-y-4">
+                <div className="grid grid-cols-1 md:grid-cols-5 gap-3">
+                    {runtimeFields.map(([key, label, placeholder, type]) => (
+                        <div key={key} className={type === 'list' ? 'md:col-span-2' : ''}>
+                            <label className="block text-[10px] text-zinc-500 mb-1">
+                                {label}
+                                {overridden.has(key) && <span className="ml-1 text-amber-400" title={`Dosya/ortam değeri: ${data.base[key]}`}>*</span>}
+                            </label>
+                            {type === 'bool' ? (
+                                <label className="flex items-center gap-2 h-[34px] text-xs text-zinc-300">
+                                    <input
+                                        type="checkbox"
+                                        checked={!!form[key]}
+                                        onChange={e => setForm({ ...form, [key]: e.target.checked })}
+                                        className="accent-emerald-500"
+                                    />
+                                    {form[key] ? 'Açık' : 'Kapalı'}
+                                </label>
+                            ) : (
+                                <input
+                                    value={Array.isArray(form[key]) ? form[key].join(', ') : (form[key] ?? '')}
+                                    onChange={e => setForm({ ...form, [key]: e.target.value })}
+                                    className="w-full bg-white/5 border border-white/10 rounded px-3 py-2 text-xs text-white focus:outline-none focus:border-emerald-500"
+                                    placeholder={placeholder}
+                                />
+                            )}
+                        </div>
+                    ))}
+                </div>
+                <div className="flex items-center gap-2">
+                    <button onClick={handleSave} className="h-[34px] px-4 bg-white text-black text-xs font-bold rounded hover:bg-emerald-400 transition-colors">KAYDET</button>
+                    {data.overrides.length > 0 && (
+                        <button onClick={handleReset} className="h-[34px] px-4 bg-zinc-900 border border-zinc-800 text-zinc-400 text-xs font-bold rounded hover:text-white transition-colors">VARSAYILANA DÖN</button>
+                    )}
+                    <span className="text-[10px] text-zinc-500">* Ayarlar sayfasından değiştirilmiş değer</span>
+                    <button onClick={() => setShowConfig(!showConfig)} className="ml-auto text-[10px] text-zinc-400 underline hover:text-white">
+                        {showConfig ? 'Tüm ayarları gizle' : 'Tüm ayarları göster'}
+                    </button>
+                </div>
+                {error && <div className="text-xs text-red-400">{error}</div>}
+                {showConfig && (
+                    <pre className="p-3 bg-black/40 rounded text-[10px] text-zinc-300 overflow-x-auto max-h-[400px]">{JSON.stringify(data.config, null, 2)}</pre>
+                )}
+            </div>
+        </motion.div>
+    );
+}
+
+export default function SettingsPage({ role }) {
     const [config, setConfig] = useState({
         torProxy: true,
         watchlistEnabled: false,
@@ -745,7 +1515,7 @@ export default function SettingsPage() {
                         SİSTEM YAPILANDIRMASI
                     </h1>
                     <p className="text-xs text-zinc-400 mt-2 uppercase tracking-widest pl-1">
-                        galileoff • YETKİ SEVİYESİ: <span className="text-purple-500">ROOT</span>
+                        galileoff • YETKİ SEVİYESİ: <span className="text-purple-500">{roleLabels[role] || role}</span>
                     </p>
                 </div>
                 <div>
@@ -761,6 +1531,21 @@ export default function SettingsPage() {
 
 
 
+            {/* Kullanıcı Yönetimi */}
+            {role === 'admin' && <UserManager refreshTrigger={refreshTrigger} />}
+
+            {/* İki Adımlı Doğrulama */}
+            <TwoFactorManager refreshTrigger={refreshTrigger} />
+
+            {/* API Anahtarları */}
+            <APIKeyManager refreshTrigger={refreshTrigger} role={role} />
+
+            {/* Denetim Kaydı */}
+            {role === 'admin' && <AuditTrail refreshTrigger={refreshTrigger} />}
+
+            {/* Sistem Yapılandırması */}
+            {role === 'admin' && <SystemConfig refreshTrigger={refreshTrigger} />}
+
             {/* Kategori ve Keyword Yönetimi */}
             <KeywordManager refreshTrigger={refreshTrigger} />
 
@@ -829,7 +1614,7 @@ export default function SettingsPage() {
             </div>
 
             {/* Tehlikeli Bölge */}
-            <div className="mt-12 pt-8 border-t border-white/5">
+            {role === 'admin' && <div className="mt-12 pt-8 border-t border-white/5">
                 <h2 className="text-xs text-red-500 font-bold uppercase tracking-widest flex items-center gap-2 mb-6">
                     <Lock size={14} /> KRİTİK İŞLEMLER
                 </h2>
@@ -845,7 +1630,7 @@ export default function SettingsPage() {
                         SIFIRLA
                     </button>
                 </div>
-            </div>
+            </div>}
         </div >
     );
 }
@@ -2,14 +2,14 @@
 
 import { Search, History, Settings, LogOut, EyeOff, LayoutDashboard, FileText } from 'lucide-react';
 
-export default function Sidebar({ activeTab, setActiveTab, onLogout }) {
+export default function Sidebar({ activeTab, setActiveTab, onLogout, role }) {
     const menuItems = [
         { id: 'dashboard', label: 'Panel', icon: LayoutDashboard },
-        { id: 'scanner', label: 'Tarayıcı', icon: Search },
+        { id: 'scanner', label: 'Tarayıcı', icon: Search, minRole: 'analyst' },
         { id: 'history', label: 'Geçmiş', icon: History },
         { id: 'logs', label: 'Loglar', icon: FileText },
         { id: 'settings', label: 'Ayarlar', icon: Settings },
-    ];
+    ].filter(item => !item.minRole || role === 'admin' || role === item.minRole);
 
     return (
         <div className="w-64 glass-sidebar flex flex-col h-screen fixed left-0 top-0 z-50 font-mono">
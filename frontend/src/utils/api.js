@@ -20,18 +20,88 @@ api.interceptors.request.use(
     }
 );
 
-// Response Interceptor: 401 hatası gelirse oturumu kapat
+// Aynı anda gelen 401'ler tek bir yenileme isteği bekler (yenileme token'ı tek kullanımlıktır)
+let refreshing = null;
+
+export const refreshSession = () => {
+    if (!refreshing) {
+        const refreshToken = sessionStorage.getItem('refreshToken');
+        refreshing = (refreshToken
+            ? axios.post(`${api.defaults.baseURL}/refresh`, { refresh_token: refreshToken }).then((res) => {
+                sessionStorage.setItem('authToken', res.data.token);
+                sessionStorage.setItem('refreshToken', res.data.refresh_token);
+                return res.data.token;
+            })
+            : Promise.reject(new Error('Yenileme token\'ı yok'))
+        ).finally(() => { refreshing = null; });
+    }
+    return refreshing;
+};
+
+// Response Interceptor: 401 gelirse oturumu bir kez yenileyip isteği tekrarla, olmazsa oturumu kapat
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
-        if (error.response && error.response.status === 401) {
-            // Token geçersiz veya süresi dolmuş
-            if (typeof window !== 'undefined') {
-                sessionStorage.removeItem('authToken');
+    async (error) => {
+        const original = error.config;
+        if (error.response && error.response.status === 401 && typeof window !== 'undefined') {
+            if (original && !original._retried) {
+                original._retried = true;
+                try {
+                    const token = await refreshSession();
+                    original.headers.Authorization = `Bearer ${token}`;
+                    return api(original);
+                } catch (refreshError) {
+                    // Yenileme başarısız, aşağıda oturum kapatılır
+                }
             }
+            // Token geçersiz veya süresi dolmuş
+            sessionStorage.removeItem('authToken');
+            sessionStorage.removeItem('refreshToken');
         }
         return Promise.reject(error);
     }
 );
 
+// Server-Sent Events akışını okur (EventSource Authorization başlığı gönderemediği için fetch kullanılır)
+// Akış sunucu tarafından kapatıldığında veya signal iptal edildiğinde biter.
+export const streamEvents = async (path, onEvent, signal) => {
+    const open = (token) => fetch(`${api.defaults.baseURL}${path}`, {
+        headers: token ? { Authorization: `Bearer ${token}` } : {},
+        signal,
+    });
+    let res = await open(typeof window !== 'undefined' ? sessionStorage.getItem('authToken') : null);
+    if (res.status === 401) {
+        res = await open(await refreshSession());
+    }
+    if (!res.ok || !res.body) {
+        throw new Error(`Akış açılamadı (${res.status})`);
+    }
+
+    const reader = res.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    while (true) {
+        const { value, done } = await reader.read();
+        if (done) break;
+        buffer += decoder.decode(value, { stream: true });
+
+        let idx;
+        while ((idx = buffer.indexOf('\n\n')) !== -1) {
+            const chunk = buffer.slice(0, idx);
+            buffer = buffer.slice(idx + 2);
+            const data = chunk
+                .split('\n')
+                .filter((line) => line.startsWith('data:'))
+                .map((line) => line.slice(5).replace(/^ /, ''))
+                .join('\n');
+            if (!data) continue; // Canlı tutma yorumu
+            try {
+                onEvent(JSON.parse(data));
+            } catch (err) {
+                console.error("Akış mesajı çözülemedi", err);
+            }
+        }
+    }
+};
+
 export default api;